@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,10 +14,16 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/werf/logboek"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"k8s.io/klog"
 	klog_v2 "k8s.io/klog/v2"
 
 	"github.com/werf/kubedog"
+	"github.com/werf/kubedog/pkg/display"
 	"github.com/werf/kubedog/pkg/kube"
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/trackers/follow"
@@ -30,6 +37,7 @@ func main() {
 
 	var namespace string
 	var timeoutSeconds int
+	var watchRetryBudget int
 	var statusProgressPeriodSeconds int64
 	var logsSince string
 	var kubeContext string
@@ -37,6 +45,22 @@ func main() {
 	var kubeConfigBase64 string
 	var kubeConfigPathMergeList []string
 	var outputPrefix string
+	var outputFormat string
+	var reportFile string
+	var junitReportFile string
+	var diagnosticsBundle string
+	var metricsListenAddress string
+	var metricsPushURL string
+	var metricsPushJob string
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var traceFile string
+	var specsFiles []string
+	var manifestsFiles []string
+	var useResourceAnnotations bool
+	var labelSelector string
+	var kubeContexts []string
+	var requiredReadyClusters int
 
 	makeTrackerOptions := func(mode string) tracker.Options {
 		// rollout track defaults
@@ -61,13 +85,33 @@ func main() {
 		}
 
 		opts := tracker.Options{
-			Timeout:      time.Second * time.Duration(timeout),
-			LogsFromTime: logsFromTime,
+			Timeout:          time.Second * time.Duration(timeout),
+			LogsFromTime:     logsFromTime,
+			WatchRetryBudget: watchRetryBudget,
 		}
 
 		return opts
 	}
 
+	isJSONOutput := func() bool {
+		switch outputFormat {
+		case "text":
+			return false
+		case "json":
+			return true
+		default:
+			fmt.Fprintf(os.Stderr, "Bad value specified for --output: %q, expected text or json\n", outputFormat)
+			os.Exit(1)
+		}
+		return false
+	}
+
+	initPrinter := func() {
+		if isJSONOutput() {
+			display.SetPrinter(display.JSONPrinter{})
+		}
+	}
+
 	init := func() {
 		if err := SilenceKlog(context.Background()); err != nil {
 			fmt.Fprintf(os.Stderr, "Unable to initialize klog: %s\n", err)
@@ -121,6 +165,7 @@ func main() {
 	rootCmd := &cobra.Command{Use: "kubedog"}
 	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "If present, the namespace scope of a resource.")
 	rootCmd.PersistentFlags().IntVarP(&timeoutSeconds, "timeout", "t", -1, "Timeout of operation in seconds. 0 is wait forever. Default is 0.")
+	rootCmd.PersistentFlags().IntVarP(&watchRetryBudget, "watch-retry-budget", "", tracker.DefaultWatchRetryBudget, "Number of consecutive failed list and watch requests to the API server, after which tracking fails. Set -1 to retry forever.")
 	rootCmd.PersistentFlags().StringVarP(&logsSince, "logs-since", "", "now", "A duration like 30s, 5m, or 2h to start log records from the past. 'all' to show all logs and 'now' to display only new records (default).")
 	rootCmd.PersistentFlags().StringVarP(&kubeContext, "kube-context", "", os.Getenv("KUBEDOG_KUBE_CONTEXT"), "The name of the kubeconfig context to use (can be set with $KUBEDOG_KUBE_CONTEXT).")
 	rootCmd.PersistentFlags().StringVarP(&kubeConfig, "kube-config", "", "", "Path to the kubeconfig file (can be set with $KUBEDOG_KUBE_CONFIG or $KUBECONFIG).")
@@ -143,10 +188,114 @@ func main() {
 	}
 	rootCmd.AddCommand(versionCmd)
 
+	makeMetrics := func() *multitrack.Metrics {
+		if metricsListenAddress == "" && metricsPushURL == "" {
+			return nil
+		}
+
+		metrics := multitrack.NewMetrics()
+		if metricsListenAddress != "" {
+			go serveMetrics(metricsListenAddress, metrics)
+		}
+		return metrics
+	}
+
+	runMultitrack := func(specs multitrack.MultitrackSpecs) {
+		multitrackOptions := multitrack.MultitrackOptions{
+			StatusProgressPeriod:   time.Second * time.Duration(statusProgressPeriodSeconds),
+			Options:                makeTrackerOptions("track"),
+			UseResourceAnnotations: useResourceAnnotations,
+			DynamicClient:          kube.DynamicClient,
+			DiagnosticsBundlePath:  diagnosticsBundle,
+			Metrics:                makeMetrics(),
+		}
+		if isJSONOutput() {
+			multitrackOptions.Reporter = multitrack.NewJSONReporter(os.Stdout)
+		}
+
+		tracerProvider, shutdownTracing, err := newTracerProvider(otlpEndpoint, otlpInsecure, traceFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting up tracing: %s\n", err)
+			os.Exit(1)
+		}
+		if tracerProvider != nil {
+			multitrackOptions.TracerProvider = tracerProvider
+		}
+
+		var report multitrack.MultitrackReport
+		if len(kubeContexts) > 0 {
+			clusters, clustersErr := getKubeContextsClusters(kubeContexts, specs, kube.GetAllContextsClientsOptions{
+				ConfigPath:          kubeConfig,
+				ConfigDataBase64:    kubeConfigBase64,
+				ConfigPathMergeList: kubeConfigPathMergeList,
+			})
+			if clustersErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", clustersErr)
+				shutdownTracing()
+				os.Exit(1)
+			}
+
+			report, err = multitrack.MultitrackClusters(clusters, multitrack.MultitrackClustersOptions{
+				MultitrackOptions:     multitrackOptions,
+				RequiredReadyClusters: requiredReadyClusters,
+			})
+		} else {
+			report, err = multitrack.MultitrackWithReport(kube.Kubernetes, specs, multitrackOptions)
+		}
+
+		if shutdownErr := shutdownTracing(); shutdownErr != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting traces: %s\n", shutdownErr)
+		}
+
+		if reportFile != "" {
+			if writeErr := writeMultitrackReport(reportFile, report); writeErr != nil {
+				fmt.Fprintf(os.Stderr, "Error writing report file: %s\n", writeErr)
+				os.Exit(1)
+			}
+		}
+
+		if junitReportFile != "" {
+			if writeErr := writeMultitrackJUnitReport(junitReportFile, report); writeErr != nil {
+				fmt.Fprintf(os.Stderr, "Error writing JUnit report file: %s\n", writeErr)
+				os.Exit(1)
+			}
+		}
+
+		if metricsPushURL != "" {
+			if pushErr := multitrackOptions.Metrics.Push(metricsPushURL, metricsPushJob); pushErr != nil {
+				fmt.Fprintf(os.Stderr, "Error pushing metrics: %s\n", pushErr)
+				os.Exit(1)
+			}
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	// addMultitrackFlags registers flags shared by all commands running multitrack with runMultitrack
+	addMultitrackFlags := func(cmd *cobra.Command) {
+		cmd.PersistentFlags().Int64VarP(&statusProgressPeriodSeconds, "status-progress-period", "", 5, "Status progress period in seconds. Set -1 to stop showing status progress.")
+		cmd.PersistentFlags().BoolVarP(&useResourceAnnotations, "use-resource-annotations", "", false, "Configure specs with kubedog/* annotations of the live resources. Values set explicitly in specs take precedence over annotations.")
+		cmd.PersistentFlags().StringVarP(&reportFile, "report-file", "", "", "Write JSON report about every tracked resource into the specified file when tracking is done or failed.")
+		cmd.PersistentFlags().StringVarP(&junitReportFile, "junit-report-file", "", "", "Write JUnit XML report, where every tracked resource is a test case, into the specified file when tracking is done or failed.")
+		cmd.PersistentFlags().StringVarP(&diagnosticsBundle, "diagnostics-bundle", "", "", "Collect objects, pods, logs of failing containers, events and nodes conditions of not ready resources when tracking fails. Bundle is written as tar.gz archive if the path ends with .tar.gz or .tgz and as a directory otherwise.")
+		cmd.PersistentFlags().StringVarP(&metricsListenAddress, "metrics-listen-address", "", "", "Serve prometheus metrics of the tracking on the /metrics path of the specified address, e.g. :9090.")
+		cmd.PersistentFlags().StringVarP(&metricsPushURL, "metrics-push-url", "", "", "Push prometheus metrics to the Pushgateway-compatible endpoint when tracking is done or failed.")
+		cmd.PersistentFlags().StringVarP(&metricsPushJob, "metrics-push-job", "", "kubedog", "Job name of the metrics pushed with --metrics-push-url.")
+		cmd.PersistentFlags().StringVarP(&otlpEndpoint, "otlp-endpoint", "", "", "Export trace of the tracking to the OTLP/HTTP endpoint, e.g. localhost:4318.")
+		cmd.PersistentFlags().BoolVarP(&otlpInsecure, "otlp-insecure", "", false, "Use HTTP instead of HTTPS for --otlp-endpoint.")
+		cmd.PersistentFlags().StringVarP(&traceFile, "trace-file", "", "", "Write spans of the tracking trace in JSON format into the specified file.")
+		cmd.PersistentFlags().StringSliceVarP(&kubeContexts, "kube-contexts", "", nil, "Track the same resources in every specified kubeconfig context in parallel, comma separated or specified multiple times.")
+		cmd.PersistentFlags().IntVarP(&requiredReadyClusters, "required-ready-clusters", "", 0, "Number of --kube-contexts clusters, in which all resources should become ready. Default is all clusters.")
+		addOutputFlag(cmd, &outputFormat)
+	}
+
 	multitrackCmd := &cobra.Command{
 		Use:     "multitrack",
 		Short:   "Track multiple resources using multitrack tracker",
-		Example: `echo '{"Deployments":[{"ResourceName":"mydeploy","Namespace":"myns"},{"ResourceName":"myresource","Namespace":"myns","FailMode":"HopeUntilEndOfDeployProcess","AllowFailuresCount":3,"SkipLogsForContainers":["two", "three"]}], "StatefulSets":[{"ResourceName":"mysts","Namespace":"myns"}]}' | kubedog multitrack`,
+		Example: `echo '{"Deployments":[{"ResourceName":"mydeploy","Namespace":"myns"},{"ResourceName":"myresource","Namespace":"myns","FailMode":"HopeUntilEndOfDeployProcess","AllowFailuresCount":3,"SkipLogsForContainers":["two", "three"]}], "StatefulSets":[{"ResourceName":"mysts","Namespace":"myns"}], "Generic":[{"ResourceName":"mycert","Namespace":"myns","Kind":"Certificate.cert-manager.io"}]}' | kubedog multitrack`,
 		Run: func(cmd *cobra.Command, args []string) {
 			init()
 
@@ -154,35 +303,128 @@ func main() {
 				logboek.Context(context.Background()).Streams().SetPrefix(outputPrefix)
 			}
 
-			specsInput, err := ioutil.ReadAll(os.Stdin)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error reading stdin: %s\n", err)
-				os.Exit(1)
+			var specs multitrack.MultitrackSpecs
+			var err error
+			if len(specsFiles) > 0 {
+				specs, err = multitrack.LoadSpecsFiles(specsFiles)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error loading MultitrackSpecs files: %s\n", err)
+					os.Exit(1)
+				}
+			} else if labelSelector == "" {
+				specsInput, err := ioutil.ReadAll(os.Stdin)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading stdin: %s\n", err)
+					os.Exit(1)
+				}
+
+				specs, err = multitrack.ParseSpecs(specsInput)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error parsing MultitrackSpecs: %s\n", err)
+					os.Exit(1)
+				}
 			}
 
-			specs := multitrack.MultitrackSpecs{}
-			err = json.Unmarshal(specsInput, &specs)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error parsing MultitrackSpecs json: %s\n", err)
-				os.Exit(1)
+			if labelSelector != "" {
+				specs.Selectors = append(specs.Selectors, multitrack.MultitrackSelectorSpec{
+					Namespace:     namespace,
+					LabelSelector: labelSelector,
+				})
+
+				if err := multitrack.ValidateSpecs(specs); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+					os.Exit(1)
+				}
+			}
+
+			runMultitrack(specs)
+		},
+	}
+	multitrackCmd.PersistentFlags().StringVarP(&labelSelector, "selector", "l", "", "Track all Deployments, StatefulSets, DaemonSets and Jobs matching the label selector in the namespace, including ones created while tracking runs. Specs from stdin are not read unless --file is specified.")
+	multitrackCmd.PersistentFlags().StringArrayVarP(&specsFiles, "file", "f", nil, "Read MultitrackSpecs in YAML or JSON format from the specified file, directory or glob pattern instead of stdin. Can be specified multiple times, all specs are merged.")
+	addMultitrackFlags(multitrackCmd)
+
+	rootCmd.AddCommand(multitrackCmd)
+
+	trackManifestsCmd := &cobra.Command{
+		Use:     "track-manifests",
+		Short:   "Track Deployments, StatefulSets, DaemonSets, Jobs and Canaries from Kubernetes manifests using multitrack tracker",
+		Example: `helm template ./chart | kubedog track-manifests -n myns`,
+		Run: func(cmd *cobra.Command, args []string) {
+			init()
+
+			if outputPrefix != "" {
+				logboek.Context(context.Background()).Streams().SetPrefix(outputPrefix)
+			}
+
+			var specs multitrack.MultitrackSpecs
+			var err error
+			if len(manifestsFiles) > 0 {
+				specs, err = multitrack.LoadManifestsFiles(manifestsFiles, namespace)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error loading manifests files: %s\n", err)
+					os.Exit(1)
+				}
+			} else {
+				manifestsInput, err := ioutil.ReadAll(os.Stdin)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading stdin: %s\n", err)
+					os.Exit(1)
+				}
+
+				specs, err = multitrack.ParseManifests(manifestsInput, namespace)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error parsing manifests: %s\n", err)
+					os.Exit(1)
+				}
+			}
+
+			runMultitrack(specs)
+		},
+	}
+	trackManifestsCmd.PersistentFlags().StringArrayVarP(&manifestsFiles, "file", "f", nil, "Read Kubernetes manifests in YAML or JSON format from the specified file, directory or glob pattern instead of stdin. Can be specified multiple times.")
+	addMultitrackFlags(trackManifestsCmd)
+
+	rootCmd.AddCommand(trackManifestsCmd)
+
+	watchCmd := &cobra.Command{Use: "watch"}
+	addOutputFlag(watchCmd, &outputFormat)
+	rootCmd.AddCommand(watchCmd)
+
+	watchNamespaceCmd := &cobra.Command{
+		Use:   "namespace NS",
+		Short: "Watch all Deployments, StatefulSets, DaemonSets and Jobs of the namespace",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			init()
+
+			if outputPrefix != "" {
+				logboek.Context(context.Background()).Streams().SetPrefix(outputPrefix)
 			}
 
 			multitrackOptions := multitrack.MultitrackOptions{
-				StatusProgressPeriod: time.Second * time.Duration(statusProgressPeriodSeconds),
-				Options:              makeTrackerOptions("track"),
+				StatusProgressPeriod:   time.Second * time.Duration(statusProgressPeriodSeconds),
+				Options:                makeTrackerOptions("follow"),
+				UseResourceAnnotations: useResourceAnnotations,
+				Metrics:                makeMetrics(),
 			}
-			err = multitrack.Multitrack(kube.Kubernetes, specs, multitrackOptions)
-			if err != nil {
+			if isJSONOutput() {
+				multitrackOptions.Reporter = multitrack.NewJSONReporter(os.Stdout)
+			}
+
+			if err := multitrack.WatchNamespace(kube.Kubernetes, args[0], multitrackOptions); err != nil {
 				fmt.Fprintln(os.Stderr, err)
 				os.Exit(1)
 			}
 		},
 	}
-	multitrackCmd.PersistentFlags().Int64VarP(&statusProgressPeriodSeconds, "status-progress-period", "", 5, "Status progress period in seconds. Set -1 to stop showing status progress.")
-
-	rootCmd.AddCommand(multitrackCmd)
+	watchNamespaceCmd.PersistentFlags().Int64VarP(&statusProgressPeriodSeconds, "status-progress-period", "", 5, "Status progress period in seconds. Set -1 to stop showing status progress.")
+	watchNamespaceCmd.PersistentFlags().BoolVarP(&useResourceAnnotations, "use-resource-annotations", "", false, "Configure specs with kubedog/* annotations of the live resources.")
+	watchNamespaceCmd.PersistentFlags().StringVarP(&metricsListenAddress, "metrics-listen-address", "", "", "Serve prometheus metrics of the tracking on the /metrics path of the specified address, e.g. :9090.")
+	watchCmd.AddCommand(watchNamespaceCmd)
 
 	followCmd := &cobra.Command{Use: "follow"}
+	addOutputFlag(followCmd, &outputFormat)
 	rootCmd.AddCommand(followCmd)
 
 	followCmd.AddCommand(&cobra.Command{
@@ -192,6 +434,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := follow.TrackJob(name, namespace, kube.Kubernetes, makeTrackerOptions("follow"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -206,6 +449,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := follow.TrackDeployment(name, namespace, kube.Kubernetes, makeTrackerOptions("follow"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -220,6 +464,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := follow.TrackStatefulSet(name, namespace, kube.Kubernetes, makeTrackerOptions("follow"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -234,6 +479,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := follow.TrackDaemonSet(name, namespace, kube.Kubernetes, makeTrackerOptions("follow"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -248,6 +494,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := follow.TrackPod(name, namespace, kube.Kubernetes, makeTrackerOptions("follow"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -259,6 +506,7 @@ func main() {
 	rolloutCmd := &cobra.Command{Use: "rollout"}
 	rootCmd.AddCommand(rolloutCmd)
 	trackCmd := &cobra.Command{Use: "track"}
+	addOutputFlag(trackCmd, &outputFormat)
 	rolloutCmd.AddCommand(trackCmd)
 
 	trackCmd.AddCommand(&cobra.Command{
@@ -268,6 +516,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := rollout.TrackJobTillDone(name, namespace, kube.Kubernetes, makeTrackerOptions("track"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -283,6 +532,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := rollout.TrackDeploymentTillReady(name, namespace, kube.Kubernetes, makeTrackerOptions("track"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -298,6 +548,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := rollout.TrackStatefulSetTillReady(name, namespace, kube.Kubernetes, makeTrackerOptions("track"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -313,6 +564,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := rollout.TrackDaemonSetTillReady(name, namespace, kube.Kubernetes, makeTrackerOptions("track"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -328,6 +580,7 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			init()
+			initPrinter()
 			err := rollout.TrackPodTillReady(name, namespace, kube.Kubernetes, makeTrackerOptions("track"))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -343,6 +596,128 @@ func main() {
 	}
 }
 
+func addOutputFlag(cmd *cobra.Command, outputFormat *string) {
+	cmd.PersistentFlags().StringVarP(outputFormat, "output", "o", "text", "Output format: text or json. In json mode every event is printed as a separate JSON object per line.")
+}
+
+// newTracerProvider returns nil when neither OTLP endpoint nor trace file is set. Returned shutdown func exports
+// remaining spans and closes the trace file, it should be called when tracking is done.
+func newTracerProvider(otlpEndpoint string, otlpInsecure bool, traceFile string) (*sdktrace.TracerProvider, func() error, error) {
+	if otlpEndpoint == "" && traceFile == "" {
+		return nil, func() error { return nil }, nil
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(sdkresource.NewSchemaless(attribute.String("service.name", "kubedog"))),
+	}
+
+	if otlpEndpoint != "" {
+		exporterOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(otlpEndpoint)}
+		if otlpInsecure {
+			exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(context.Background(), exporterOpts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create OTLP exporter: %s", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	var f *os.File
+	if traceFile != "" {
+		var err error
+		f, err = os.Create(traceFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			f.Close()
+			return nil, nil, fmt.Errorf("unable to create file exporter: %s", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(opts...)
+
+	shutdown := func() error {
+		err := tracerProvider.Shutdown(context.Background())
+		if f != nil {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}
+
+	return tracerProvider, shutdown, nil
+}
+
+func getKubeContextsClusters(kubeContexts []string, specs multitrack.MultitrackSpecs, opts kube.GetAllContextsClientsOptions) ([]multitrack.MultitrackCluster, error) {
+	contextsClients, err := kube.GetAllContextsClients(opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get kube contexts clients: %s", err)
+	}
+
+	var clusters []multitrack.MultitrackCluster
+	for _, kubeContext := range kubeContexts {
+		var contextClient *kube.ContextClient
+		for _, c := range contextsClients {
+			if c.ContextName == kubeContext {
+				contextClient = c
+				break
+			}
+		}
+		if contextClient == nil {
+			return nil, fmt.Errorf("kube context %q not found", kubeContext)
+		}
+
+		clusters = append(clusters, multitrack.MultitrackCluster{
+			Name:          contextClient.ContextName,
+			Kube:          contextClient.Client,
+			DynamicClient: contextClient.DynamicClient,
+			Specs:         specs,
+		})
+	}
+
+	return clusters, nil
+}
+
+func serveMetrics(addr string, metrics *multitrack.Metrics) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		fmt.Fprintf(os.Stderr, "Error serving metrics: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func writeMultitrackReport(path string, report multitrack.MultitrackReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func writeMultitrackJUnitReport(path string, report multitrack.MultitrackReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := report.WriteJUnit(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
 func SilenceKlogV2(ctx context.Context) error {
 	fs := flag.NewFlagSet("klog", flag.PanicOnError)
 	klog_v2.InitFlags(fs)
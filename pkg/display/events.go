@@ -0,0 +1,98 @@
+package display
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventAdded          EventType = "added"
+	EventReady          EventType = "ready"
+	EventSucceeded      EventType = "succeeded"
+	EventFailed         EventType = "failed"
+	EventMessage        EventType = "event"
+	EventServiceMessage EventType = "message"
+	EventLogLine        EventType = "log"
+	EventStatus         EventType = "status"
+)
+
+// Event is a single machine-readable tracking event, which is rendered as one JSON object per line.
+type Event struct {
+	Timestamp time.Time   `json:"timestamp"`
+	Type      EventType   `json:"type"`
+	Cluster   string      `json:"cluster,omitempty"`
+	Kind      string      `json:"kind,omitempty"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	Payload   interface{} `json:"payload,omitempty"`
+}
+
+type MessagePayload struct {
+	Message string `json:"message"`
+}
+
+// WaitingPayload is the status of the resource, which tracking is postponed until its dependencies are ready.
+type WaitingPayload struct {
+	WaitingFor []string `json:"waitingFor"`
+}
+
+type LogLinePayload struct {
+	Pod       string `json:"pod,omitempty"`
+	Container string `json:"container"`
+	Timestamp string `json:"logTimestamp"`
+	Message   string `json:"message"`
+}
+
+func NewEvent(eventType EventType, kind, namespace, name string, payload interface{}) Event {
+	return Event{
+		Timestamp: time.Now().UTC(),
+		Type:      eventType,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Payload:   payload,
+	}
+}
+
+// WriteEvent writes event as a JSON line into the specified stream.
+func WriteEvent(stream io.Writer, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	_, err = stream.Write(append(data, '\n'))
+	return err
+}
+
+// OutEvent writes event as a JSON line into the Out stream.
+func OutEvent(event Event) error {
+	return WriteEvent(Out, event)
+}
+
+// WriteLogLineEvents writes every log line as a separate JSON line into the specified stream.
+func WriteLogLineEvents(stream io.Writer, kind, namespace, name, podName, containerName string, logLines []LogLine) error {
+	for _, line := range logLines {
+		err := WriteEvent(stream, NewEvent(EventLogLine, kind, namespace, name, LogLinePayload{
+			Pod:       podName,
+			Container: containerName,
+			Timestamp: line.Timestamp,
+			Message:   line.Message,
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutLogLineEvents writes every log line as a separate JSON line into the Out stream.
+func OutLogLineEvents(kind, namespace, name, podName, containerName string, logLines []LogLine) error {
+	return WriteLogLineEvents(Out, kind, namespace, name, podName, containerName, logLines)
+}
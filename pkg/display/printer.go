@@ -0,0 +1,66 @@
+package display
+
+import "fmt"
+
+// Printer outputs events of the resources tracked by the follow and rollout trackers.
+type Printer interface {
+	// PrintEvent outputs the event, msg is the human-readable description of the event.
+	PrintEvent(event Event, msg string) error
+	// PrintLogLines outputs log lines of the container, header is used by the human-readable output.
+	PrintLogLines(kind, namespace, name, podName, containerName, header string, logLines []LogLine) error
+}
+
+var printer Printer = TextPrinter{}
+
+// SetPrinter sets the printer of the follow and rollout trackers, TextPrinter is used by default.
+func SetPrinter(p Printer) {
+	printer = p
+}
+
+// PrintEvent outputs the event of the resource with the current printer. The formatted message is used
+// as the payload of the event, when payload is nil.
+func PrintEvent(eventType EventType, kind, namespace, name string, payload interface{}, format string, a ...interface{}) error {
+	msg := fmt.Sprintf(format, a...)
+	if payload == nil {
+		payload = MessagePayload{Message: msg}
+	}
+	return printer.PrintEvent(NewEvent(eventType, kind, namespace, name, payload), msg)
+}
+
+// PrintStatus outputs the status of the resource with the current printer.
+func PrintStatus(kind, namespace, name string, status interface{}) error {
+	return printer.PrintEvent(NewEvent(EventStatus, kind, namespace, name, status), "")
+}
+
+// PrintLogLines outputs log lines of the container of the resource with the current printer.
+func PrintLogLines(kind, namespace, name, podName, containerName, header string, logLines []LogLine) error {
+	return printer.PrintLogLines(kind, namespace, name, podName, containerName, header, logLines)
+}
+
+// TextPrinter outputs events as `# kind/name msg` lines and log lines under the log header into the Out stream.
+type TextPrinter struct{}
+
+func (TextPrinter) PrintEvent(event Event, msg string) error {
+	// Statuses are shown only by the machine-readable output
+	if event.Type == EventStatus {
+		return nil
+	}
+	_, err := OutF("# %s/%s %s\n", event.Kind, event.Name, msg)
+	return err
+}
+
+func (TextPrinter) PrintLogLines(_, _, _, _, _, header string, logLines []LogLine) error {
+	OutputLogLines(header, logLines)
+	return nil
+}
+
+// JSONPrinter outputs every event and every log line as a separate JSON line into the Out stream.
+type JSONPrinter struct{}
+
+func (JSONPrinter) PrintEvent(event Event, _ string) error {
+	return OutEvent(event)
+}
+
+func (JSONPrinter) PrintLogLines(kind, namespace, name, podName, containerName, _ string, logLines []LogLine) error {
+	return OutLogLineEvents(kind, namespace, name, podName, containerName, logLines)
+}
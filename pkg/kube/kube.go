@@ -121,6 +121,7 @@ type ContextClient struct {
 	ContextName      string
 	ContextNamespace string
 	Client           kubernetes.Interface
+	DynamicClient    dynamic.Interface
 }
 
 func GetAllContextsClients(opts GetAllContextsClientsOptions) ([]*ContextClient, error) {
@@ -292,10 +293,16 @@ func getOutOfClusterContextsClients(configPath, configDataBase64 string, configP
 			return nil, err
 		}
 
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+
 		res = append(res, &ContextClient{
 			ContextName:      contextName,
 			ContextNamespace: context.Namespace,
 			Client:           clientset,
+			DynamicClient:    dynamicClient,
 		})
 	}
 
@@ -331,10 +338,16 @@ func getInClusterContextClient() (*ContextClient, error) {
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig.Config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ContextClient{
 		ContextName:      "inClusterContext",
 		ContextNamespace: kubeConfig.DefaultNamespace,
 		Client:           clientset,
+		DynamicClient:    dynamicClient,
 	}, nil
 }
 
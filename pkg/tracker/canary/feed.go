@@ -15,6 +15,7 @@ type Feed interface {
 	OnSucceeded(func() error)
 	OnFailed(func(reason string) error)
 	OnEventMsg(func(msg string) error)
+	OnServiceMsg(func(msg string) error)
 	OnStatus(func(CanaryStatus) error)
 
 	GetStatus() CanaryStatus
@@ -26,11 +27,12 @@ func NewFeed() Feed {
 }
 
 type feed struct {
-	OnAddedFunc     func() error
-	OnSucceededFunc func() error
-	OnFailedFunc    func(string) error
-	OnEventMsgFunc  func(string) error
-	OnStatusFunc    func(CanaryStatus) error
+	OnAddedFunc      func() error
+	OnSucceededFunc  func() error
+	OnFailedFunc     func(string) error
+	OnEventMsgFunc   func(string) error
+	OnServiceMsgFunc func(string) error
+	OnStatusFunc     func(CanaryStatus) error
 
 	statusMux sync.Mutex
 	status    CanaryStatus
@@ -52,6 +54,10 @@ func (f *feed) OnEventMsg(function func(string) error) {
 	f.OnEventMsgFunc = function
 }
 
+func (f *feed) OnServiceMsg(function func(string) error) {
+	f.OnServiceMsgFunc = function
+}
+
 func (f *feed) OnStatus(function func(CanaryStatus) error) {
 	f.OnStatusFunc = function
 }
@@ -129,6 +135,17 @@ func (f *feed) Track(name, namespace string, kube kubernetes.Interface, opts tra
 				}
 			}
 
+		case msg := <-canary.ServiceMsg:
+			if f.OnServiceMsgFunc != nil {
+				err := f.OnServiceMsgFunc(msg)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
 		case status := <-canary.Status:
 			f.setStatus(status)
 
@@ -13,7 +13,6 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/kube"
 	"github.com/werf/kubedog/pkg/tracker"
@@ -51,13 +50,16 @@ type Tracker struct {
 }
 
 func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.Options) *Tracker {
-	return &Tracker{
+	res := &Tracker{
 		Tracker: tracker.Tracker{
 			Kube:             kube,
 			Namespace:        namespace,
 			FullResourceName: fmt.Sprintf("canary/%s", name),
 			ResourceName:     name,
 			LogsFromTime:     opts.LogsFromTime,
+			Informers:        opts.Informers,
+			WatchRetryBudget: opts.WatchRetryBudget,
+			ServiceMsg:       make(chan string, 1),
 		},
 
 		Added:     make(chan CanaryStatus, 1),
@@ -75,6 +77,8 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 		objectFailed:   make(chan interface{}, 1),
 		errors:         make(chan error),
 	}
+
+	return res
 }
 
 func (canary *Tracker) Track(ctx context.Context) error {
@@ -139,7 +143,7 @@ func (canary *Tracker) runInformer(ctx context.Context) error {
 	}
 
 	go func() {
-		_, err := watchtools.UntilWithSync(ctx, lw, &v1beta1.Canary{}, nil, func(e watch.Event) (bool, error) {
+		_, err := canary.UntilWithSync(ctx, lw, &v1beta1.Canary{}, func(e watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("Canary `%s` informer event: %#v\n", canary.ResourceName, e.Type)
 			}
@@ -7,6 +7,7 @@ type ControllerFeed interface {
 	OnReady(func() error)
 	OnFailed(func(reason string) error)
 	OnEventMsg(func(msg string) error) // Pulling: pull alpine:3.6....
+	OnServiceMsg(func(msg string) error)
 	OnAddedReplicaSet(func(replicaset.ReplicaSet) error)
 	OnAddedPod(func(replicaset.ReplicaSetPod) error)
 	OnPodLogChunk(func(*replicaset.ReplicaSetPodLogChunk) error)
@@ -18,6 +19,7 @@ type CommonControllerFeed struct {
 	OnReadyFunc           func() error
 	OnFailedFunc          func(reason string) error
 	OnEventMsgFunc        func(msg string) error
+	OnServiceMsgFunc      func(msg string) error
 	OnAddedReplicaSetFunc func(replicaset.ReplicaSet) error
 	OnAddedPodFunc        func(replicaset.ReplicaSetPod) error
 	OnPodLogChunkFunc     func(*replicaset.ReplicaSetPodLogChunk) error
@@ -40,6 +42,10 @@ func (f *CommonControllerFeed) OnEventMsg(function func(string) error) {
 	f.OnEventMsgFunc = function
 }
 
+func (f *CommonControllerFeed) OnServiceMsg(function func(string) error) {
+	f.OnServiceMsgFunc = function
+}
+
 func (f *CommonControllerFeed) OnAddedReplicaSet(function func(replicaset.ReplicaSet) error) {
 	f.OnAddedReplicaSetFunc = function
 }
@@ -121,6 +121,17 @@ func (f *feed) Track(name, namespace string, kube kubernetes.Interface, opts tra
 				}
 			}
 
+		case msg := <-daemonSetTracker.ServiceMsg:
+			if f.OnServiceMsgFunc != nil {
+				err := f.OnServiceMsgFunc(msg)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
 		case report := <-daemonSetTracker.AddedPod:
 			f.setStatus(report.DaemonSetStatus)
 
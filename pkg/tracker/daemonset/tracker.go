@@ -14,11 +14,11 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/tracker/debug"
 	"github.com/werf/kubedog/pkg/tracker/event"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 	"github.com/werf/kubedog/pkg/tracker/pod"
 	"github.com/werf/kubedog/pkg/tracker/replicaset"
 	"github.com/werf/kubedog/pkg/utils"
@@ -56,6 +56,7 @@ type Tracker struct {
 	PodError    chan PodErrorReport
 
 	ignoreReadinessProbeFailsByContainerName map[string]time.Duration
+	reportStatusFailures                     bool
 
 	lastObject     *appsv1.DaemonSet
 	failedReason   string
@@ -75,19 +76,23 @@ type Tracker struct {
 }
 
 func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.Options) *Tracker {
-	return &Tracker{
+	res := &Tracker{
 		Tracker: tracker.Tracker{
 			Kube:             kube,
 			Namespace:        namespace,
 			FullResourceName: fmt.Sprintf("ds/%s", name),
 			ResourceName:     name,
 			LogsFromTime:     opts.LogsFromTime,
+			Informers:        opts.Informers,
+			WatchRetryBudget: opts.WatchRetryBudget,
+			ServiceMsg:       make(chan string, 1),
 		},
 
 		podStatuses:    make(map[string]pod.PodStatus),
 		podGenerations: make(map[string]string),
 
 		ignoreReadinessProbeFailsByContainerName: opts.IgnoreReadinessProbeFailsByContainerName,
+		reportStatusFailures:                     opts.ReportStatusFailures,
 
 		Added:  make(chan DaemonSetStatus, 1),
 		Ready:  make(chan DaemonSetStatus),
@@ -110,6 +115,8 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 		podContainerErrorsRelay: make(chan map[string]pod.ContainerErrorReport, 10),
 		donePodsRelay:           make(chan map[string]pod.PodStatus, 1),
 	}
+
+	return res
 }
 
 // Track starts tracking of DaemonSet rollout process.
@@ -274,17 +281,17 @@ func (d *Tracker) runDaemonSetInformer(ctx context.Context) {
 		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", d.ResourceName).String()
 		return options
 	}
-	lw := &cache.ListWatch{
+	lw := d.Informers.ListWatch(ctx, informer.DaemonSets, d.Namespace, tweakListOptions, &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return client.AppsV1().DaemonSets(d.Namespace).List(ctx, tweakListOptions(options))
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return client.AppsV1().DaemonSets(d.Namespace).Watch(ctx, tweakListOptions(options))
 		},
-	}
+	})
 
 	go func() {
-		_, err := watchtools.UntilWithSync(ctx, lw, &appsv1.DaemonSet{}, nil, func(e watch.Event) (bool, error) {
+		_, err := d.UntilWithSync(ctx, lw, &appsv1.DaemonSet{}, func(e watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("    Daemonset/%s event: %#v\n", d.ResourceName, e.Type)
 			}
@@ -339,6 +346,9 @@ func (d *Tracker) runPodTracker(ctx context.Context, podName string) error {
 	newCtx, cancelPodCtx := context.WithCancel(ctx)
 	podTracker := pod.NewTracker(podName, d.Namespace, d.Kube, pod.Options{
 		IgnoreReadinessProbeFailsByContainerName: d.ignoreReadinessProbeFailsByContainerName,
+		Informers:                                d.Informers,
+		WatchRetryBudget:                         d.WatchRetryBudget,
+		ReportStatusFailures:                     d.reportStatusFailures,
 	})
 	if !d.LogsFromTime.IsZero() {
 		podTracker.LogsFromTime = d.LogsFromTime
@@ -382,6 +392,8 @@ func (d *Tracker) runPodTracker(ctx context.Context, podName string) error {
 
 			case msg := <-podTracker.EventMsg:
 				d.EventMsg <- fmt.Sprintf("po/%s %s", podTracker.ResourceName, msg)
+			case msg := <-podTracker.ServiceMsg:
+				d.ServiceMsg <- fmt.Sprintf("po/%s %s", podTracker.ResourceName, msg)
 			case chunk := <-podTracker.ContainerLogChunk:
 				rsChunk := &replicaset.ReplicaSetPodLogChunk{
 					PodLogChunk: &pod.PodLogChunk{
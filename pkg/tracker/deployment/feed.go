@@ -120,6 +120,17 @@ func (f *feed) Track(name, namespace string, kube kubernetes.Interface, opts tra
 				}
 			}
 
+		case msg := <-deploymentTracker.ServiceMsg:
+			if f.OnServiceMsgFunc != nil {
+				err := f.OnServiceMsgFunc(msg)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
 		case report := <-deploymentTracker.AddedReplicaSet:
 			f.setStatus(report.DeploymentStatus)
 
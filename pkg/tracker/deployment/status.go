@@ -2,8 +2,11 @@ package deployment
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/werf/kubedog/pkg/tracker/indicators"
 	"github.com/werf/kubedog/pkg/tracker/pod"
@@ -25,6 +28,14 @@ type DeploymentStatus struct {
 	IsFailed     bool
 	FailedReason string
 
+	// IsProgressDeadlineExceeded is set when the controller has reported Progressing=False condition with
+	// ProgressDeadlineExceeded reason, ProgressDeadlineMessage is the message of the condition.
+	IsProgressDeadlineExceeded bool
+	ProgressDeadlineMessage    string
+	// ProgressDeadline is the time when the rollout, which is not ready, exceeds progressDeadlineSeconds
+	// unless the controller observes progress before.
+	ProgressDeadline *time.Time
+
 	Pods map[string]pod.PodStatus
 	// New Pod belongs to the new ReplicaSet of the Deployment,
 	// i.e. actual up-to-date Pod of the Deployment
@@ -96,6 +107,11 @@ processingPodsStatuses:
 		res.WaitingForMessages = append(res.WaitingForMessages, fmt.Sprintf("observed generation %d should be >= %d", object.Status.ObservedGeneration, object.Generation))
 	}
 
+	// Progressing condition describes the rollout of the observed generation only
+	if !res.IsReady && object.Status.ObservedGeneration >= object.Generation {
+		setProgressDeadline(&res, object)
+	}
+
 	if !res.IsReady && !res.IsFailed {
 		res.IsFailed = isTrackerFailed
 		res.FailedReason = trackerFailedReason
@@ -104,6 +120,27 @@ processingPodsStatuses:
 	return res
 }
 
+// setProgressDeadline sets the progress deadline of the rollout using Progressing condition of the Deployment.
+func setProgressDeadline(status *DeploymentStatus, object *appsv1.Deployment) {
+	if object.Spec.ProgressDeadlineSeconds == nil || *object.Spec.ProgressDeadlineSeconds == math.MaxInt32 {
+		return
+	}
+
+	cond := utils.GetDeploymentCondition(object.Status, appsv1.DeploymentProgressing)
+	if cond == nil {
+		return
+	}
+
+	if cond.Status == corev1.ConditionFalse && cond.Reason == utils.TimedOutReason {
+		status.IsProgressDeadlineExceeded = true
+		status.ProgressDeadlineMessage = cond.Message
+		return
+	}
+
+	deadline := cond.LastUpdateTime.Add(time.Duration(*object.Spec.ProgressDeadlineSeconds) * time.Second)
+	status.ProgressDeadline = &deadline
+}
+
 // DeploymentRolloutStatus returns a message describing deployment status, and a bool value indicating if the status is considered done.
 func DeploymentRolloutStatus(deployment *appsv1.Deployment, revision int64) (string, bool, error) {
 	if revision > 0 {
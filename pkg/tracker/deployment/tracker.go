@@ -14,11 +14,11 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/tracker/debug"
 	"github.com/werf/kubedog/pkg/tracker/event"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 	"github.com/werf/kubedog/pkg/tracker/pod"
 	"github.com/werf/kubedog/pkg/tracker/replicaset"
 	"github.com/werf/kubedog/pkg/utils"
@@ -52,7 +52,11 @@ type Tracker struct {
 	podStatuses      map[string]pod.PodStatus
 	rsNameByPod      map[string]string
 
+	// isProgressDeadlineExceeded is set when the progress deadline failure has been reported
+	isProgressDeadlineExceeded bool
+
 	ignoreReadinessProbeFailsByContainerName map[string]time.Duration
+	reportStatusFailures                     bool
 
 	TrackedPodsNames []string
 
@@ -84,13 +88,16 @@ type Tracker struct {
 }
 
 func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.Options) *Tracker {
-	return &Tracker{
+	res := &Tracker{
 		Tracker: tracker.Tracker{
 			Kube:             kube,
 			Namespace:        namespace,
 			FullResourceName: fmt.Sprintf("deploy/%s", name),
 			ResourceName:     name,
 			LogsFromTime:     opts.LogsFromTime,
+			Informers:        opts.Informers,
+			WatchRetryBudget: opts.WatchRetryBudget,
+			ServiceMsg:       make(chan string, 1),
 		},
 
 		Added:  make(chan DeploymentStatus, 1),
@@ -109,6 +116,7 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 		rsNameByPod:      make(map[string]string),
 
 		ignoreReadinessProbeFailsByContainerName: opts.IgnoreReadinessProbeFailsByContainerName,
+		reportStatusFailures:                     opts.ReportStatusFailures,
 
 		errors:             make(chan error),
 		resourceAdded:      make(chan *appsv1.Deployment, 1),
@@ -125,6 +133,8 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 		podContainerErrorsRelay: make(chan map[string]pod.ContainerErrorReport, 10),
 		donePodsRelay:           make(chan map[string]pod.PodStatus, 10),
 	}
+
+	return res
 }
 
 // Track starts tracking of deployment rollout process.
@@ -155,6 +165,7 @@ func (d *Tracker) Track(ctx context.Context) (err error) {
 			d.knownReplicaSets = make(map[string]*appsv1.ReplicaSet)
 			d.podStatuses = make(map[string]pod.PodStatus)
 			d.rsNameByPod = make(map[string]string)
+			d.isProgressDeadlineExceeded = false
 			d.TrackedPodsNames = nil
 			d.Status <- DeploymentStatus{}
 
@@ -393,17 +404,17 @@ func (d *Tracker) runDeploymentInformer(ctx context.Context) {
 		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", d.ResourceName).String()
 		return options
 	}
-	lw := &cache.ListWatch{
+	lw := d.Informers.ListWatch(ctx, informer.Deployments, d.Namespace, tweakListOptions, &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return client.AppsV1().Deployments(d.Namespace).List(ctx, tweakListOptions(options))
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return client.AppsV1().Deployments(d.Namespace).Watch(ctx, tweakListOptions(options))
 		},
-	}
+	})
 
 	go func() {
-		_, err := watchtools.UntilWithSync(ctx, lw, &appsv1.Deployment{}, nil, func(e watch.Event) (bool, error) {
+		_, err := d.UntilWithSync(ctx, lw, &appsv1.Deployment{}, func(e watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("    deploy/%s event: %#v\n", d.ResourceName, e.Type)
 			}
@@ -465,6 +476,9 @@ func (d *Tracker) runPodTracker(_ctx context.Context, podName, rsName string) er
 	newCtx, cancelPodCtx := context.WithCancel(_ctx)
 	podTracker := pod.NewTracker(podName, d.Namespace, d.Kube, pod.Options{
 		IgnoreReadinessProbeFailsByContainerName: d.ignoreReadinessProbeFailsByContainerName,
+		Informers:                                d.Informers,
+		WatchRetryBudget:                         d.WatchRetryBudget,
+		ReportStatusFailures:                     d.reportStatusFailures,
 	})
 	if !d.LogsFromTime.IsZero() {
 		podTracker.LogsFromTime = d.LogsFromTime
@@ -508,6 +522,8 @@ func (d *Tracker) runPodTracker(_ctx context.Context, podName, rsName string) er
 
 			case msg := <-podTracker.EventMsg:
 				d.EventMsg <- fmt.Sprintf("po/%s %s", podTracker.ResourceName, msg)
+			case msg := <-podTracker.ServiceMsg:
+				d.ServiceMsg <- fmt.Sprintf("po/%s %s", podTracker.ResourceName, msg)
 			case chunk := <-podTracker.ContainerLogChunk:
 				d.podLogChunksRelay <- map[string]*pod.ContainerLogChunk{podTracker.ResourceName: chunk}
 			case report := <-podTracker.ContainerError:
@@ -587,6 +603,15 @@ func (d *Tracker) handleDeploymentState(ctx context.Context, object *appsv1.Depl
 		}
 	}
 
+	// Progress deadline failure is reported once, when the controller sets ProgressDeadlineExceeded condition,
+	// and is reported again only if the rollout progresses and exceeds the deadline once more
+	if status.IsProgressDeadlineExceeded && !d.isProgressDeadlineExceeded && !status.IsFailed {
+		status.IsFailed = true
+		status.FailedReason = fmt.Sprintf("%s: %s", utils.TimedOutReason, status.ProgressDeadlineMessage)
+		d.Failed <- status
+	}
+	d.isProgressDeadlineExceeded = status.IsProgressDeadlineExceeded
+
 	return nil
 }
 
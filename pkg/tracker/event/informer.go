@@ -7,15 +7,16 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/tracker/debug"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 	"github.com/werf/kubedog/pkg/utils"
 )
 
@@ -29,6 +30,12 @@ type ReadinessProbeFailure struct {
 	Message       string
 }
 
+// VolumeMountFailure is sent for FailedMount and FailedAttachVolume events of the pod.
+type VolumeMountFailure struct {
+	Reason  string
+	Message string
+}
+
 type EventInformer struct {
 	tracker.Tracker
 	Resource interface{}
@@ -49,6 +56,9 @@ func NewEventInformer(trk *tracker.Tracker, resource interface{}) *EventInformer
 			Kube:             trk.Kube,
 			Namespace:        trk.Namespace,
 			FullResourceName: trk.FullResourceName,
+			Informers:        trk.Informers,
+			WatchRetryBudget: trk.WatchRetryBudget,
+			ServiceMsg:       trk.ServiceMsg,
 		},
 		Resource:         resource,
 		Errors:           make(chan error),
@@ -74,20 +84,20 @@ func (e *EventInformer) Run(ctx context.Context) {
 		return options
 	}
 
-	lwe := &cache.ListWatch{
+	lwe := e.Informers.ListWatch(ctx, informer.Events, e.Namespace, tweakEventListOptions, &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return client.CoreV1().Events(e.Namespace).List(ctx, tweakEventListOptions(options))
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return client.CoreV1().Events(e.Namespace).Watch(ctx, tweakEventListOptions(options))
 		},
-	}
+	})
 
 	go func() {
 		if debug.Debug() {
 			fmt.Printf("> %s run event informer\n", e.FullResourceName)
 		}
-		_, err := watchtools.UntilWithSync(ctx, lwe, &corev1.Event{}, nil, func(ev watch.Event) (bool, error) {
+		_, err := e.UntilWithSync(ctx, lwe, &corev1.Event{}, func(ev watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("    %s event: %#v\n", e.FullResourceName, ev.Type)
 			}
@@ -136,6 +146,23 @@ func (e *EventInformer) Run(ctx context.Context) {
 
 // handleInitialEvents saves uids of existed k8s events to ignore watch.Added events on them
 func (e *EventInformer) handleInitialEvents(ctx context.Context) {
+	if e.Informers != nil {
+		events, err := e.Informers.List(ctx, informer.Events, e.Namespace, metav1.ListOptions{FieldSelector: utils.EventFieldSelectorFromResource(e.Resource)})
+		if err != nil {
+			if debug.Debug() {
+				fmt.Printf("list event error: %v\n", err)
+			}
+			return
+		}
+
+		for _, ev := range events {
+			if accessor, err := meta.Accessor(ev); err == nil {
+				e.initialEventUids[accessor.GetUID()] = true
+			}
+		}
+		return
+	}
+
 	evList, err := utils.ListEventsForObject(ctx, e.Kube, e.Resource)
 	if err != nil {
 		if debug.Debug() {
@@ -202,6 +229,13 @@ func (e *EventInformer) handlePodEvent(event *corev1.Event, pod *corev1.Pod, msg
 		}
 	}
 
+	if event.Reason == "FailedMount" || event.Reason == "FailedAttachVolume" {
+		e.Failures <- VolumeMountFailure{
+			Reason:  event.Reason,
+			Message: msg,
+		}
+	}
+
 	if strings.Contains(event.Message, "Readiness probe failed:") {
 		e.Failures <- ReadinessProbeFailure{
 			ContainerName: strings.TrimSuffix(strings.Split(event.InvolvedObject.FieldPath, "{")[1], "}"),
@@ -0,0 +1,186 @@
+package generic
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	watchtools "k8s.io/client-go/tools/watch"
+
+	"github.com/werf/kubedog/pkg/tracker"
+)
+
+type Feed interface {
+	OnAdded(func() error)
+	OnReady(func() error)
+	OnFailed(func(reason string) error)
+	OnEventMsg(func(msg string) error)
+	OnServiceMsg(func(msg string) error)
+	OnStatus(func(ResourceStatus) error)
+
+	GetStatus() ResourceStatus
+	Track(name, namespace string, groupVersionResource schema.GroupVersionResource, kube kubernetes.Interface, dynamicClient dynamic.Interface, opts tracker.Options) error
+}
+
+func NewFeed() Feed {
+	return &feed{}
+}
+
+type feed struct {
+	OnAddedFunc      func() error
+	OnReadyFunc      func() error
+	OnFailedFunc     func(string) error
+	OnEventMsgFunc   func(string) error
+	OnServiceMsgFunc func(string) error
+	OnStatusFunc     func(ResourceStatus) error
+
+	statusMux sync.Mutex
+	status    ResourceStatus
+}
+
+func (f *feed) OnAdded(function func() error) {
+	f.OnAddedFunc = function
+}
+
+func (f *feed) OnReady(function func() error) {
+	f.OnReadyFunc = function
+}
+
+func (f *feed) OnFailed(function func(string) error) {
+	f.OnFailedFunc = function
+}
+
+func (f *feed) OnEventMsg(function func(string) error) {
+	f.OnEventMsgFunc = function
+}
+
+func (f *feed) OnServiceMsg(function func(string) error) {
+	f.OnServiceMsgFunc = function
+}
+
+func (f *feed) OnStatus(function func(ResourceStatus) error) {
+	f.OnStatusFunc = function
+}
+
+func (f *feed) Track(name, namespace string, groupVersionResource schema.GroupVersionResource, kube kubernetes.Interface, dynamicClient dynamic.Interface, opts tracker.Options) error {
+	errorChan := make(chan error)
+	doneChan := make(chan struct{})
+
+	parentContext := opts.ParentContext
+	if parentContext == nil {
+		parentContext = context.Background()
+	}
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(parentContext, opts.Timeout)
+	defer cancel()
+
+	generic := NewTracker(name, namespace, groupVersionResource, kube, dynamicClient, opts)
+
+	go func() {
+		err := generic.Track(ctx)
+		if err != nil {
+			errorChan <- err
+		} else {
+			doneChan <- struct{}{}
+		}
+	}()
+
+	for {
+		select {
+		case status := <-generic.Added:
+			f.setStatus(status)
+
+			if f.OnAddedFunc != nil {
+				err := f.OnAddedFunc()
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+		case status := <-generic.Ready:
+			f.setStatus(status)
+
+			if f.OnReadyFunc != nil {
+				err := f.OnReadyFunc()
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+		case status := <-generic.Failed:
+			f.setStatus(status)
+
+			if f.OnFailedFunc != nil {
+				err := f.OnFailedFunc(status.FailedReason)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+		case msg := <-generic.EventMsg:
+			if f.OnEventMsgFunc != nil {
+				err := f.OnEventMsgFunc(msg)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+		case msg := <-generic.ServiceMsg:
+			if f.OnServiceMsgFunc != nil {
+				err := f.OnServiceMsgFunc(msg)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+		case status := <-generic.Status:
+			f.setStatus(status)
+
+			if f.OnStatusFunc != nil {
+				err := f.OnStatusFunc(status)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+		case err := <-errorChan:
+			return err
+		case <-doneChan:
+			return nil
+		}
+	}
+}
+
+func (f *feed) setStatus(status ResourceStatus) {
+	f.statusMux.Lock()
+	defer f.statusMux.Unlock()
+
+	if status.StatusGeneration > f.status.StatusGeneration {
+		f.status = status
+	}
+}
+
+func (f *feed) GetStatus() ResourceStatus {
+	f.statusMux.Lock()
+	defer f.statusMux.Unlock()
+	return f.status
+}
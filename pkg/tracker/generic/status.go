@@ -0,0 +1,245 @@
+package generic
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/werf/kubedog/pkg/tracker/indicators"
+	"github.com/werf/kubedog/pkg/utils"
+)
+
+// Resource states in the same sense as in the kstatus library.
+const (
+	CurrentStatus     = "Current"
+	InProgressStatus  = "InProgress"
+	FailedStatus      = "Failed"
+	TerminatingStatus = "Terminating"
+	NotFoundStatus    = "NotFound"
+)
+
+type ResourceStatus struct {
+	StatusGeneration uint64
+
+	// StatusIndicator value is one of: Current, InProgress, Failed, Terminating or NotFound.
+	StatusIndicator *indicators.StringEqualConditionIndicator
+	Message         string
+
+	Age string
+
+	IsReady      bool
+	IsFailed     bool
+	FailedReason string
+}
+
+// statusRule computes the state and the message of the resource of the specific kind.
+type statusRule func(object *unstructured.Unstructured) (string, string)
+
+var statusRulesByGroupKind = map[schema.GroupKind]statusRule{
+	{Group: "", Kind: "Service"}:                                      serviceStatus,
+	{Group: "", Kind: "PersistentVolumeClaim"}:                        persistentVolumeClaimStatus,
+	{Group: "networking.k8s.io", Kind: "Ingress"}:                     ingressStatus,
+	{Group: "extensions", Kind: "Ingress"}:                            ingressStatus,
+	{Group: "policy", Kind: "PodDisruptionBudget"}:                    podDisruptionBudgetStatus,
+	{Group: "argoproj.io", Kind: "Rollout"}:                           argoRolloutStatus,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: customResourceDefinitionStatus,
+}
+
+func NewResourceStatus(object *unstructured.Unstructured, statusGeneration uint64) ResourceStatus {
+	res := ResourceStatus{
+		StatusGeneration: statusGeneration,
+		StatusIndicator:  &indicators.StringEqualConditionIndicator{TargetValue: CurrentStatus, FailedValue: FailedStatus},
+		Age:              utils.TranslateTimestampSince(object.GetCreationTimestamp()),
+	}
+
+	res.StatusIndicator.Value, res.Message = computeStatus(object)
+
+	switch res.StatusIndicator.Value {
+	case CurrentStatus:
+		res.IsReady = true
+	case FailedStatus:
+		res.IsFailed = true
+		res.FailedReason = res.Message
+	}
+
+	return res
+}
+
+func newNotFoundResourceStatus(statusGeneration uint64) ResourceStatus {
+	return ResourceStatus{
+		StatusGeneration: statusGeneration,
+		StatusIndicator:  &indicators.StringEqualConditionIndicator{Value: NotFoundStatus, TargetValue: CurrentStatus, FailedValue: FailedStatus},
+		Message:          "resource not found",
+	}
+}
+
+func computeStatus(object *unstructured.Unstructured) (string, string) {
+	if object.GetDeletionTimestamp() != nil {
+		return TerminatingStatus, "resource is being deleted"
+	}
+
+	// Status is not actual until controller has observed the last generation of the resource
+	observedGeneration, found, err := unstructured.NestedInt64(object.Object, "status", "observedGeneration")
+	if err == nil && found && observedGeneration < object.GetGeneration() {
+		return InProgressStatus, fmt.Sprintf("waiting for generation %d to be observed, current observed generation is %d", object.GetGeneration(), observedGeneration)
+	}
+
+	if rule, hasKey := statusRulesByGroupKind[object.GroupVersionKind().GroupKind()]; hasKey {
+		return rule(object)
+	}
+
+	return conditionsStatus(object)
+}
+
+// conditionsStatus judges readiness of arbitrary resource by the conventional status conditions:
+// Stalled=True means failure, Reconciling=True means progress, Ready or Available condition means readiness.
+// Resources without such conditions are ready as soon as they exist.
+func conditionsStatus(object *unstructured.Unstructured) (string, string) {
+	conditions := getConditions(object)
+
+	if cond, hasKey := conditions["Stalled"]; hasKey && cond.Status == "True" {
+		return FailedStatus, cond.format()
+	}
+
+	if cond, hasKey := conditions["Reconciling"]; hasKey && cond.Status == "True" {
+		return InProgressStatus, cond.format()
+	}
+
+	for _, condType := range []string{"Ready", "Available"} {
+		cond, hasKey := conditions[condType]
+		if !hasKey {
+			continue
+		}
+
+		if cond.Status == "True" {
+			return CurrentStatus, ""
+		}
+		return InProgressStatus, cond.format()
+	}
+
+	return CurrentStatus, ""
+}
+
+func serviceStatus(object *unstructured.Unstructured) (string, string) {
+	serviceType, _, _ := unstructured.NestedString(object.Object, "spec", "type")
+	if serviceType != "LoadBalancer" {
+		return CurrentStatus, ""
+	}
+
+	ingress, _, _ := unstructured.NestedSlice(object.Object, "status", "loadBalancer", "ingress")
+	if len(ingress) == 0 {
+		return InProgressStatus, "waiting for load balancer ingress"
+	}
+	return CurrentStatus, ""
+}
+
+func ingressStatus(object *unstructured.Unstructured) (string, string) {
+	ingress, _, _ := unstructured.NestedSlice(object.Object, "status", "loadBalancer", "ingress")
+	if len(ingress) == 0 {
+		return InProgressStatus, "waiting for load balancer ingress"
+	}
+	return CurrentStatus, ""
+}
+
+func persistentVolumeClaimStatus(object *unstructured.Unstructured) (string, string) {
+	phase, _, _ := unstructured.NestedString(object.Object, "status", "phase")
+
+	switch phase {
+	case "Bound":
+		return CurrentStatus, ""
+	case "Lost":
+		return FailedStatus, "persistent volume claim lost its underlying volume"
+	default:
+		return InProgressStatus, fmt.Sprintf("phase is %q, waiting for Bound", phase)
+	}
+}
+
+func podDisruptionBudgetStatus(object *unstructured.Unstructured) (string, string) {
+	currentHealthy, _, _ := unstructured.NestedInt64(object.Object, "status", "currentHealthy")
+	desiredHealthy, _, _ := unstructured.NestedInt64(object.Object, "status", "desiredHealthy")
+
+	if currentHealthy < desiredHealthy {
+		return InProgressStatus, fmt.Sprintf("%d healthy pods of %d desired", currentHealthy, desiredHealthy)
+	}
+	return CurrentStatus, ""
+}
+
+func argoRolloutStatus(object *unstructured.Unstructured) (string, string) {
+	phase, _, _ := unstructured.NestedString(object.Object, "status", "phase")
+	message, _, _ := unstructured.NestedString(object.Object, "status", "message")
+
+	switch phase {
+	case "Healthy":
+		return CurrentStatus, ""
+	case "Degraded":
+		return FailedStatus, formatPhaseMessage(phase, message)
+	case "":
+		return InProgressStatus, "waiting for status phase"
+	default:
+		return InProgressStatus, formatPhaseMessage(phase, message)
+	}
+}
+
+func customResourceDefinitionStatus(object *unstructured.Unstructured) (string, string) {
+	conditions := getConditions(object)
+
+	if cond, hasKey := conditions["NamesAccepted"]; hasKey && cond.Status == "False" {
+		return FailedStatus, cond.format()
+	}
+
+	if cond, hasKey := conditions["Established"]; hasKey && cond.Status == "True" {
+		return CurrentStatus, ""
+	}
+	return InProgressStatus, "waiting for Established condition"
+}
+
+func formatPhaseMessage(phase, message string) string {
+	if message == "" {
+		return fmt.Sprintf("phase is %s", phase)
+	}
+	return fmt.Sprintf("phase is %s: %s", phase, message)
+}
+
+type condition struct {
+	Type    string
+	Status  string
+	Reason  string
+	Message string
+}
+
+func (cond condition) format() string {
+	parts := []string{fmt.Sprintf("%s=%s", cond.Type, cond.Status)}
+	if cond.Reason != "" {
+		parts = append(parts, cond.Reason)
+	}
+	if cond.Message != "" {
+		parts = append(parts, cond.Message)
+	}
+	return strings.Join(parts, ": ")
+}
+
+func getConditions(object *unstructured.Unstructured) map[string]condition {
+	res := make(map[string]condition)
+
+	items, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		cond := condition{}
+		cond.Type, _, _ = unstructured.NestedString(fields, "type")
+		cond.Status, _, _ = unstructured.NestedString(fields, "status")
+		cond.Reason, _, _ = unstructured.NestedString(fields, "reason")
+		cond.Message, _, _ = unstructured.NestedString(fields, "message")
+
+		if cond.Type != "" {
+			res[cond.Type] = cond
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,184 @@
+package generic
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestComputeStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		object          map[string]interface{}
+		isDeleted       bool
+		expectedStatus  string
+		expectedMessage string
+	}{
+		{
+			name:           "resource without status",
+			object:         map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"},
+			expectedStatus: CurrentStatus,
+		},
+		{
+			name:            "deleted resource",
+			object:          map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"},
+			isDeleted:       true,
+			expectedStatus:  TerminatingStatus,
+			expectedMessage: "resource is being deleted",
+		},
+		{
+			name: "not observed generation",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v1", "kind": "Database",
+				"metadata": map[string]interface{}{"generation": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"conditions":         []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+				},
+			},
+			expectedStatus:  InProgressStatus,
+			expectedMessage: "waiting for generation 3 to be observed, current observed generation is 2",
+		},
+		{
+			name: "ready condition",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v1", "kind": "Database",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+				},
+			},
+			expectedStatus: CurrentStatus,
+		},
+		{
+			name: "not ready condition",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v1", "kind": "Database",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False", "reason": "Provisioning", "message": "creating volume"}},
+				},
+			},
+			expectedStatus:  InProgressStatus,
+			expectedMessage: "Ready=False: Provisioning: creating volume",
+		},
+		{
+			name: "stalled condition takes precedence",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v1", "kind": "Database",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False"},
+						map[string]interface{}{"type": "Reconciling", "status": "True"},
+						map[string]interface{}{"type": "Stalled", "status": "True", "reason": "QuotaExceeded"},
+					},
+				},
+			},
+			expectedStatus:  FailedStatus,
+			expectedMessage: "Stalled=True: QuotaExceeded",
+		},
+		{
+			name: "reconciling condition",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v1", "kind": "Database",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Available", "status": "True"},
+						map[string]interface{}{"type": "Reconciling", "status": "True"},
+					},
+				},
+			},
+			expectedStatus:  InProgressStatus,
+			expectedMessage: "Reconciling=True",
+		},
+		{
+			name: "cluster ip service",
+			object: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Service",
+				"spec": map[string]interface{}{"type": "ClusterIP"},
+			},
+			expectedStatus: CurrentStatus,
+		},
+		{
+			name: "load balancer service without ingress",
+			object: map[string]interface{}{
+				"apiVersion": "v1", "kind": "Service",
+				"spec": map[string]interface{}{"type": "LoadBalancer"},
+			},
+			expectedStatus:  InProgressStatus,
+			expectedMessage: "waiting for load balancer ingress",
+		},
+		{
+			name: "lost persistent volume claim",
+			object: map[string]interface{}{
+				"apiVersion": "v1", "kind": "PersistentVolumeClaim",
+				"status": map[string]interface{}{"phase": "Lost"},
+			},
+			expectedStatus:  FailedStatus,
+			expectedMessage: "persistent volume claim lost its underlying volume",
+		},
+		{
+			name: "pending persistent volume claim",
+			object: map[string]interface{}{
+				"apiVersion": "v1", "kind": "PersistentVolumeClaim",
+				"status": map[string]interface{}{"phase": "Pending"},
+			},
+			expectedStatus:  InProgressStatus,
+			expectedMessage: `phase is "Pending", waiting for Bound`,
+		},
+		{
+			name: "pod disruption budget without enough healthy pods",
+			object: map[string]interface{}{
+				"apiVersion": "policy/v1beta1", "kind": "PodDisruptionBudget",
+				"status": map[string]interface{}{"currentHealthy": int64(1), "desiredHealthy": int64(2)},
+			},
+			expectedStatus:  InProgressStatus,
+			expectedMessage: "1 healthy pods of 2 desired",
+		},
+		{
+			name: "degraded argo rollout",
+			object: map[string]interface{}{
+				"apiVersion": "argoproj.io/v1alpha1", "kind": "Rollout",
+				"status": map[string]interface{}{"phase": "Degraded", "message": "ProgressDeadlineExceeded"},
+			},
+			expectedStatus:  FailedStatus,
+			expectedMessage: "phase is Degraded: ProgressDeadlineExceeded",
+		},
+		{
+			name: "established custom resource definition",
+			object: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+						map[string]interface{}{"type": "Established", "status": "True"},
+					},
+				},
+			},
+			expectedStatus: CurrentStatus,
+		},
+		{
+			name: "custom resource definition with names conflict",
+			object: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{map[string]interface{}{"type": "NamesAccepted", "status": "False", "reason": "MultipleNamesNotAllowed"}},
+				},
+			},
+			expectedStatus:  FailedStatus,
+			expectedMessage: "NamesAccepted=False: MultipleNamesNotAllowed",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			object := &unstructured.Unstructured{Object: tc.object}
+			if tc.isDeleted {
+				now := metav1.Now()
+				object.SetDeletionTimestamp(&now)
+			}
+
+			status, message := computeStatus(object)
+			if status != tc.expectedStatus || message != tc.expectedMessage {
+				t.Errorf("expected %s %q, got %s %q", tc.expectedStatus, tc.expectedMessage, status, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/werf/kubedog/pkg/tracker"
+	"github.com/werf/kubedog/pkg/tracker/debug"
+	"github.com/werf/kubedog/pkg/tracker/event"
+)
+
+// Tracker tracks arbitrary resource by its status, see NewResourceStatus for readiness rules.
+type Tracker struct {
+	tracker.Tracker
+
+	DynamicClient        dynamic.Interface
+	GroupVersionResource schema.GroupVersionResource
+
+	Added  chan ResourceStatus
+	Ready  chan ResourceStatus
+	Failed chan ResourceStatus
+	Status chan ResourceStatus
+
+	EventMsg chan string
+
+	State tracker.TrackerState
+
+	lastObject       *unstructured.Unstructured
+	failedReason     string
+	eventsObjectUID  types.UID
+	cancelEventsFunc context.CancelFunc
+
+	errors chan error
+
+	objectAdded    chan *unstructured.Unstructured
+	objectModified chan *unstructured.Unstructured
+	objectDeleted  chan *unstructured.Unstructured
+	eventFailures  chan interface{}
+}
+
+func NewTracker(name, namespace string, groupVersionResource schema.GroupVersionResource, kube kubernetes.Interface, dynamicClient dynamic.Interface, opts tracker.Options) *Tracker {
+	res := &Tracker{
+		Tracker: tracker.Tracker{
+			Kube:             kube,
+			Namespace:        namespace,
+			FullResourceName: fmt.Sprintf("%s/%s", FormatResourceKind(groupVersionResource), name),
+			ResourceName:     name,
+			LogsFromTime:     opts.LogsFromTime,
+			Informers:        opts.Informers,
+			WatchRetryBudget: opts.WatchRetryBudget,
+			ServiceMsg:       make(chan string, 1),
+		},
+
+		DynamicClient:        dynamicClient,
+		GroupVersionResource: groupVersionResource,
+
+		Added:  make(chan ResourceStatus, 1),
+		Ready:  make(chan ResourceStatus),
+		Failed: make(chan ResourceStatus),
+		Status: make(chan ResourceStatus, 100),
+
+		EventMsg: make(chan string, 1),
+
+		State: tracker.Initial,
+
+		objectAdded:    make(chan *unstructured.Unstructured),
+		objectModified: make(chan *unstructured.Unstructured),
+		objectDeleted:  make(chan *unstructured.Unstructured),
+		eventFailures:  make(chan interface{}, 1),
+		errors:         make(chan error),
+	}
+
+	return res
+}
+
+// FormatResourceKind returns the resource kind in the kubectl format: resource.group (e.g. certificates.cert-manager.io).
+func FormatResourceKind(groupVersionResource schema.GroupVersionResource) string {
+	if groupVersionResource.Group == "" {
+		return groupVersionResource.Resource
+	}
+	return fmt.Sprintf("%s.%s", groupVersionResource.Resource, groupVersionResource.Group)
+}
+
+func (generic *Tracker) Track(ctx context.Context) error {
+	defer generic.stopEventsInformer()
+
+	generic.runInformer(ctx)
+
+	for {
+		select {
+		case object := <-generic.objectAdded:
+			generic.runEventsInformer(ctx, object)
+
+			if err := generic.handleResourceState(object); err != nil {
+				return err
+			}
+		case object := <-generic.objectModified:
+			if err := generic.handleResourceState(object); err != nil {
+				return err
+			}
+		case <-generic.objectDeleted:
+			generic.stopEventsInformer()
+
+			generic.lastObject = nil
+			generic.State = tracker.Initial
+			generic.StatusGeneration++
+			generic.Status <- newNotFoundResourceStatus(generic.StatusGeneration)
+		case <-generic.eventFailures:
+			// Readiness is judged only by the resource status, failure events are displayed as regular events
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				return nil
+			}
+			return ctx.Err()
+		case err := <-generic.errors:
+			return err
+		}
+	}
+}
+
+func (generic *Tracker) runInformer(ctx context.Context) {
+	client := generic.DynamicClient.Resource(generic.GroupVersionResource).Namespace(generic.Namespace)
+
+	tweakListOptions := func(options metav1.ListOptions) metav1.ListOptions {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", generic.ResourceName).String()
+		return options
+	}
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.List(ctx, tweakListOptions(options))
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.Watch(ctx, tweakListOptions(options))
+		},
+	}
+
+	go func() {
+		_, err := generic.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, func(e watch.Event) (bool, error) {
+			if debug.Debug() {
+				fmt.Printf("%s informer event: %#v\n", generic.FullResourceName, e.Type)
+			}
+
+			var object *unstructured.Unstructured
+
+			if e.Type != watch.Error {
+				var ok bool
+				object, ok = e.Object.(*unstructured.Unstructured)
+				if !ok {
+					return true, fmt.Errorf("expected %s to be a *unstructured.Unstructured, got %T", generic.FullResourceName, e.Object)
+				}
+			}
+
+			switch e.Type {
+			case watch.Added:
+				generic.objectAdded <- object
+			case watch.Modified:
+				generic.objectModified <- object
+			case watch.Deleted:
+				generic.objectDeleted <- object
+			case watch.Error:
+				return true, fmt.Errorf("%s watch error: %v", generic.FullResourceName, e.Object)
+			}
+
+			return false, nil
+		})
+
+		if err := tracker.AdaptInformerError(err); err != nil {
+			generic.errors <- fmt.Errorf("%s informer error: %s", generic.FullResourceName, err)
+		}
+
+		if debug.Debug() {
+			fmt.Printf("%s informer done\n", generic.FullResourceName)
+		}
+	}()
+}
+
+// runEventsInformer starts events informer for the object, events informer is restarted when the resource is re-created.
+func (generic *Tracker) runEventsInformer(ctx context.Context, object *unstructured.Unstructured) {
+	if generic.Kube == nil || generic.eventsObjectUID == object.GetUID() {
+		return
+	}
+	generic.stopEventsInformer()
+
+	eventsCtx, cancel := context.WithCancel(ctx)
+	generic.cancelEventsFunc = cancel
+	generic.eventsObjectUID = object.GetUID()
+
+	eventInformer := event.NewEventInformer(&generic.Tracker, object)
+	eventInformer.WithChannels(generic.EventMsg, generic.eventFailures, generic.errors)
+	eventInformer.Run(eventsCtx)
+}
+
+func (generic *Tracker) stopEventsInformer() {
+	if generic.cancelEventsFunc != nil {
+		generic.cancelEventsFunc()
+		generic.cancelEventsFunc = nil
+		generic.eventsObjectUID = ""
+	}
+}
+
+func (generic *Tracker) handleResourceState(object *unstructured.Unstructured) error {
+	generic.lastObject = object
+	generic.StatusGeneration++
+
+	status := NewResourceStatus(object, generic.StatusGeneration)
+
+	switch {
+	case status.IsFailed:
+		// Failure is reported once until the failed reason changes
+		if generic.State == tracker.ResourceFailed && generic.failedReason == status.FailedReason {
+			generic.Status <- status
+			return nil
+		}
+
+		generic.State = tracker.ResourceFailed
+		generic.failedReason = status.FailedReason
+		generic.Failed <- status
+	case status.IsReady:
+		generic.State = tracker.ResourceReady
+		generic.Ready <- status
+	case generic.State == tracker.Initial:
+		generic.State = tracker.ResourceAdded
+		generic.Added <- status
+	default:
+		generic.State = tracker.ResourceAdded
+		generic.Status <- status
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package informer
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// eventQueue implements watch.Interface, events are buffered so that a slow consumer does not block the producer.
+type eventQueue struct {
+	result   chan watch.Event
+	notify   chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	onStop   func()
+
+	mux   sync.Mutex
+	queue []watch.Event
+}
+
+func newEventQueue(ctx context.Context, onStop func()) *eventQueue {
+	q := &eventQueue{
+		result: make(chan watch.Event),
+		notify: make(chan struct{}, 1),
+		stopCh: make(chan struct{}),
+		onStop: onStop,
+	}
+
+	go q.run(ctx)
+
+	return q
+}
+
+func (q *eventQueue) Stop() {
+	q.stopOnce.Do(func() {
+		q.onStop()
+		close(q.stopCh)
+	})
+}
+
+func (q *eventQueue) ResultChan() <-chan watch.Event {
+	return q.result
+}
+
+func (q *eventQueue) push(event watch.Event) {
+	q.mux.Lock()
+	q.queue = append(q.queue, event)
+	q.mux.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers queued events until the queue is stopped. The result channel is left open when ctx is done:
+// the consumer is stopped by the same context, closed channel would be reported by the reflector as a watch failure.
+func (q *eventQueue) run(ctx context.Context) {
+	for {
+		q.mux.Lock()
+		if len(q.queue) == 0 {
+			q.mux.Unlock()
+
+			select {
+			case <-q.notify:
+				continue
+			case <-q.stopCh:
+				close(q.result)
+				return
+			case <-ctx.Done():
+				q.onStop()
+				return
+			}
+		}
+
+		event := q.queue[0]
+		q.queue = q.queue[1:]
+		q.mux.Unlock()
+
+		select {
+		case q.result <- event:
+		case <-q.stopCh:
+			close(q.result)
+			return
+		case <-ctx.Done():
+			q.onStop()
+			return
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package informer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/werf/kubedog/pkg/tracker/debug"
+)
+
+type Kind string
+
+const (
+	Pods         Kind = "pods"
+	ReplicaSets  Kind = "replicasets"
+	Events       Kind = "events"
+	Deployments  Kind = "deployments"
+	StatefulSets Kind = "statefulsets"
+	DaemonSets   Kind = "daemonsets"
+	Jobs         Kind = "jobs"
+	Nodes        Kind = "nodes"
+
+	PersistentVolumeClaims Kind = "persistentvolumeclaims"
+)
+
+// Factory runs informers shared by the trackers: there is a single list/watch for every kind of resources
+// in the namespace, trackers subscribe to it with ListWatch and filter resources by selectors in memory.
+// Nil Factory is valid, every tracker lists and watches its resources by itself in this case.
+type Factory struct {
+	ctx  context.Context
+	kube kubernetes.Interface
+
+	mux                   sync.Mutex
+	informers             map[informerKey]*sharedInformer
+	watchReconnectHandler func(resource string)
+}
+
+type informerKey struct {
+	Kind      Kind
+	Namespace string
+}
+
+// NewFactory creates Factory, informers are started on the first subscription and are stopped when ctx is done.
+func NewFactory(ctx context.Context, kube kubernetes.Interface) *Factory {
+	return &Factory{
+		ctx:       ctx,
+		kube:      kube,
+		informers: make(map[informerKey]*sharedInformer),
+	}
+}
+
+// ListWatch returns ListerWatcher of the resources of the kind in the namespace, which match field and label selectors
+// set by tweakListOptions. Resources are served from the shared informer cache, lw is returned as is for nil Factory.
+func (f *Factory) ListWatch(ctx context.Context, kind Kind, namespace string, tweakListOptions func(metav1.ListOptions) metav1.ListOptions, lw cache.ListerWatcher) cache.ListerWatcher {
+	if f == nil {
+		return lw
+	}
+
+	return &listWatch{
+		ctx:              ctx,
+		informer:         f.getInformer(kind, namespace),
+		tweakListOptions: tweakListOptions,
+	}
+}
+
+// List returns resources of the kind in the namespace matching options selectors from the shared informer cache.
+func (f *Factory) List(ctx context.Context, kind Kind, namespace string, options metav1.ListOptions) ([]runtime.Object, error) {
+	filter, err := newFilter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	informer := f.getInformer(kind, namespace)
+	if err := informer.waitForSync(ctx); err != nil {
+		return nil, err
+	}
+
+	res, _ := informer.list(filter)
+	return res, nil
+}
+
+// SetWatchReconnectHandler sets the handler called on every repeated watch request to the API server, which is done
+// when the watch is closed by the server or failed. Handler is called with the name of the watched resources.
+func (f *Factory) SetWatchReconnectHandler(handler func(resource string)) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.watchReconnectHandler = handler
+}
+
+// HandleWatchReconnect calls the handler set by SetWatchReconnectHandler, it is no-op for nil Factory.
+func (f *Factory) HandleWatchReconnect(resource string) {
+	if f == nil {
+		return
+	}
+
+	f.mux.Lock()
+	handler := f.watchReconnectHandler
+	f.mux.Unlock()
+
+	if handler != nil {
+		handler(resource)
+	}
+}
+
+// IsShared returns true when lw serves resources from the shared informer cache and does not request the API server.
+func IsShared(lw cache.ListerWatcher) bool {
+	_, ok := lw.(*listWatch)
+	return ok
+}
+
+func (f *Factory) getInformer(kind Kind, namespace string) *sharedInformer {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	key := informerKey{Kind: kind, Namespace: namespace}
+	if informer, hasKey := f.informers[key]; hasKey {
+		return informer
+	}
+
+	// Requests are not bound to the context: informer closes the watch by itself when stopped,
+	// otherwise a cancelled request races with the stop and the watch failure is logged
+	lw, objType := newNamespaceListWatch(context.Background(), f.kube, kind, namespace)
+	name := fmt.Sprintf("%s/%s", namespace, kind)
+	lw = &reconnectListWatch{ListerWatcher: lw, onReconnect: func() { f.HandleWatchReconnect(name) }}
+	informer := newSharedInformer(f.ctx, name, cache.NewSharedIndexInformer(lw, objType, 0, cache.Indexers{}))
+	f.informers[key] = informer
+
+	if debug.Debug() {
+		fmt.Printf("> shared %s informer started\n", informer.name)
+	}
+	go informer.informer.Run(f.ctx.Done())
+
+	return informer
+}
+
+type sharedInformer struct {
+	ctx      context.Context
+	name     string
+	informer cache.SharedIndexInformer
+
+	mux      sync.Mutex
+	watchers map[*watcher]struct{}
+
+	// lastErr is the last failure of the informer list and watch requests, failures are counted by errorsCount
+	lastErr     error
+	errorsCount int
+}
+
+func newSharedInformer(ctx context.Context, name string, informer cache.SharedIndexInformer) *sharedInformer {
+	si := &sharedInformer{
+		ctx:      ctx,
+		name:     name,
+		informer: informer,
+		watchers: make(map[*watcher]struct{}),
+	}
+
+	_ = informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		si.mux.Lock()
+		si.lastErr = err
+		si.errorsCount++
+		si.mux.Unlock()
+
+		cache.DefaultWatchErrorHandler(r, err)
+	})
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			si.dispatch(nil, obj, false)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			si.dispatch(oldObj, newObj, false)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			si.dispatch(nil, obj, true)
+		},
+	})
+
+	return si
+}
+
+// waitForSync waits until the initial list of the shared informer is done. Informer retries failed requests by itself,
+// but the failure is returned to the waiting subscriber, so that it is counted and reported as its own failure.
+func (si *sharedInformer) waitForSync(ctx context.Context) error {
+	if si.informer.HasSynced() {
+		return nil
+	}
+
+	si.mux.Lock()
+	errorsCount := si.errorsCount
+	si.mux.Unlock()
+
+	syncCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-si.ctx.Done():
+			cancel()
+		case <-syncCtx.Done():
+		}
+	}()
+
+	var failure error
+	err := wait.PollImmediateUntil(100*time.Millisecond, func() (bool, error) {
+		if si.informer.HasSynced() {
+			return true, nil
+		}
+
+		si.mux.Lock()
+		defer si.mux.Unlock()
+
+		if si.errorsCount != errorsCount {
+			failure = si.lastErr
+			return true, nil
+		}
+		return false, nil
+	}, syncCtx.Done())
+
+	switch {
+	case failure != nil:
+		return failure
+	case err != nil:
+		return fmt.Errorf("shared %s informer is stopped before cache is synced", si.name)
+	default:
+		return nil
+	}
+}
+
+func (si *sharedInformer) list(filter *filter) ([]runtime.Object, string) {
+	var res []runtime.Object
+	for _, item := range si.informer.GetStore().List() {
+		obj, ok := item.(runtime.Object)
+		if ok && filter.matches(obj) {
+			res = append(res, obj.DeepCopyObject())
+		}
+	}
+	return res, si.informer.LastSyncResourceVersion()
+}
+
+// subscribe returns the current resources matching the watcher filter, all subsequent changes are sent to the watcher.
+func (si *sharedInformer) subscribe(w *watcher) ([]runtime.Object, string) {
+	si.mux.Lock()
+	defer si.mux.Unlock()
+
+	si.watchers[w] = struct{}{}
+
+	items, resourceVersion := si.list(w.filter)
+	for _, obj := range items {
+		if accessor, err := meta.Accessor(obj); err == nil && accessor.GetResourceVersion() != "" {
+			w.listedVersions[accessor.GetUID()] = accessor.GetResourceVersion()
+		}
+	}
+
+	return items, resourceVersion
+}
+
+func (si *sharedInformer) unsubscribe(w *watcher) {
+	si.mux.Lock()
+	defer si.mux.Unlock()
+
+	delete(si.watchers, w)
+}
+
+// dispatch sends the change to the watchers in the same way as the API server does for the watch with selectors:
+// the resource starting to match the selectors is added, the resource not matching anymore is deleted.
+func (si *sharedInformer) dispatch(oldItem, newItem interface{}, deleted bool) {
+	oldObj, _ := oldItem.(runtime.Object)
+	newObj, ok := newItem.(runtime.Object)
+	if !ok {
+		return
+	}
+
+	si.mux.Lock()
+	defer si.mux.Unlock()
+
+	for w := range si.watchers {
+		oldMatches := oldObj != nil && w.filter.matches(oldObj)
+		newMatches := w.filter.matches(newObj)
+
+		var eventType watch.EventType
+		switch {
+		case deleted && newMatches:
+			eventType = watch.Deleted
+		case deleted:
+			continue
+		case oldMatches && newMatches:
+			eventType = watch.Modified
+		case newMatches:
+			eventType = watch.Added
+		case oldMatches:
+			eventType = watch.Deleted
+		default:
+			continue
+		}
+
+		if eventType != watch.Deleted && w.isListed(newObj) {
+			continue
+		}
+
+		w.push(watch.Event{Type: eventType, Object: newObj.DeepCopyObject()})
+	}
+}
+
+type filter struct {
+	labels labels.Selector
+	fields fields.Selector
+}
+
+func newFilter(options metav1.ListOptions) (*filter, error) {
+	labelSelector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("bad label selector %q: %s", options.LabelSelector, err)
+	}
+
+	fieldSelector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		return nil, fmt.Errorf("bad field selector %q: %s", options.FieldSelector, err)
+	}
+
+	return &filter{labels: labelSelector, fields: fieldSelector}, nil
+}
+
+func (f *filter) matches(obj runtime.Object) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+
+	if !f.labels.Matches(labels.Set(accessor.GetLabels())) {
+		return false
+	}
+	return f.fields.Matches(getObjectFields(obj, accessor))
+}
+
+// getObjectFields returns fields supported by the field selectors of the API server, which are used by the trackers.
+func getObjectFields(obj runtime.Object, accessor metav1.Object) fields.Set {
+	res := fields.Set{
+		"metadata.name":      accessor.GetName(),
+		"metadata.namespace": accessor.GetNamespace(),
+	}
+
+	if event, ok := obj.(*corev1.Event); ok {
+		res["involvedObject.kind"] = event.InvolvedObject.Kind
+		res["involvedObject.namespace"] = event.InvolvedObject.Namespace
+		res["involvedObject.name"] = event.InvolvedObject.Name
+		res["involvedObject.uid"] = string(event.InvolvedObject.UID)
+		res["involvedObject.apiVersion"] = event.InvolvedObject.APIVersion
+		res["involvedObject.resourceVersion"] = event.InvolvedObject.ResourceVersion
+		res["involvedObject.fieldPath"] = event.InvolvedObject.FieldPath
+		res["reason"] = event.Reason
+		res["source"] = event.Source.Component
+		res["type"] = event.Type
+	}
+
+	return res
+}
+
+func newNamespaceListWatch(ctx context.Context, kube kubernetes.Interface, kind Kind, namespace string) (cache.ListerWatcher, runtime.Object) {
+	switch kind {
+	case Pods:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.CoreV1().Pods(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.CoreV1().Pods(namespace).Watch(ctx, options)
+			},
+		}, &corev1.Pod{}
+	case ReplicaSets:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.AppsV1().ReplicaSets(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.AppsV1().ReplicaSets(namespace).Watch(ctx, options)
+			},
+		}, &appsv1.ReplicaSet{}
+	case Events:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.CoreV1().Events(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.CoreV1().Events(namespace).Watch(ctx, options)
+			},
+		}, &corev1.Event{}
+	case Deployments:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.AppsV1().Deployments(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.AppsV1().Deployments(namespace).Watch(ctx, options)
+			},
+		}, &appsv1.Deployment{}
+	case StatefulSets:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.AppsV1().StatefulSets(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.AppsV1().StatefulSets(namespace).Watch(ctx, options)
+			},
+		}, &appsv1.StatefulSet{}
+	case DaemonSets:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.AppsV1().DaemonSets(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.AppsV1().DaemonSets(namespace).Watch(ctx, options)
+			},
+		}, &appsv1.DaemonSet{}
+	case Jobs:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.BatchV1().Jobs(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.BatchV1().Jobs(namespace).Watch(ctx, options)
+			},
+		}, &batchv1.Job{}
+	case Nodes:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.CoreV1().Nodes().List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.CoreV1().Nodes().Watch(ctx, options)
+			},
+		}, &corev1.Node{}
+	case PersistentVolumeClaims:
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.CoreV1().PersistentVolumeClaims(namespace).List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.CoreV1().PersistentVolumeClaims(namespace).Watch(ctx, options)
+			},
+		}, &corev1.PersistentVolumeClaim{}
+	default:
+		panic(fmt.Sprintf("unsupported shared informer kind %q", kind))
+	}
+}
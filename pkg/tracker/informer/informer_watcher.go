@@ -0,0 +1,54 @@
+package informer
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewInformerWatcher runs informer over lw and wraps it into watch.Interface the same way as
+// watchtools.NewIndexerInformerWatcher does. Resources deleted while the watch was broken are found on re-list,
+// their last known state is passed with watch.Deleted event. The returned channel is closed when the informer is stopped.
+func NewInformerWatcher(ctx context.Context, lw cache.ListerWatcher, objType runtime.Object) (watch.Interface, <-chan struct{}) {
+	stopCh := make(chan struct{})
+	var stopOnce sync.Once
+
+	queue := newEventQueue(ctx, func() {
+		stopOnce.Do(func() {
+			close(stopCh)
+		})
+	})
+
+	push := func(eventType watch.EventType, obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+
+		if object, ok := obj.(runtime.Object); ok {
+			queue.push(watch.Event{Type: eventType, Object: object})
+		}
+	}
+
+	_, informer := cache.NewIndexerInformer(lw, objType, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			push(watch.Added, obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			push(watch.Modified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			push(watch.Deleted, obj)
+		},
+	}, cache.Indexers{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		informer.Run(stopCh)
+	}()
+
+	return queue, done
+}
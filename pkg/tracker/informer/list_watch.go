@@ -0,0 +1,132 @@
+package informer
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// listWatch implements cache.ListerWatcher over the shared informer.
+// Watcher is subscribed on List, so that no changes are lost between List and the subsequent Watch.
+type listWatch struct {
+	ctx              context.Context
+	informer         *sharedInformer
+	tweakListOptions func(metav1.ListOptions) metav1.ListOptions
+
+	mux     sync.Mutex
+	pending *watcher
+}
+
+func (lw *listWatch) List(_ metav1.ListOptions) (runtime.Object, error) {
+	filter, err := newFilter(lw.tweakListOptions(metav1.ListOptions{}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lw.informer.waitForSync(lw.ctx); err != nil {
+		return nil, err
+	}
+
+	w := newWatcher(lw.ctx, lw.informer, filter)
+	items, resourceVersion := lw.informer.subscribe(w)
+
+	lw.mux.Lock()
+	if lw.pending != nil {
+		lw.pending.Stop()
+	}
+	lw.pending = w
+	lw.mux.Unlock()
+
+	list := &metav1.List{ListMeta: metav1.ListMeta{ResourceVersion: resourceVersion}}
+	for _, item := range items {
+		list.Items = append(list.Items, runtime.RawExtension{Object: item})
+	}
+
+	return list, nil
+}
+
+func (lw *listWatch) Watch(_ metav1.ListOptions) (watch.Interface, error) {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+
+	if lw.pending != nil {
+		w := lw.pending
+		lw.pending = nil
+		return w, nil
+	}
+
+	filter, err := newFilter(lw.tweakListOptions(metav1.ListOptions{}))
+	if err != nil {
+		return nil, err
+	}
+
+	w := newWatcher(lw.ctx, lw.informer, filter)
+	lw.informer.subscribe(w)
+
+	return w, nil
+}
+
+// watcher is the subscription to the shared informer.
+type watcher struct {
+	*eventQueue
+
+	filter *filter
+
+	// listedVersions are versions of the resources returned by List, informer notifications about them are skipped
+	listedVersions map[types.UID]string
+}
+
+func newWatcher(ctx context.Context, informer *sharedInformer, filter *filter) *watcher {
+	w := &watcher{
+		filter:         filter,
+		listedVersions: make(map[types.UID]string),
+	}
+	w.eventQueue = newEventQueue(ctx, func() {
+		informer.unsubscribe(w)
+	})
+
+	return w
+}
+
+// isListed checks whether the resource version has been already returned by List, should be called with locked informer mux.
+func (w *watcher) isListed(obj runtime.Object) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+
+	listedVersion, hasKey := w.listedVersions[accessor.GetUID()]
+	if !hasKey {
+		return false
+	}
+	delete(w.listedVersions, accessor.GetUID())
+
+	return listedVersion == accessor.GetResourceVersion()
+}
+
+// reconnectListWatch calls onReconnect on every watch request except the first one.
+type reconnectListWatch struct {
+	cache.ListerWatcher
+	onReconnect func()
+
+	mux     sync.Mutex
+	watches int
+}
+
+func (lw *reconnectListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	lw.mux.Lock()
+	lw.watches++
+	isReconnect := lw.watches > 1
+	lw.mux.Unlock()
+
+	if isReconnect {
+		lw.onReconnect()
+	}
+	return lw.ListerWatcher.Watch(options)
+}
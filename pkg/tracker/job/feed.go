@@ -18,6 +18,7 @@ type Feed interface {
 	OnSucceeded(func() error)
 	OnFailed(func(reason string) error)
 	OnEventMsg(func(msg string) error)
+	OnServiceMsg(func(msg string) error)
 	OnAddedPod(func(podName string) error)
 	OnPodLogChunk(func(*pod.PodLogChunk) error)
 	OnPodError(func(pod.PodError) error)
@@ -36,6 +37,7 @@ type feed struct {
 	OnSucceededFunc   func() error
 	OnFailedFunc      func(string) error
 	OnEventMsgFunc    func(string) error
+	OnServiceMsgFunc  func(string) error
 	OnAddedPodFunc    func(string) error
 	OnPodLogChunkFunc func(*pod.PodLogChunk) error
 	OnPodErrorFunc    func(pod.PodError) error
@@ -61,6 +63,10 @@ func (f *feed) OnEventMsg(function func(string) error) {
 	f.OnEventMsgFunc = function
 }
 
+func (f *feed) OnServiceMsg(function func(string) error) {
+	f.OnServiceMsgFunc = function
+}
+
 func (f *feed) OnAddedPod(function func(string) error) {
 	f.OnAddedPodFunc = function
 }
@@ -155,6 +161,17 @@ func (f *feed) Track(name, namespace string, kube kubernetes.Interface, opts tra
 				}
 			}
 
+		case msg := <-job.ServiceMsg:
+			if f.OnServiceMsgFunc != nil {
+				err := f.OnServiceMsgFunc(msg)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
 		case report := <-job.AddedPod:
 			f.setStatus(report.JobStatus)
 
@@ -14,11 +14,11 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/tracker/debug"
 	"github.com/werf/kubedog/pkg/tracker/event"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 	"github.com/werf/kubedog/pkg/tracker/pod"
 	"github.com/werf/kubedog/pkg/utils"
 )
@@ -60,6 +60,7 @@ type Tracker struct {
 	podStatuses  map[string]pod.PodStatus
 
 	ignoreReadinessProbeFailsByContainerName map[string]time.Duration
+	reportStatusFailures                     bool
 
 	objectAdded    chan *batchv1.Job
 	objectModified chan *batchv1.Job
@@ -76,13 +77,16 @@ type Tracker struct {
 }
 
 func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.Options) *Tracker {
-	return &Tracker{
+	res := &Tracker{
 		Tracker: tracker.Tracker{
 			Kube:             kube,
 			Namespace:        namespace,
 			FullResourceName: fmt.Sprintf("job/%s", name),
 			ResourceName:     name,
 			LogsFromTime:     opts.LogsFromTime,
+			Informers:        opts.Informers,
+			WatchRetryBudget: opts.WatchRetryBudget,
+			ServiceMsg:       make(chan string, 1),
 		},
 
 		Added:     make(chan JobStatus, 1),
@@ -98,6 +102,7 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 		podStatuses: make(map[string]pod.PodStatus),
 
 		ignoreReadinessProbeFailsByContainerName: opts.IgnoreReadinessProbeFailsByContainerName,
+		reportStatusFailures:                     opts.ReportStatusFailures,
 
 		State: tracker.Initial,
 
@@ -112,6 +117,8 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 		podContainerErrorsRelay: make(chan map[string]pod.ContainerErrorReport, 10),
 		donePodsRelay:           make(chan map[string]pod.PodStatus, 10),
 	}
+
+	return res
 }
 
 func (job *Tracker) Track(ctx context.Context) error {
@@ -242,17 +249,17 @@ func (job *Tracker) runInformer(ctx context.Context) error {
 		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", job.ResourceName).String()
 		return options
 	}
-	lw := &cache.ListWatch{
+	lw := job.Informers.ListWatch(ctx, informer.Jobs, job.Namespace, tweakListOptions, &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return job.Kube.BatchV1().Jobs(job.Namespace).List(ctx, tweakListOptions(options))
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return job.Kube.BatchV1().Jobs(job.Namespace).Watch(ctx, tweakListOptions(options))
 		},
-	}
+	})
 
 	go func() {
-		_, err := watchtools.UntilWithSync(ctx, lw, &batchv1.Job{}, nil, func(e watch.Event) (bool, error) {
+		_, err := job.UntilWithSync(ctx, lw, &batchv1.Job{}, func(e watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("Job `%s` informer event: %#v\n", job.ResourceName, e.Type)
 			}
@@ -359,6 +366,9 @@ func (job *Tracker) runPodTracker(_ctx context.Context, podName string) error {
 	newCtx, cancelPodCtx := context.WithCancel(_ctx)
 	podTracker := pod.NewTracker(podName, job.Namespace, job.Kube, pod.Options{
 		IgnoreReadinessProbeFailsByContainerName: job.ignoreReadinessProbeFailsByContainerName,
+		Informers:                                job.Informers,
+		WatchRetryBudget:                         job.WatchRetryBudget,
+		ReportStatusFailures:                     job.reportStatusFailures,
 	})
 	if !job.LogsFromTime.IsZero() {
 		podTracker.LogsFromTime = job.LogsFromTime
@@ -401,6 +411,8 @@ func (job *Tracker) runPodTracker(_ctx context.Context, podName string) error {
 
 			case msg := <-podTracker.EventMsg:
 				job.EventMsg <- fmt.Sprintf("po/%s %s", podTracker.ResourceName, msg)
+			case msg := <-podTracker.ServiceMsg:
+				job.ServiceMsg <- fmt.Sprintf("po/%s %s", podTracker.ResourceName, msg)
 			case chunk := <-podTracker.ContainerLogChunk:
 				podChunk := &pod.PodLogChunk{ContainerLogChunk: chunk, PodName: podTracker.ResourceName}
 				job.PodLogChunk <- podChunk
@@ -0,0 +1,131 @@
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FailureType classifies failures of the pod and its containers.
+type FailureType string
+
+const (
+	// ImagePullFailure is reported for ImagePullBackOff, ErrImagePull, ErrImageNeverPull and InvalidImageName containers.
+	ImagePullFailure FailureType = "ImagePull"
+	// CrashLoopBackOffFailure is reported for containers restarted after crash.
+	CrashLoopBackOffFailure FailureType = "CrashLoopBackOff"
+	// OOMKilledFailure is reported for containers killed because of memory limit, including crash loop after OOM kill.
+	OOMKilledFailure FailureType = "OOMKilled"
+	// CreateContainerConfigFailure is reported for CreateContainerConfigError and CreateContainerError containers,
+	// e.g. when referenced ConfigMap or Secret does not exist.
+	CreateContainerConfigFailure FailureType = "CreateContainerConfigError"
+	// UnschedulableFailure is reported for pods which cannot be scheduled, the message contains the scheduler reason.
+	UnschedulableFailure FailureType = "Unschedulable"
+	// EvictedFailure is reported for pods evicted by kubelet, e.g. on node disk pressure.
+	EvictedFailure FailureType = "Evicted"
+	// FailedMountFailure is reported for pods which volumes cannot be attached or mounted.
+	FailedMountFailure FailureType = "FailedMount"
+	// DeadlineExceededFailure is reported for pods and jobs which are active longer than activeDeadlineSeconds.
+	DeadlineExceededFailure FailureType = "DeadlineExceeded"
+	// ProbeFailure is reported for failed readiness probes and for containers restarted by liveness and startup probes.
+	ProbeFailure FailureType = "ProbeFailure"
+	// ProgressDeadlineExceededFailure is reported for deployments which have not progressed within progressDeadlineSeconds.
+	ProgressDeadlineExceededFailure FailureType = "ProgressDeadlineExceeded"
+	// OtherFailure is reported for all failures not covered by other types.
+	OtherFailure FailureType = "Other"
+)
+
+// FailureTypes are all known failure types.
+var FailureTypes = []FailureType{
+	ImagePullFailure, CrashLoopBackOffFailure, OOMKilledFailure, CreateContainerConfigFailure, UnschedulableFailure,
+	EvictedFailure, FailedMountFailure, DeadlineExceededFailure, ProbeFailure, ProgressDeadlineExceededFailure, OtherFailure,
+}
+
+// Failure is the typed failure of the pod or one of its containers, ContainerName is empty for the pod failures.
+type Failure struct {
+	Type          FailureType
+	ContainerName string
+	Message       string
+}
+
+// FailureTypeByReason classifies the reason of the container state, pod status or event.
+func FailureTypeByReason(reason string) FailureType {
+	switch reason {
+	case "ImagePullBackOff", "ErrImagePull", "ErrImageNeverPull", "InvalidImageName":
+		return ImagePullFailure
+	case "CrashLoopBackOff":
+		return CrashLoopBackOffFailure
+	case "OOMKilled":
+		return OOMKilledFailure
+	case "CreateContainerConfigError", "CreateContainerError":
+		return CreateContainerConfigFailure
+	case "Unschedulable", "FailedScheduling":
+		return UnschedulableFailure
+	case "Evicted":
+		return EvictedFailure
+	case "FailedMount", "FailedAttachVolume":
+		return FailedMountFailure
+	case "DeadlineExceeded":
+		return DeadlineExceededFailure
+	case "ProgressDeadlineExceeded":
+		return ProgressDeadlineExceededFailure
+	default:
+		return OtherFailure
+	}
+}
+
+// getContainerFailure returns the failure of the waiting or terminated container, nil if container is not failed.
+func getContainerFailure(cs corev1.ContainerStatus) *Failure {
+	switch {
+	case cs.State.Waiting != nil:
+		switch cs.State.Waiting.Reason {
+		case "ImagePullBackOff", "ErrImagePull", "ErrImageNeverPull", "InvalidImageName", "CreateContainerConfigError", "CreateContainerError":
+			return &Failure{
+				Type:          FailureTypeByReason(cs.State.Waiting.Reason),
+				ContainerName: cs.Name,
+				Message:       fmt.Sprintf("%s: %s", cs.State.Waiting.Reason, cs.State.Waiting.Message),
+			}
+		case "CrashLoopBackOff":
+			failureType := CrashLoopBackOffFailure
+			if cs.LastTerminationState.Terminated != nil && cs.LastTerminationState.Terminated.Reason == "OOMKilled" {
+				failureType = OOMKilledFailure
+			}
+			return &Failure{
+				Type:          failureType,
+				ContainerName: cs.Name,
+				Message:       fmt.Sprintf("%s: %s", cs.State.Waiting.Reason, cs.State.Waiting.Message),
+			}
+		}
+	case cs.State.Terminated != nil && cs.State.Terminated.Reason == "OOMKilled":
+		return &Failure{
+			Type:          OOMKilledFailure,
+			ContainerName: cs.Name,
+			Message:       fmt.Sprintf("OOMKilled: exit code %d", cs.State.Terminated.ExitCode),
+		}
+	}
+
+	return nil
+}
+
+// getPodFailure returns the failure of the evicted, timed out or unschedulable pod, nil if pod is not failed.
+func getPodFailure(pod *corev1.Pod) *Failure {
+	switch pod.Status.Reason {
+	case "Evicted", "DeadlineExceeded":
+		return &Failure{
+			Type:    FailureTypeByReason(pod.Status.Reason),
+			Message: strings.TrimSpace(fmt.Sprintf("%s: %s", pod.Status.Reason, pod.Status.Message)),
+		}
+	}
+
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			return &Failure{
+				Type:    UnschedulableFailure,
+				Message: fmt.Sprintf("%s: %s", cond.Reason, cond.Message),
+			}
+		}
+	}
+
+	return nil
+}
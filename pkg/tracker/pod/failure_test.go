@@ -0,0 +1,73 @@
+package pod
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetContainerFailure(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		status   corev1.ContainerStatus
+		expected *Failure
+	}{
+		{
+			name:   "running container",
+			status: corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		},
+		{
+			name:   "creating container",
+			status: corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+		},
+		{
+			name:     "image pull back-off",
+			status:   corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}}},
+			expected: &Failure{Type: ImagePullFailure, ContainerName: "app", Message: "ImagePullBackOff: Back-off pulling image"},
+		},
+		{
+			name:     "invalid image name",
+			status:   corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "InvalidImageName", Message: "couldn't parse image reference"}}},
+			expected: &Failure{Type: ImagePullFailure, ContainerName: "app", Message: "InvalidImageName: couldn't parse image reference"},
+		},
+		{
+			name:     "missing config map",
+			status:   corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError", Message: `configmap "app" not found`}}},
+			expected: &Failure{Type: CreateContainerConfigFailure, ContainerName: "app", Message: `CreateContainerConfigError: configmap "app" not found`},
+		},
+		{
+			name: "crash loop back-off",
+			status: corev1.ContainerStatus{
+				Name:                 "app",
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 10s"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			},
+			expected: &Failure{Type: CrashLoopBackOffFailure, ContainerName: "app", Message: "CrashLoopBackOff: back-off 10s"},
+		},
+		{
+			name: "crash loop back-off after OOM kill",
+			status: corev1.ContainerStatus{
+				Name:                 "app",
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 10s"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			},
+			expected: &Failure{Type: OOMKilledFailure, ContainerName: "app", Message: "CrashLoopBackOff: back-off 10s"},
+		},
+		{
+			name:     "OOM killed container",
+			status:   corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}}},
+			expected: &Failure{Type: OOMKilledFailure, ContainerName: "app", Message: "OOMKilled: exit code 137"},
+		},
+		{
+			name:   "completed container",
+			status: corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if failure := getContainerFailure(tc.status); !reflect.DeepEqual(failure, tc.expected) {
+				t.Errorf("expected failure %+v, got %+v", tc.expected, failure)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ type Feed interface {
 	OnReady(func() error)
 
 	OnEventMsg(func(msg string) error)
+	OnServiceMsg(func(msg string) error)
 	OnContainerLogChunk(func(*ContainerLogChunk) error)
 	OnContainerError(func(ContainerError) error)
 	OnStatus(func(PodStatus) error)
@@ -36,6 +37,7 @@ type feed struct {
 	OnSucceededFunc         func() error
 	OnFailedFunc            func(string) error
 	OnEventMsgFunc          func(string) error
+	OnServiceMsgFunc        func(string) error
 	OnReadyFunc             func() error
 	OnContainerLogChunkFunc func(*ContainerLogChunk) error
 	OnContainerErrorFunc    func(ContainerError) error
@@ -61,6 +63,10 @@ func (f *feed) OnEventMsg(function func(string) error) {
 	f.OnEventMsgFunc = function
 }
 
+func (f *feed) OnServiceMsg(function func(string) error) {
+	f.OnServiceMsgFunc = function
+}
+
 func (f *feed) OnReady(function func() error) {
 	f.OnReadyFunc = function
 }
@@ -89,7 +95,9 @@ func (f *feed) Track(name, namespace string, kube kubernetes.Interface, opts tra
 	defer cancel()
 
 	pod := NewTracker(name, namespace, kube, Options{
-		opts.IgnoreReadinessProbeFailsByContainerName,
+		IgnoreReadinessProbeFailsByContainerName: opts.IgnoreReadinessProbeFailsByContainerName,
+		Informers:                                opts.Informers,
+		WatchRetryBudget:                         opts.WatchRetryBudget,
 	})
 
 	go func() {
@@ -188,6 +196,17 @@ func (f *feed) Track(name, namespace string, kube kubernetes.Interface, opts tra
 				}
 			}
 
+		case msg := <-pod.ServiceMsg:
+			if f.OnServiceMsgFunc != nil {
+				err := f.OnServiceMsgFunc(msg)
+				if err == tracker.StopTrack {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+
 		case status := <-pod.Ready:
 			f.setStatus(status)
 
@@ -9,10 +9,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/tracker/debug"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 	"github.com/werf/kubedog/pkg/utils"
 )
 
@@ -30,6 +30,9 @@ func NewPodsInformer(trk *tracker.Tracker, controller utils.ControllerMetadata)
 			Kube:             trk.Kube,
 			Namespace:        trk.Namespace,
 			FullResourceName: trk.FullResourceName,
+			Informers:        trk.Informers,
+			WatchRetryBudget: trk.WatchRetryBudget,
+			ServiceMsg:       trk.ServiceMsg,
 		},
 		Controller: controller,
 		PodAdded:   make(chan *corev1.Pod, 1),
@@ -60,17 +63,17 @@ func (p *PodsInformer) Run(ctx context.Context) {
 		options.LabelSelector = selector.String()
 		return options
 	}
-	lw := &cache.ListWatch{
+	lw := p.Informers.ListWatch(ctx, informer.Pods, p.Namespace, tweakListOptions, &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return client.CoreV1().Pods(p.Namespace).List(ctx, tweakListOptions(options))
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return client.CoreV1().Pods(p.Namespace).Watch(ctx, tweakListOptions(options))
 		},
-	}
+	})
 
 	go func() {
-		_, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, func(e watch.Event) (bool, error) {
+		_, err := p.UntilWithSync(ctx, lw, &corev1.Pod{}, func(e watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("    %s pod event: %#v\n", p.FullResourceName, e.Type)
 			}
@@ -0,0 +1,63 @@
+package pod
+
+import (
+	"time"
+
+	"github.com/werf/kubedog/pkg/display"
+)
+
+const (
+	logsReconnectInitialDelay = time.Second
+	logsReconnectMaxDelay     = 30 * time.Second
+)
+
+// logsCursor is the position in the container logs, which is used to re-open interrupted logs stream without duplicates.
+// Re-opened stream starts from the second of the last received line (SinceTime has seconds precision),
+// so lines older than the last one are skipped as well as lines with the same timestamp, which were already received.
+type logsCursor struct {
+	lastTime      time.Time
+	lastTimeLines map[string]int
+
+	// skipLines are lines with the lastTime timestamp, which are expected to be received again by the re-opened stream
+	skipLines map[string]int
+}
+
+func newLogsCursor() *logsCursor {
+	return &logsCursor{
+		lastTimeLines: make(map[string]int),
+		skipLines:     make(map[string]int),
+	}
+}
+
+// resume should be called when the stream is re-opened.
+func (cursor *logsCursor) resume() {
+	cursor.skipLines = make(map[string]int)
+	for msg, count := range cursor.lastTimeLines {
+		cursor.skipLines[msg] = count
+	}
+}
+
+// accept moves the cursor and returns false for the line, which has been already received.
+func (cursor *logsCursor) accept(line display.LogLine) bool {
+	lineTime, err := time.Parse(time.RFC3339Nano, line.Timestamp)
+	if err != nil {
+		return true
+	}
+
+	switch {
+	case lineTime.Before(cursor.lastTime):
+		return false
+	case lineTime.Equal(cursor.lastTime):
+		if cursor.skipLines[line.Message] > 0 {
+			cursor.skipLines[line.Message]--
+			return false
+		}
+		cursor.lastTimeLines[line.Message]++
+	default:
+		cursor.lastTime = lineTime
+		cursor.lastTimeLines = map[string]int{line.Message: 1}
+		cursor.skipLines = make(map[string]int)
+	}
+
+	return true
+}
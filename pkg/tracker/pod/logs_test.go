@@ -0,0 +1,92 @@
+package pod
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/werf/kubedog/pkg/display"
+)
+
+func TestLogsCursorAccept(t *testing.T) {
+	const (
+		t1 = "2021-03-01T12:00:01.000000001Z"
+		t2 = "2021-03-01T12:00:02.000000001Z"
+		t3 = "2021-03-01T12:00:03.000000001Z"
+	)
+
+	for _, tc := range []struct {
+		name string
+		// streams are received one by one, the cursor is resumed before every stream except the first one
+		streams  [][]display.LogLine
+		expected []string
+	}{
+		{
+			name: "single stream",
+			streams: [][]display.LogLine{
+				{{Timestamp: t1, Message: "a"}, {Timestamp: t1, Message: "a"}, {Timestamp: t2, Message: "b"}},
+			},
+			expected: []string{"a", "a", "b"},
+		},
+		{
+			name: "re-opened stream repeats lines of the last second",
+			streams: [][]display.LogLine{
+				{{Timestamp: t1, Message: "a"}, {Timestamp: t2, Message: "b"}, {Timestamp: t2, Message: "c"}},
+				{{Timestamp: t2, Message: "b"}, {Timestamp: t2, Message: "c"}, {Timestamp: t3, Message: "d"}},
+			},
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name: "re-opened stream repeats older lines",
+			streams: [][]display.LogLine{
+				{{Timestamp: t1, Message: "a"}, {Timestamp: t2, Message: "b"}},
+				{{Timestamp: t1, Message: "a"}, {Timestamp: t2, Message: "b"}, {Timestamp: t3, Message: "c"}},
+			},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "new lines with the timestamp of the last received line",
+			streams: [][]display.LogLine{
+				{{Timestamp: t1, Message: "a"}, {Timestamp: t1, Message: "a"}},
+				{{Timestamp: t1, Message: "a"}, {Timestamp: t1, Message: "a"}, {Timestamp: t1, Message: "a"}, {Timestamp: t1, Message: "b"}},
+			},
+			expected: []string{"a", "a", "a", "b"},
+		},
+		{
+			name: "stream re-opened twice",
+			streams: [][]display.LogLine{
+				{{Timestamp: t1, Message: "a"}},
+				{{Timestamp: t1, Message: "a"}, {Timestamp: t1, Message: "b"}},
+				{{Timestamp: t1, Message: "a"}, {Timestamp: t1, Message: "b"}, {Timestamp: t2, Message: "c"}},
+			},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "lines without timestamp are always accepted",
+			streams: [][]display.LogLine{
+				{{Timestamp: t2, Message: "a"}, {Message: "b"}},
+				{{Message: "b"}, {Timestamp: t1, Message: "c"}},
+			},
+			expected: []string{"a", "b", "b"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cursor := newLogsCursor()
+
+			var accepted []string
+			for i, stream := range tc.streams {
+				if i > 0 {
+					cursor.resume()
+				}
+				for _, line := range stream {
+					if cursor.accept(line) {
+						accepted = append(accepted, line.Message)
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(accepted, tc.expected) {
+				t.Errorf("expected lines %v, got %v", tc.expected, accepted)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/werf/kubedog/pkg/display"
+	"github.com/werf/kubedog/pkg/tracker/debug"
+)
+
+// previousInstanceLogsTailLines limits the number of the terminated instance log lines shown on container restart.
+const previousInstanceLogsTailLines = 100
+
+// ContainerPreviousInstance is the terminated instance of the restarted container with its last log lines.
+type ContainerPreviousInstance struct {
+	RestartCount int32
+	ExitCode     int32
+	Signal       int32
+	Reason       string
+	Message      string
+	LogLines     []display.LogLine
+}
+
+// Describe returns the termination state of the instance, e.g. "exit code 137, reason OOMKilled".
+func (instance *ContainerPreviousInstance) Describe() string {
+	parts := []string{fmt.Sprintf("exit code %d", instance.ExitCode)}
+	if instance.Reason != "" {
+		parts = append(parts, fmt.Sprintf("reason %s", instance.Reason))
+	}
+	if instance.Signal != 0 {
+		parts = append(parts, fmt.Sprintf("signal %d", instance.Signal))
+	}
+	if instance.Message != "" {
+		parts = append(parts, fmt.Sprintf("message %q", instance.Message))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// previousInstanceLogs is the result of fetching logs of the terminated instance of the restarted container.
+type previousInstanceLogs struct {
+	ContainerName string
+	Instance      *ContainerPreviousInstance
+	Err           error
+}
+
+// handleContainersRestarts starts fetching of the terminated instance logs for every container which restarts count has increased.
+// Logs are fetched in background so that the pod tracker loop is not blocked, see handlePreviousInstanceLogs.
+func (pod *Tracker) handleContainersRestarts(ctx context.Context, object *corev1.Pod) {
+	allContainerStatuses := make([]corev1.ContainerStatus, 0)
+	allContainerStatuses = append(allContainerStatuses, object.Status.InitContainerStatuses...)
+	allContainerStatuses = append(allContainerStatuses, object.Status.ContainerStatuses...)
+
+	for _, cs := range allContainerStatuses {
+		restarts, hasKey := pod.containersRestarts[cs.Name]
+		pod.containersRestarts[cs.Name] = cs.RestartCount
+
+		// Restarts happened before tracking has started are not reported
+		if !hasKey || cs.RestartCount <= restarts {
+			continue
+		}
+
+		instance := &ContainerPreviousInstance{RestartCount: cs.RestartCount}
+		if terminated := cs.LastTerminationState.Terminated; terminated != nil {
+			instance.ExitCode = terminated.ExitCode
+			instance.Signal = terminated.Signal
+			instance.Reason = terminated.Reason
+			instance.Message = strings.TrimSpace(terminated.Message)
+		}
+
+		go pod.fetchPreviousInstanceLogs(ctx, cs.Name, instance)
+	}
+}
+
+func (pod *Tracker) fetchPreviousInstanceLogs(ctx context.Context, containerName string, instance *ContainerPreviousInstance) {
+	logLines, err := pod.getPreviousInstanceLogs(ctx, containerName)
+	instance.LogLines = logLines
+
+	select {
+	case pod.previousInstanceLogsFetched <- previousInstanceLogs{ContainerName: containerName, Instance: instance, Err: err}:
+	case <-ctx.Done():
+	}
+}
+
+// handlePreviousInstanceLogs sends captured logs to ContainerLogChunk as the delimited block, the instance is attached
+// to the container errors reported afterwards.
+func (pod *Tracker) handlePreviousInstanceLogs(ctx context.Context, res previousInstanceLogs) {
+	// Pod has been deleted or the later restart has been already reported
+	if _, hasKey := pod.containersRestarts[res.ContainerName]; !hasKey {
+		return
+	}
+	if prev := pod.containersPreviousInstance[res.ContainerName]; prev != nil && prev.RestartCount >= res.Instance.RestartCount {
+		return
+	}
+
+	instance := res.Instance
+	if res.Err != nil {
+		if debug.Debug() {
+			fmt.Printf("pod/%s container/%s previous instance logs error: %s\n", pod.ResourceName, res.ContainerName, res.Err)
+		}
+		pod.sendServiceMsg(ctx, fmt.Sprintf("container/%s restarted (%s), unable to get previous instance logs: %s", res.ContainerName, instance.Describe(), res.Err))
+	}
+
+	pod.containersPreviousInstance[res.ContainerName] = instance
+
+	blockLines := []display.LogLine{{Message: fmt.Sprintf("--- container/%s restart #%d: previous instance terminated with %s ---", res.ContainerName, instance.RestartCount, instance.Describe())}}
+	if len(instance.LogLines) == 0 {
+		blockLines = append(blockLines, display.LogLine{Message: "(no logs)"})
+	}
+	blockLines = append(blockLines, instance.LogLines...)
+	blockLines = append(blockLines, display.LogLine{Message: fmt.Sprintf("--- end of container/%s previous instance logs ---", res.ContainerName)})
+
+	pod.ContainerLogChunk <- &ContainerLogChunk{
+		ContainerName:    res.ContainerName,
+		LogLines:         blockLines,
+		PreviousInstance: instance,
+	}
+}
+
+func (pod *Tracker) getPreviousInstanceLogs(ctx context.Context, containerName string) ([]display.LogLine, error) {
+	tailLines := int64(previousInstanceLogsTailLines)
+	logOpts := &corev1.PodLogOptions{
+		Container:  containerName,
+		Previous:   true,
+		Timestamps: true,
+		TailLines:  &tailLines,
+	}
+
+	data, err := pod.Kube.CoreV1().Pods(pod.Namespace).GetLogs(pod.ResourceName, logOpts).DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	logLines := make([]display.LogLine, 0)
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		lineParts := strings.SplitN(line, " ", 2)
+		if len(lineParts) == 2 {
+			logLines = append(logLines, display.LogLine{Timestamp: lineParts[0], Message: lineParts[1]})
+		}
+	}
+
+	return logLines, nil
+}
@@ -0,0 +1,400 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/werf/kubedog/pkg/tracker/informer"
+)
+
+// schedulingDiagnosisMaxNodes limits the number of nodes described in the diagnosis summary.
+const schedulingDiagnosisMaxNodes = 5
+
+// SchedulingDiagnosis explains why the pod cannot be scheduled. It is derived from the scheduler message,
+// the pod spec, nodes and pods running on nodes.
+type SchedulingDiagnosis struct {
+	// SchedulerMessage is the message of the PodScheduled condition, e.g. "0/3 nodes are available: 3 Insufficient cpu."
+	SchedulerMessage string
+	// VolumeClaims are problems of the persistent volume claims used by the pod, e.g. "pvc/data is Pending".
+	VolumeClaims []string
+	// Nodes are the nodes which do not fit the pod with the reasons.
+	Nodes []NodeFit
+	// NodesCount is the number of nodes in the cluster.
+	NodesCount int
+	// Errors are problems of the diagnosis itself, e.g. nodes cannot be listed because of RBAC restrictions.
+	Errors []string
+}
+
+// NodeFit lists the reasons why the pod does not fit the node.
+type NodeFit struct {
+	NodeName string
+	Reasons  []string
+}
+
+// Summary returns the single line description of the diagnosis.
+func (diagnosis *SchedulingDiagnosis) Summary() string {
+	var parts []string
+	parts = append(parts, diagnosis.VolumeClaims...)
+
+	if diagnosis.NodesCount > 0 {
+		var nodes []string
+		for i, node := range diagnosis.Nodes {
+			if i == schedulingDiagnosisMaxNodes {
+				nodes = append(nodes, fmt.Sprintf("%d more nodes", len(diagnosis.Nodes)-schedulingDiagnosisMaxNodes))
+				break
+			}
+			nodes = append(nodes, fmt.Sprintf("%s: %s", node.NodeName, strings.Join(node.Reasons, ", ")))
+		}
+
+		msg := fmt.Sprintf("%d/%d nodes fit", diagnosis.NodesCount-len(diagnosis.Nodes), diagnosis.NodesCount)
+		if len(nodes) > 0 {
+			msg = fmt.Sprintf("%s (%s)", msg, strings.Join(nodes, "; "))
+		}
+		parts = append(parts, msg)
+	}
+
+	if len(parts) == 0 {
+		return diagnosis.SchedulerMessage
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DiagnoseScheduling explains why the pod cannot be scheduled: checks the pod persistent volume claims and
+// whether the pod fits every node by cordon, taints, node selector, required node affinity and free resources.
+// Nodes, pods of all namespaces and claims are served from the shared informers cache when informers are set,
+// otherwise they are requested. Diagnosis requires list and watch of nodes and pods in all namespaces and of claims
+// in the pod namespace, problems of the diagnosis, e.g. forbidden nodes list, are reported in the Errors field.
+func DiagnoseScheduling(ctx context.Context, kube kubernetes.Interface, informers *informer.Factory, pod *corev1.Pod, schedulerMessage string) *SchedulingDiagnosis {
+	diagnosis := &SchedulingDiagnosis{SchedulerMessage: schedulerMessage}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		claimName := volume.PersistentVolumeClaim.ClaimName
+		pvc, err := getPersistentVolumeClaim(ctx, kube, informers, pod.Namespace, claimName)
+		switch {
+		case apierrors.IsNotFound(err):
+			diagnosis.VolumeClaims = append(diagnosis.VolumeClaims, fmt.Sprintf("pvc/%s not found", claimName))
+		case err != nil:
+			diagnosis.Errors = append(diagnosis.Errors, fmt.Sprintf("unable to get pvc/%s: %s", claimName, err))
+		case pvc.Status.Phase != corev1.ClaimBound:
+			msg := fmt.Sprintf("pvc/%s is %s", claimName, pvc.Status.Phase)
+			if pvc.Spec.StorageClassName != nil {
+				msg = fmt.Sprintf("%s (storage class %s)", msg, *pvc.Spec.StorageClassName)
+			}
+			diagnosis.VolumeClaims = append(diagnosis.VolumeClaims, msg)
+		}
+	}
+
+	nodes, err := listNodes(ctx, kube, informers)
+	if err != nil {
+		diagnosis.Errors = append(diagnosis.Errors, fmt.Sprintf("unable to list nodes: %s", err))
+		return diagnosis
+	}
+	diagnosis.NodesCount = len(nodes)
+
+	// Resources requested on nodes are unknown without pods, other checks are still made
+	requestedByNode, err := getRequestedResourcesByNode(ctx, kube, informers)
+	if err != nil {
+		diagnosis.Errors = append(diagnosis.Errors, fmt.Sprintf("unable to list pods of nodes: %s", err))
+	}
+
+	podRequests := getPodRequests(pod)
+
+	for _, node := range nodes {
+		var reasons []string
+		reasons = append(reasons, getNodeSelectionMismatch(pod, node)...)
+		if requestedByNode != nil {
+			reasons = append(reasons, getNodeResourcesShortage(podRequests, node, requestedByNode[node.Name])...)
+		}
+
+		if len(reasons) > 0 {
+			diagnosis.Nodes = append(diagnosis.Nodes, NodeFit{NodeName: node.Name, Reasons: reasons})
+		}
+	}
+
+	sort.Slice(diagnosis.Nodes, func(i, j int) bool {
+		return diagnosis.Nodes[i].NodeName < diagnosis.Nodes[j].NodeName
+	})
+
+	return diagnosis
+}
+
+func getPersistentVolumeClaim(ctx context.Context, kube kubernetes.Interface, informers *informer.Factory, namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	if informers == nil {
+		return kube.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+
+	objects, err := informers.List(ctx, informer.PersistentVolumeClaims, namespace, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		if pvc, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+			return pvc, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(corev1.Resource("persistentvolumeclaims"), name)
+}
+
+func listNodes(ctx context.Context, kube kubernetes.Interface, informers *informer.Factory) ([]*corev1.Node, error) {
+	var nodes []*corev1.Node
+
+	if informers == nil {
+		nodeList, err := kube.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range nodeList.Items {
+			nodes = append(nodes, &nodeList.Items[i])
+		}
+		return nodes, nil
+	}
+
+	objects, err := informers.List(ctx, informer.Nodes, metav1.NamespaceAll, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		if node, ok := obj.(*corev1.Node); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// nodeRequests are resources requested by the pods of the node.
+type nodeRequests struct {
+	Resources corev1.ResourceList
+	Pods      int64
+}
+
+// getRequestedResourcesByNode sums requests of the pods running on every node. Pods of all namespaces are served
+// from the shared informer cache when informers are set, so the API server is not requested for every diagnosis.
+func getRequestedResourcesByNode(ctx context.Context, kube kubernetes.Interface, informers *informer.Factory) (map[string]*nodeRequests, error) {
+	var pods []*corev1.Pod
+
+	if informers == nil {
+		fieldSelector := fields.AndSelectors(
+			fields.OneTermNotEqualSelector("spec.nodeName", ""),
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+		)
+
+		podList, err := kube.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: fieldSelector.String()})
+		if err != nil {
+			return nil, err
+		}
+		for i := range podList.Items {
+			pods = append(pods, &podList.Items[i])
+		}
+	} else {
+		// Shared informer filters by metadata fields only, the rest of the selector is applied here
+		objects, err := informers.List(ctx, informer.Pods, metav1.NamespaceAll, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			nodePod, ok := obj.(*corev1.Pod)
+			if !ok || nodePod.Spec.NodeName == "" || nodePod.Status.Phase == corev1.PodSucceeded || nodePod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			pods = append(pods, nodePod)
+		}
+	}
+
+	res := make(map[string]*nodeRequests)
+	for _, nodePod := range pods {
+		requests, hasKey := res[nodePod.Spec.NodeName]
+		if !hasKey {
+			requests = &nodeRequests{Resources: corev1.ResourceList{}}
+			res[nodePod.Spec.NodeName] = requests
+		}
+
+		requests.Pods++
+		for name, quantity := range getPodRequests(nodePod) {
+			sum := requests.Resources[name]
+			sum.Add(quantity)
+			requests.Resources[name] = sum
+		}
+	}
+
+	return res, nil
+}
+
+// getPodRequests returns the resources requested by the pod the same way as the scheduler counts them: the sum of
+// the containers requests or the maximum of the init containers requests, whichever is greater, plus pod overhead.
+func getPodRequests(pod *corev1.Pod) corev1.ResourceList {
+	res := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			sum := res[name]
+			sum.Add(quantity)
+			res[name] = sum
+		}
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, hasKey := res[name]; !hasKey || quantity.Cmp(current) > 0 {
+				res[name] = quantity.DeepCopy()
+			}
+		}
+	}
+
+	for name, quantity := range pod.Spec.Overhead {
+		sum := res[name]
+		sum.Add(quantity)
+		res[name] = sum
+	}
+
+	return res
+}
+
+func getNodeResourcesShortage(podRequests corev1.ResourceList, node *corev1.Node, requested *nodeRequests) []string {
+	if requested == nil {
+		requested = &nodeRequests{Resources: corev1.ResourceList{}}
+	}
+
+	var reasons []string
+
+	if allocatablePods, hasKey := node.Status.Allocatable[corev1.ResourcePods]; hasKey && requested.Pods >= allocatablePods.Value() {
+		reasons = append(reasons, fmt.Sprintf("too many pods (%d of %d)", requested.Pods, allocatablePods.Value()))
+	}
+
+	names := make([]string, 0, len(podRequests))
+	for name := range podRequests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		resourceName := corev1.ResourceName(name)
+		request := podRequests[resourceName]
+		if request.IsZero() {
+			continue
+		}
+
+		allocatable, hasKey := node.Status.Allocatable[resourceName]
+		if !hasKey {
+			reasons = append(reasons, fmt.Sprintf("no %s", name))
+			continue
+		}
+
+		free := allocatable.DeepCopy()
+		free.Sub(requested.Resources[resourceName])
+		if free.Cmp(resource.Quantity{}) < 0 {
+			free = resource.Quantity{}
+		}
+
+		if request.Cmp(free) > 0 {
+			reasons = append(reasons, fmt.Sprintf("insufficient %s (requested %s, free %s of %s)", name, request.String(), free.String(), allocatable.String()))
+		}
+	}
+
+	return reasons
+}
+
+func getNodeSelectionMismatch(pod *corev1.Pod, node *corev1.Node) []string {
+	var reasons []string
+
+	if node.Spec.Unschedulable {
+		reasons = append(reasons, "node is cordoned")
+	}
+
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+
+		if !tolerated {
+			reasons = append(reasons, fmt.Sprintf("taint %s not tolerated", formatTaint(taint)))
+		}
+	}
+
+	if len(pod.Spec.NodeSelector) > 0 && !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		reasons = append(reasons, "node selector mismatch")
+	}
+
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !matchNodeSelectorTerms(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, node) {
+			reasons = append(reasons, "node affinity mismatch")
+		}
+	}
+
+	return reasons
+}
+
+func formatTaint(taint *corev1.Taint) string {
+	if taint.Value == "" {
+		return fmt.Sprintf("%s:%s", taint.Key, taint.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect)
+}
+
+// matchNodeSelectorTerms checks that node matches any of the terms, expressions of the term are ANDed.
+func matchNodeSelectorTerms(terms []corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		if matchNodeSelectorRequirements(term.MatchExpressions, labels.Set(node.Labels)) &&
+			matchNodeSelectorRequirements(term.MatchFields, labels.Set{"metadata.name": node.Name}) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchNodeSelectorRequirements(requirements []corev1.NodeSelectorRequirement, set labels.Set) bool {
+	for _, req := range requirements {
+		var op selection.Operator
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn:
+			op = selection.In
+		case corev1.NodeSelectorOpNotIn:
+			op = selection.NotIn
+		case corev1.NodeSelectorOpExists:
+			op = selection.Exists
+		case corev1.NodeSelectorOpDoesNotExist:
+			op = selection.DoesNotExist
+		case corev1.NodeSelectorOpGt:
+			op = selection.GreaterThan
+		case corev1.NodeSelectorOpLt:
+			op = selection.LessThan
+		default:
+			return false
+		}
+
+		requirement, err := labels.NewRequirement(req.Key, op, req.Values)
+		if err != nil || !requirement.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
@@ -26,6 +26,14 @@ type PodStatus struct {
 	FailedReason string
 
 	ContainersErrors []ContainerError
+
+	// Failures are typed failures of the pod and its containers derived from the pod status, conditions and events.
+	Failures []Failure
+
+	// SchedulingDiagnosis explains why the unschedulable pod cannot be scheduled, nil for scheduled pods.
+	SchedulingDiagnosis *SchedulingDiagnosis
+	// WaitingForMessages are conditions the pending pod is waiting for, e.g. scheduling.
+	WaitingForMessages []string
 }
 
 func NewPodStatus(pod *corev1.Pod, statusGeneration uint64, trackedContainers []string, isTrackerFailed bool, trackerFailedReason string) PodStatus {
@@ -142,23 +150,38 @@ func NewPodStatus(pod *corev1.Pod, statusGeneration uint64, trackedContainers []
 }
 
 func setContainersStatusesToPodStatus(status *PodStatus, pod *corev1.Pod) {
+	if failure := getPodFailure(pod); failure != nil {
+		status.Failures = append(status.Failures, *failure)
+	}
+
 	allContainerStatuses := make([]corev1.ContainerStatus, 0)
 	allContainerStatuses = append(allContainerStatuses, pod.Status.InitContainerStatuses...)
 	allContainerStatuses = append(allContainerStatuses, pod.Status.ContainerStatuses...)
 
 	for _, cs := range allContainerStatuses {
-		if cs.State.Waiting != nil {
-			switch cs.State.Waiting.Reason {
-			case "ImagePullBackOff", "ErrImagePull", "CrashLoopBackOff", "ErrImageNeverPull":
-				if status.ContainersErrors == nil {
-					status.ContainersErrors = []ContainerError{}
-				}
+		failure := getContainerFailure(cs)
+		if failure == nil {
+			continue
+		}
+		status.Failures = append(status.Failures, *failure)
 
-				status.ContainersErrors = append(status.ContainersErrors, ContainerError{
-					ContainerName: cs.Name,
-					Message:       fmt.Sprintf("%s: %s", cs.State.Waiting.Reason, cs.State.Waiting.Message),
-				})
+		// Terminated containers are reported by the pod status. Other waiting reasons are often transient,
+		// e.g. CreateContainerConfigError until the referenced ConfigMap is created, so they are only kept in Failures
+		if cs.State.Waiting == nil {
+			continue
+		}
+
+		switch cs.State.Waiting.Reason {
+		case "ImagePullBackOff", "ErrImagePull", "CrashLoopBackOff", "ErrImageNeverPull":
+			if status.ContainersErrors == nil {
+				status.ContainersErrors = []ContainerError{}
 			}
+
+			status.ContainersErrors = append(status.ContainersErrors, ContainerError{
+				ContainerName: failure.ContainerName,
+				Message:       failure.Message,
+				Type:          failure.Type,
+			})
 		}
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -15,22 +16,34 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/display"
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/tracker/debug"
 	"github.com/werf/kubedog/pkg/tracker/event"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 )
 
 type ContainerError struct {
 	Message       string
 	ContainerName string
+	Type          FailureType
+
+	// IsStatusFailure is set for the typed failures of the pod status, which are not container errors,
+	// e.g. Unschedulable, Evicted or FailedMount. ContainerName is empty for the failures of the pod itself.
+	IsStatusFailure bool
+
+	// PreviousInstance is the last terminated instance of the restarted container, which logs have been fetched,
+	// nil if container has not been restarted.
+	PreviousInstance *ContainerPreviousInstance
 }
 
 type ContainerLogChunk struct {
 	ContainerName string
 	LogLines      []display.LogLine
+
+	// PreviousInstance is set when the chunk is the delimited block of the terminated instance logs.
+	PreviousInstance *ContainerPreviousInstance
 }
 
 type PodLogChunk struct {
@@ -81,8 +94,21 @@ type Tracker struct {
 	readinessProbes                          map[string]*ReadinessProbe
 	ignoreReadinessProbeFailsByContainerName map[string]time.Duration
 
-	lastObject   *corev1.Pod
-	failedReason string
+	reportStatusFailures   bool
+	reportedStatusFailures map[string]bool
+
+	containersRestarts          map[string]int32
+	containersPreviousInstance  map[string]*ContainerPreviousInstance
+	previousInstanceLogsFetched chan previousInstanceLogs
+
+	lastObject    *corev1.Pod
+	lastObjectMux sync.Mutex
+	failedReason  string
+	mountFailure  *Failure
+
+	schedulingMessage   string
+	schedulingDiagnosis *SchedulingDiagnosis
+	schedulingDiagnosed chan *SchedulingDiagnosis
 
 	objectAdded    chan *corev1.Pod
 	objectModified chan *corev1.Pod
@@ -95,15 +121,21 @@ type Tracker struct {
 
 type Options struct {
 	IgnoreReadinessProbeFailsByContainerName map[string]time.Duration
+	Informers                                *informer.Factory
+	WatchRetryBudget                         int
+	ReportStatusFailures                     bool
 }
 
 func NewTracker(name, namespace string, kube kubernetes.Interface, opts Options) *Tracker {
-	return &Tracker{
+	res := &Tracker{
 		Tracker: tracker.Tracker{
 			Kube:             kube,
 			Namespace:        namespace,
 			FullResourceName: fmt.Sprintf("po/%s", name),
 			ResourceName:     name,
+			Informers:        opts.Informers,
+			WatchRetryBudget: opts.WatchRetryBudget,
+			ServiceMsg:       make(chan string, 1),
 		},
 
 		Added:     make(chan PodStatus, 1),
@@ -125,6 +157,14 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts Options)
 		readinessProbes:                          make(map[string]*ReadinessProbe),
 		ignoreReadinessProbeFailsByContainerName: opts.IgnoreReadinessProbeFailsByContainerName,
 
+		reportStatusFailures:   opts.ReportStatusFailures,
+		reportedStatusFailures: make(map[string]bool),
+
+		containersRestarts:          make(map[string]int32),
+		containersPreviousInstance:  make(map[string]*ContainerPreviousInstance),
+		previousInstanceLogsFetched: make(chan previousInstanceLogs, 10),
+		schedulingDiagnosed:         make(chan *SchedulingDiagnosis, 10),
+
 		objectAdded:    make(chan *corev1.Pod),
 		objectModified: make(chan *corev1.Pod),
 		objectDeleted:  make(chan *corev1.Pod),
@@ -132,6 +172,8 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts Options)
 		errors:         make(chan error),
 		containerDone:  make(chan string, 10),
 	}
+
+	return res
 }
 
 func (pod *Tracker) Start(ctx context.Context) error {
@@ -168,10 +210,13 @@ func (pod *Tracker) Start(ctx context.Context) error {
 			}
 
 			pod.State = tracker.ResourceDeleted
-			pod.lastObject = nil
+			pod.setLastObject(nil)
 
 			pod.ContainerTrackerStateChanges = make(map[string]chan tracker.TrackerState)
 			pod.ContainerTrackerStates = make(map[string]tracker.TrackerState)
+			pod.containersRestarts = make(map[string]int32)
+			pod.containersPreviousInstance = make(map[string]*ContainerPreviousInstance)
+			pod.reportedStatusFailures = make(map[string]bool)
 
 			status := PodStatus{}
 			pod.LastStatus = status
@@ -190,10 +235,18 @@ func (pod *Tracker) Start(ctx context.Context) error {
 				pod.handleProbeTriggeredRestart(failure)
 			case event.ReadinessProbeFailure:
 				pod.handleReadinessProbeFailure(failure)
+			case event.VolumeMountFailure:
+				pod.handleVolumeMountFailure(failure)
 			default:
 				panic(fmt.Errorf("unexpected type %T", failure))
 			}
 
+		case res := <-pod.previousInstanceLogsFetched:
+			pod.handlePreviousInstanceLogs(ctx, res)
+
+		case diagnosis := <-pod.schedulingDiagnosed:
+			pod.handleSchedulingDiagnosis(diagnosis)
+
 		case containerName := <-pod.containerDone:
 			trackedContainers := make([]string, 0)
 			for _, name := range pod.TrackedContainers {
@@ -235,7 +288,7 @@ func (pod *Tracker) handleRegularFailure(reason string) {
 	var status PodStatus
 	if pod.lastObject != nil {
 		pod.StatusGeneration++
-		status = NewPodStatus(pod.lastObject, pod.StatusGeneration, pod.TrackedContainers, pod.State == tracker.ResourceFailed, pod.failedReason)
+		status = pod.newPodStatus(pod.lastObject)
 	} else {
 		status = PodStatus{IsFailed: true, FailedReason: reason}
 	}
@@ -253,11 +306,156 @@ func (pod *Tracker) handleProbeTriggeredRestart(event event.ProbeTriggeredRestar
 		ContainerError: ContainerError{
 			ContainerName: event.ContainerName,
 			Message:       event.Message,
+			Type:          ProbeFailure,
 		},
 		PodStatus: pod.LastStatus,
 	}
 }
 
+func (pod *Tracker) handleVolumeMountFailure(event event.VolumeMountFailure) {
+	if debug.Debug() {
+		fmt.Printf("Pod %q processing VolumeMountFailure: %s\n", pod.ResourceName, event.Message)
+	}
+
+	pod.mountFailure = &Failure{Type: FailedMountFailure, Message: event.Message}
+
+	// Status of the pod with failed mount is not updated, so the failure is reported with the last known status
+	if pod.lastObject != nil {
+		pod.StatusGeneration++
+		status := pod.newPodStatus(pod.lastObject)
+		pod.LastStatus = status
+		pod.handleStatusFailures(status)
+		pod.Status <- status
+	}
+}
+
+// handleStatusFailures reports the typed failures, which are not container errors, as container errors
+// once when the failure appears.
+func (pod *Tracker) handleStatusFailures(status PodStatus) {
+	if !pod.reportStatusFailures {
+		return
+	}
+
+	isContainerError := make(map[string]bool)
+	for _, containerError := range status.ContainersErrors {
+		isContainerError[fmt.Sprintf("%s/%s", containerError.Type, containerError.ContainerName)] = true
+	}
+
+	reportedStatusFailures := make(map[string]bool)
+	for _, failure := range status.Failures {
+		key := fmt.Sprintf("%s/%s", failure.Type, failure.ContainerName)
+		if isContainerError[key] {
+			continue
+		}
+
+		reportedStatusFailures[key] = true
+		if pod.reportedStatusFailures[key] {
+			continue
+		}
+
+		if debug.Debug() {
+			fmt.Printf("pod/%s processing %s status failure: %s\n", pod.ResourceName, failure.Type, failure.Message)
+		}
+
+		pod.ContainerError <- ContainerErrorReport{
+			ContainerError: ContainerError{
+				ContainerName:   failure.ContainerName,
+				Message:         failure.Message,
+				Type:            failure.Type,
+				IsStatusFailure: true,
+			},
+			PodStatus: status,
+		}
+	}
+	pod.reportedStatusFailures = reportedStatusFailures
+}
+
+// newPodStatus makes the status of the pod with failures reported by events, which are not the part of the pod status.
+func (pod *Tracker) newPodStatus(object *corev1.Pod) PodStatus {
+	status := NewPodStatus(object, pod.StatusGeneration, pod.TrackedContainers, pod.State == tracker.ResourceFailed, pod.failedReason)
+
+	if pod.mountFailure != nil {
+		allContainerStatuses := make([]corev1.ContainerStatus, 0)
+		allContainerStatuses = append(allContainerStatuses, object.Status.InitContainerStatuses...)
+		allContainerStatuses = append(allContainerStatuses, object.Status.ContainerStatuses...)
+
+		// Volumes are mounted before containers are started
+		for _, cs := range allContainerStatuses {
+			if cs.State.Running != nil || cs.State.Terminated != nil {
+				pod.mountFailure = nil
+				break
+			}
+		}
+	}
+	if pod.mountFailure != nil {
+		status.Failures = append(status.Failures, *pod.mountFailure)
+	}
+
+	if pod.schedulingDiagnosis != nil {
+		status.SchedulingDiagnosis = pod.schedulingDiagnosis
+		status.WaitingForMessages = append(status.WaitingForMessages, fmt.Sprintf("scheduling: %s", pod.schedulingDiagnosis.Summary()))
+	}
+
+	return status
+}
+
+// updateSchedulingDiagnosis diagnoses the unschedulable pod, the diagnosis is updated when the scheduler message changes.
+// Diagnosis is made in background so that the pod tracker loop is not blocked, see handleSchedulingDiagnosis.
+func (pod *Tracker) updateSchedulingDiagnosis(ctx context.Context, object *corev1.Pod) {
+	var schedulingCond *corev1.PodCondition
+	for i := range object.Status.Conditions {
+		cond := &object.Status.Conditions[i]
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			schedulingCond = cond
+			break
+		}
+	}
+
+	if schedulingCond == nil {
+		pod.schedulingMessage = ""
+		pod.schedulingDiagnosis = nil
+		return
+	}
+
+	// Diagnosis of the same message is already made or in progress
+	if pod.schedulingMessage == schedulingCond.Message {
+		return
+	}
+	pod.schedulingMessage = schedulingCond.Message
+
+	go pod.diagnoseScheduling(ctx, object.DeepCopy(), schedulingCond.Message)
+}
+
+func (pod *Tracker) diagnoseScheduling(ctx context.Context, object *corev1.Pod, schedulerMessage string) {
+	diagnosis := DiagnoseScheduling(ctx, pod.Kube, pod.Informers, object, schedulerMessage)
+
+	select {
+	case pod.schedulingDiagnosed <- diagnosis:
+	case <-ctx.Done():
+	}
+}
+
+// handleSchedulingDiagnosis sets the diagnosis of the current scheduler message and reports the updated status.
+func (pod *Tracker) handleSchedulingDiagnosis(diagnosis *SchedulingDiagnosis) {
+	// Pod has been scheduled or the scheduler message has changed during the diagnosis
+	if pod.schedulingMessage != diagnosis.SchedulerMessage {
+		return
+	}
+
+	if debug.Debug() {
+		fmt.Printf("pod/%s scheduling diagnosis: %s, errors: %v\n", pod.ResourceName, diagnosis.Summary(), diagnosis.Errors)
+	}
+
+	pod.schedulingDiagnosis = diagnosis
+
+	if pod.lastObject != nil {
+		pod.StatusGeneration++
+		status := pod.newPodStatus(pod.lastObject)
+		pod.LastStatus = status
+		pod.Status <- status
+	}
+}
+
 func (pod *Tracker) handleReadinessProbeFailure(event event.ReadinessProbeFailure) {
 	readinessProbe, ok := pod.readinessProbes[event.ContainerName]
 	if !ok {
@@ -282,16 +480,19 @@ func (pod *Tracker) handleReadinessProbeFailure(event event.ReadinessProbeFailur
 		ContainerError: ContainerError{
 			ContainerName: event.ContainerName,
 			Message:       event.Message,
+			Type:          ProbeFailure,
 		},
 		PodStatus: pod.LastStatus,
 	}
 }
 
 func (pod *Tracker) handlePodState(ctx context.Context, object *corev1.Pod) error {
-	pod.lastObject = object
+	pod.setLastObject(object)
 	pod.StatusGeneration++
 
-	status := NewPodStatus(object, pod.StatusGeneration, pod.TrackedContainers, pod.State == tracker.ResourceFailed, pod.failedReason)
+	pod.updateSchedulingDiagnosis(ctx, object)
+
+	status := pod.newPodStatus(object)
 	pod.LastStatus = status
 
 	switch pod.State {
@@ -314,16 +515,22 @@ func (pod *Tracker) handlePodState(ctx context.Context, object *corev1.Pod) erro
 		return fmt.Errorf("unable to handle pod containers state: %s", err)
 	}
 
+	pod.handleContainersRestarts(ctx, object)
+
 	for _, containerError := range status.ContainersErrors {
 		pod.ContainerError <- ContainerErrorReport{
 			ContainerError: ContainerError{
-				ContainerName: containerError.ContainerName,
-				Message:       containerError.Message,
+				ContainerName:    containerError.ContainerName,
+				Message:          containerError.Message,
+				Type:             containerError.Type,
+				PreviousInstance: pod.containersPreviousInstance[containerError.ContainerName],
 			},
 			PodStatus: status,
 		}
 	}
 
+	pod.handleStatusFailures(status)
+
 	switch pod.State {
 	case tracker.Initial:
 		switch {
@@ -411,13 +618,83 @@ func (pod *Tracker) handleContainersState(object *corev1.Pod) error {
 	return nil
 }
 
+// followContainerLogs streams container logs until the container is terminated. Interrupted stream is re-opened
+// with backoff since the last received line, the number of consecutive failures is limited by WatchRetryBudget.
 func (pod *Tracker) followContainerLogs(ctx context.Context, containerName string) error {
+	cursor := newLogsCursor()
+
+	budget := pod.WatchRetryBudget
+	if budget == 0 {
+		budget = tracker.DefaultWatchRetryBudget
+	}
+	failures := 0
+	delay := logsReconnectInitialDelay
+
+	for {
+		received, err := pod.streamContainerLogs(ctx, containerName, cursor)
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				return nil
+			}
+			return ctx.Err()
+		default:
+		}
+
+		if received {
+			failures = 0
+			delay = logsReconnectInitialDelay
+		}
+
+		if err == nil {
+			if !pod.isContainerRunning(containerName) {
+				return nil
+			}
+			err = fmt.Errorf("stream closed while container is running")
+		}
+
+		failures++
+		if budget > 0 && failures > budget {
+			msg := fmt.Sprintf("container/%s logs stream is lost: %s, log lines are not shown", containerName, err)
+			if !cursor.lastTime.IsZero() {
+				msg = fmt.Sprintf("container/%s logs stream is lost: %s, log lines after %s are not shown", containerName, err, cursor.lastTime.Format(time.RFC3339))
+			}
+			pod.sendServiceMsg(ctx, msg)
+			return err
+		}
+
+		pod.sendServiceMsg(ctx, fmt.Sprintf("container/%s logs stream interrupted: %s, reconnecting in %s", containerName, err, delay))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				return nil
+			}
+			return ctx.Err()
+		}
+
+		delay *= 2
+		if delay > logsReconnectMaxDelay {
+			delay = logsReconnectMaxDelay
+		}
+		cursor.resume()
+	}
+}
+
+// streamContainerLogs reads container logs stream since the cursor until EOF or error, nil error is returned on EOF.
+func (pod *Tracker) streamContainerLogs(ctx context.Context, containerName string, cursor *logsCursor) (bool, error) {
 	logOpts := &corev1.PodLogOptions{
 		Container:  containerName,
 		Timestamps: true,
 		Follow:     true,
 	}
-	if !pod.LogsFromTime.IsZero() {
+	if !cursor.lastTime.IsZero() {
+		logOpts.SinceTime = &metav1.Time{
+			Time: cursor.lastTime,
+		}
+	} else if !pod.LogsFromTime.IsZero() {
 		logOpts.SinceTime = &metav1.Time{
 			Time: pod.LogsFromTime,
 		}
@@ -428,10 +705,11 @@ func (pod *Tracker) followContainerLogs(ctx context.Context, containerName strin
 
 	readCloser, err := req.Stream(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer readCloser.Close()
 
+	received := false
 	chunkBuf := make([]byte, 1024*64)
 	lineBuf := make([]byte, 0, 1024*4)
 
@@ -449,7 +727,10 @@ func (pod *Tracker) followContainerLogs(ctx context.Context, containerName strin
 
 					lineParts := strings.SplitN(line, " ", 2)
 					if len(lineParts) == 2 {
-						chunkLines = append(chunkLines, display.LogLine{Timestamp: lineParts[0], Message: lineParts[1]})
+						logLine := display.LogLine{Timestamp: lineParts[0], Message: lineParts[1]}
+						if cursor.accept(logLine) {
+							chunkLines = append(chunkLines, logLine)
+						}
 					}
 
 					continue
@@ -458,31 +739,72 @@ func (pod *Tracker) followContainerLogs(ctx context.Context, containerName strin
 				lineBuf = append(lineBuf, bt)
 			}
 
-			pod.ContainerLogChunk <- &ContainerLogChunk{
-				ContainerName: containerName,
-				LogLines:      chunkLines,
+			if len(chunkLines) > 0 {
+				received = true
+				pod.ContainerLogChunk <- &ContainerLogChunk{
+					ContainerName: containerName,
+					LogLines:      chunkLines,
+				}
 			}
 		}
 
 		if err == io.EOF {
-			break
+			return received, nil
 		}
 
 		if err != nil {
-			return err
+			return received, err
 		}
 
 		select {
 		case <-ctx.Done():
-			if ctx.Err() == context.Canceled {
-				return nil
-			}
-			return ctx.Err()
+			return received, nil
 		default:
 		}
 	}
+}
 
-	return nil
+// isContainerRunning checks the container state of the last pod object received by the informer,
+// the container of the deleted pod is not running.
+func (pod *Tracker) isContainerRunning(containerName string) bool {
+	object := pod.getLastObject()
+	if object == nil {
+		return false
+	}
+
+	// The object is shared with the informer cache and should not be modified
+	allContainerStatuses := make([]corev1.ContainerStatus, 0)
+	allContainerStatuses = append(allContainerStatuses, object.Status.InitContainerStatuses...)
+	allContainerStatuses = append(allContainerStatuses, object.Status.ContainerStatuses...)
+
+	for _, cs := range allContainerStatuses {
+		if cs.Name == containerName {
+			return cs.State.Running != nil
+		}
+	}
+	return false
+}
+
+// setLastObject sets the last pod object, which is also read by the containers trackers.
+func (pod *Tracker) setLastObject(object *corev1.Pod) {
+	pod.lastObjectMux.Lock()
+	defer pod.lastObjectMux.Unlock()
+
+	pod.lastObject = object
+}
+
+func (pod *Tracker) getLastObject() *corev1.Pod {
+	pod.lastObjectMux.Lock()
+	defer pod.lastObjectMux.Unlock()
+
+	return pod.lastObject
+}
+
+func (pod *Tracker) sendServiceMsg(ctx context.Context, msg string) {
+	select {
+	case pod.ServiceMsg <- msg:
+	case <-ctx.Done():
+	}
 }
 
 func (pod *Tracker) trackContainer(ctx context.Context, containerName string, containerTrackerStateChanges chan tracker.TrackerState) error {
@@ -565,17 +887,17 @@ func (pod *Tracker) runInformer(ctx context.Context) error {
 		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", pod.ResourceName).String()
 		return options
 	}
-	lw := &cache.ListWatch{
+	lw := pod.Informers.ListWatch(ctx, informer.Pods, pod.Namespace, tweakListOptions, &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return pod.Kube.CoreV1().Pods(pod.Namespace).List(ctx, tweakListOptions(options))
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return pod.Kube.CoreV1().Pods(pod.Namespace).Watch(ctx, tweakListOptions(options))
 		},
-	}
+	})
 
 	go func() {
-		_, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, func(e watch.Event) (bool, error) {
+		_, err := pod.UntilWithSync(ctx, lw, &corev1.Pod{}, func(e watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("Pod `%s` informer event: %#v\n", pod.ResourceName, e.Type)
 			}
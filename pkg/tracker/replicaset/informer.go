@@ -9,10 +9,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/tracker/debug"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 	"github.com/werf/kubedog/pkg/tracker/pod"
 	"github.com/werf/kubedog/pkg/utils"
 )
@@ -57,6 +57,9 @@ func NewReplicaSetInformer(trk *tracker.Tracker, controller utils.ControllerMeta
 			Kube:             trk.Kube,
 			Namespace:        trk.Namespace,
 			FullResourceName: trk.FullResourceName,
+			Informers:        trk.Informers,
+			WatchRetryBudget: trk.WatchRetryBudget,
+			ServiceMsg:       trk.ServiceMsg,
 		},
 		Controller:         controller,
 		ReplicaSetAdded:    make(chan *appsv1.ReplicaSet, 1),
@@ -90,17 +93,17 @@ func (r *ReplicaSetInformer) Run(ctx context.Context) {
 		options.LabelSelector = selector.String()
 		return options
 	}
-	lw := &cache.ListWatch{
+	lw := r.Informers.ListWatch(ctx, informer.ReplicaSets, r.Namespace, tweakListOptions, &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return client.AppsV1().ReplicaSets(r.Namespace).List(ctx, tweakListOptions(options))
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return client.AppsV1().ReplicaSets(r.Namespace).Watch(ctx, tweakListOptions(options))
 		},
-	}
+	})
 
 	go func() {
-		_, err := watchtools.UntilWithSync(ctx, lw, &appsv1.ReplicaSet{}, nil, func(e watch.Event) (bool, error) {
+		_, err := r.UntilWithSync(ctx, lw, &appsv1.ReplicaSet{}, func(e watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("    %s replica set event: %#v\n", r.FullResourceName, e.Type)
 			}
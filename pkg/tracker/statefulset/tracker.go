@@ -15,11 +15,11 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
-	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/werf/kubedog/pkg/tracker"
 	"github.com/werf/kubedog/pkg/tracker/debug"
 	"github.com/werf/kubedog/pkg/tracker/event"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 	"github.com/werf/kubedog/pkg/tracker/pod"
 	"github.com/werf/kubedog/pkg/tracker/replicaset"
 	"github.com/werf/kubedog/pkg/utils"
@@ -47,6 +47,7 @@ type Tracker struct {
 	podRevisions map[string]string
 
 	ignoreReadinessProbeFailsByContainerName map[string]time.Duration
+	reportStatusFailures                     bool
 
 	TrackedPodsNames []string
 
@@ -77,13 +78,16 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 	if debug.Debug() {
 		fmt.Printf("> statefulset.NewTracker\n")
 	}
-	return &Tracker{
+	res := &Tracker{
 		Tracker: tracker.Tracker{
 			Kube:             kube,
 			Namespace:        namespace,
 			FullResourceName: fmt.Sprintf("sts/%s", name),
 			ResourceName:     name,
 			LogsFromTime:     opts.LogsFromTime,
+			Informers:        opts.Informers,
+			WatchRetryBudget: opts.WatchRetryBudget,
+			ServiceMsg:       make(chan string, 1),
 		},
 
 		Added:  make(chan StatefulSetStatus, 1),
@@ -97,6 +101,7 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 		PodError:    make(chan PodErrorReport),
 
 		ignoreReadinessProbeFailsByContainerName: opts.IgnoreReadinessProbeFailsByContainerName,
+		reportStatusFailures:                     opts.ReportStatusFailures,
 
 		podStatuses:  make(map[string]pod.PodStatus),
 		podRevisions: make(map[string]string),
@@ -113,6 +118,8 @@ func NewTracker(name, namespace string, kube kubernetes.Interface, opts tracker.
 		podContainerErrorsRelay: make(chan map[string]pod.ContainerErrorReport, 10),
 		donePodsRelay:           make(chan map[string]pod.PodStatus, 10),
 	}
+
+	return res
 }
 
 // Track starts tracking of StatefulSet rollout process.
@@ -283,17 +290,17 @@ func (d *Tracker) runStatefulSetInformer(ctx context.Context) {
 		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", d.ResourceName).String()
 		return options
 	}
-	lw := &cache.ListWatch{
+	lw := d.Informers.ListWatch(ctx, informer.StatefulSets, d.Namespace, tweakListOptions, &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			return client.AppsV1().StatefulSets(d.Namespace).List(ctx, tweakListOptions(options))
 		},
 		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 			return client.AppsV1().StatefulSets(d.Namespace).Watch(ctx, tweakListOptions(options))
 		},
-	}
+	})
 
 	go func() {
-		_, err := watchtools.UntilWithSync(ctx, lw, &appsv1.StatefulSet{}, nil, func(e watch.Event) (bool, error) {
+		_, err := d.UntilWithSync(ctx, lw, &appsv1.StatefulSet{}, func(e watch.Event) (bool, error) {
 			if debug.Debug() {
 				fmt.Printf("    statefulset/%s event: %#v\n", d.ResourceName, e.Type)
 			}
@@ -348,6 +355,9 @@ func (d *Tracker) runPodTracker(_ctx context.Context, podName string) error {
 	newCtx, cancelPodCtx := context.WithCancel(_ctx)
 	podTracker := pod.NewTracker(podName, d.Namespace, d.Kube, pod.Options{
 		IgnoreReadinessProbeFailsByContainerName: d.ignoreReadinessProbeFailsByContainerName,
+		Informers:                                d.Informers,
+		WatchRetryBudget:                         d.WatchRetryBudget,
+		ReportStatusFailures:                     d.reportStatusFailures,
 	})
 	if !d.LogsFromTime.IsZero() {
 		podTracker.LogsFromTime = d.LogsFromTime
@@ -391,6 +401,8 @@ func (d *Tracker) runPodTracker(_ctx context.Context, podName string) error {
 
 			case msg := <-podTracker.EventMsg:
 				d.EventMsg <- fmt.Sprintf("po/%s %s", podTracker.ResourceName, msg)
+			case msg := <-podTracker.ServiceMsg:
+				d.ServiceMsg <- fmt.Sprintf("po/%s %s", podTracker.ResourceName, msg)
 			case chunk := <-podTracker.ContainerLogChunk:
 				d.podLogChunksRelay <- map[string]*pod.ContainerLogChunk{podTracker.ResourceName: chunk}
 			case report := <-podTracker.ContainerError:
@@ -8,6 +8,8 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+
+	"github.com/werf/kubedog/pkg/tracker/informer"
 )
 
 var StopTrack = errors.New("stop tracking now")
@@ -33,6 +35,16 @@ type Tracker struct {
 	FullResourceName string // full resource name with resource kind (deploy/superapp)
 	LogsFromTime     time.Time
 
+	// Informers are shared between trackers, tracker lists and watches resources by itself when not set.
+	Informers *informer.Factory
+
+	// WatchRetryBudget is the number of consecutive failed list and watch requests, after which tracking fails.
+	// DefaultWatchRetryBudget is used when not set, negative value means unlimited retries.
+	WatchRetryBudget int
+	// ServiceMsg receives messages of the tracker itself, which are not events of the resource,
+	// e.g. about retried list and watch requests.
+	ServiceMsg chan string
+
 	StatusGeneration uint64
 }
 
@@ -41,6 +53,12 @@ type Options struct {
 	Timeout                                  time.Duration
 	LogsFromTime                             time.Time
 	IgnoreReadinessProbeFailsByContainerName map[string]time.Duration
+	Informers                                *informer.Factory
+	WatchRetryBudget                         int
+
+	// ReportStatusFailures enables reporting of the typed pod failures, which are not container errors,
+	// e.g. Unschedulable, Evicted or FailedMount, as pod errors.
+	ReportStatusFailures bool
 }
 
 type ResourceError struct {
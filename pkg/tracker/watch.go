@@ -0,0 +1,164 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+
+	"github.com/werf/kubedog/pkg/tracker/debug"
+	"github.com/werf/kubedog/pkg/tracker/informer"
+)
+
+// DefaultWatchRetryBudget is the number of consecutive failed list and watch requests, after which tracking fails.
+const DefaultWatchRetryBudget = 10
+
+// UntilWithSync lists and watches resources with lw and calls conditions for every change the same way as
+// watchtools.UntilWithSync does. Closed and expired watches are resumed by re-list, failed list and watch requests are
+// retried with the exponential backoff of the reflector. Every retry is reported to ServiceMsg channel,
+// tracking fails when the number of consecutive failures exceeds WatchRetryBudget.
+func (t *Tracker) UntilWithSync(ctx context.Context, lw cache.ListerWatcher, objType runtime.Object, conditions ...watchtools.ConditionFunc) (*watch.Event, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	retryLw := &retryListWatch{
+		ListerWatcher: lw,
+		ctx:           watchCtx,
+		cancel:        cancel,
+		name:          t.FullResourceName,
+		budget:        t.WatchRetryBudget,
+		messages:      t.ServiceMsg,
+	}
+	if retryLw.budget == 0 {
+		retryLw.budget = DefaultWatchRetryBudget
+	}
+	// Watches of the shared informers cache are reconnected by the informers
+	if !informer.IsShared(lw) {
+		retryLw.onReconnect = func() { t.Informers.HandleWatchReconnect(t.FullResourceName) }
+	}
+
+	watcher, done := informer.NewInformerWatcher(watchCtx, retryLw, objType)
+	defer func() { <-done }()
+	defer watcher.Stop()
+
+	event, err := watchtools.UntilWithoutRetry(watchCtx, watcher, conditions...)
+	if budgetErr := retryLw.getError(); budgetErr != nil {
+		return nil, budgetErr
+	}
+	return event, err
+}
+
+// retryListWatch counts consecutive failures of list and watch requests, the reflector retries failed requests.
+type retryListWatch struct {
+	cache.ListerWatcher
+
+	ctx         context.Context
+	cancel      context.CancelFunc
+	name        string
+	budget      int
+	messages    chan string
+	onReconnect func()
+
+	mux      sync.Mutex
+	failures int
+	watches  int
+	err      error
+}
+
+func (lw *retryListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	list, err := lw.ListerWatcher.List(options)
+	if err != nil {
+		lw.handleFailure("list", err)
+		return nil, err
+	}
+
+	lw.resetFailures()
+	return list, nil
+}
+
+func (lw *retryListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	lw.mux.Lock()
+	lw.watches++
+	isReconnect := lw.watches > 1
+	lw.mux.Unlock()
+
+	if isReconnect && lw.onReconnect != nil {
+		lw.onReconnect()
+	}
+
+	w, err := lw.ListerWatcher.Watch(options)
+	if err != nil {
+		lw.handleFailure("watch", err)
+		return nil, err
+	}
+
+	return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+		if e.Type != watch.Error {
+			lw.resetFailures()
+			return e, true
+		}
+
+		// Expired resource version is the regular case after etcd compaction, the reflector re-lists resources
+		if err := apierrors.FromObject(e.Object); !apierrors.IsResourceExpired(err) && !apierrors.IsGone(err) {
+			lw.handleFailure("watch", err)
+		}
+		return e, true
+	}), nil
+}
+
+func (lw *retryListWatch) resetFailures() {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+
+	lw.failures = 0
+}
+
+func (lw *retryListWatch) handleFailure(request string, err error) {
+	// Requests are failed on cancel when tracking is done
+	if lw.ctx.Err() != nil {
+		return
+	}
+
+	lw.mux.Lock()
+	lw.failures++
+	failures := lw.failures
+
+	if lw.budget > 0 && failures > lw.budget {
+		if lw.err == nil {
+			lw.err = fmt.Errorf("%s %s failed %d times in a row: %s", lw.name, request, failures, err)
+			lw.cancel()
+		}
+		lw.mux.Unlock()
+		return
+	}
+	lw.mux.Unlock()
+
+	msg := fmt.Sprintf("%s failed: %s, retrying (%d/%d)", request, err, failures, lw.budget)
+	if lw.budget < 0 {
+		msg = fmt.Sprintf("%s failed: %s, retrying (%d)", request, err, failures)
+	}
+
+	if debug.Debug() {
+		fmt.Printf("%s %s\n", lw.name, msg)
+	}
+
+	if lw.messages != nil {
+		select {
+		case lw.messages <- msg:
+		case <-lw.ctx.Done():
+		}
+	}
+}
+
+func (lw *retryListWatch) getError() error {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+
+	return lw.err
+}
@@ -192,7 +192,7 @@ func (tracker *EliminationTracker) Track(ctx context.Context, opts EliminationTr
 		}
 
 		if debug() {
-			fmt.Printf("[TrackUntilEliminated][%s] Not found existing object: stop tracking\n", tracker.Spec.String(), len(objs))
+			fmt.Printf("[TrackUntilEliminated][%s] Not found existing object: stop tracking\n", tracker.Spec.String())
 		}
 		return true, nil
 	}, func(ev watch.Event) (bool, error) {
@@ -16,48 +16,39 @@ func TrackDeployment(name, namespace string, kube kubernetes.Interface, opts tra
 
 	feed.OnAdded(func(isReady bool) error {
 		if isReady {
-			fmt.Fprintf(display.Out, "# deploy/%s appears to be ready\n", name)
-		} else {
-			fmt.Fprintf(display.Out, "# deploy/%s added\n", name)
+			return display.PrintEvent(display.EventReady, "deploy", namespace, name, feed.GetStatus(), "appears to be ready")
 		}
-		return nil
+		return display.PrintEvent(display.EventAdded, "deploy", namespace, name, feed.GetStatus(), "added")
 	})
 	feed.OnReady(func() error {
-		fmt.Fprintf(display.Out, "# deploy/%s become READY\n", name)
-		return nil
+		return display.PrintEvent(display.EventReady, "deploy", namespace, name, feed.GetStatus(), "become READY")
 	})
 	feed.OnFailed(func(reason string) error {
-		fmt.Fprintf(display.Out, "# deploy/%s FAIL: %s\n", name, reason)
-		return nil
+		return display.PrintEvent(display.EventFailed, "deploy", namespace, name, nil, "FAIL: %s", reason)
 	})
 	feed.OnEventMsg(func(msg string) error {
-		fmt.Fprintf(display.Out, "# deploy/%s event: %s\n", name, msg)
-		return nil
+		return display.PrintEvent(display.EventMessage, "deploy", namespace, name, nil, "event: %s", msg)
+	})
+	feed.OnServiceMsg(func(msg string) error {
+		return display.PrintEvent(display.EventServiceMessage, "deploy", namespace, name, nil, "%s", msg)
 	})
 	feed.OnAddedReplicaSet(func(rs replicaset.ReplicaSet) error {
 		if rs.IsNew {
-			fmt.Fprintf(display.Out, "# deploy/%s new rs/%s added\n", name, rs.Name)
-		} else {
-			fmt.Fprintf(display.Out, "# deploy/%s rs/%s added\n", name, rs.Name)
+			return display.PrintEvent(display.EventServiceMessage, "deploy", namespace, name, nil, "new rs/%s added", rs.Name)
 		}
-
-		return nil
+		return display.PrintEvent(display.EventServiceMessage, "deploy", namespace, name, nil, "rs/%s added", rs.Name)
 	})
 	feed.OnAddedPod(func(pod replicaset.ReplicaSetPod) error {
 		if pod.ReplicaSet.IsNew {
-			fmt.Fprintf(display.Out, "# deploy/%s rs/%s(new) po/%s added\n", name, pod.ReplicaSet.Name, pod.Name)
-		} else {
-			fmt.Fprintf(display.Out, "# deploy/%s rs/%s po/%s added\n", name, pod.ReplicaSet.Name, pod.Name)
+			return display.PrintEvent(display.EventServiceMessage, "deploy", namespace, name, nil, "rs/%s(new) po/%s added", pod.ReplicaSet.Name, pod.Name)
 		}
-		return nil
+		return display.PrintEvent(display.EventServiceMessage, "deploy", namespace, name, nil, "rs/%s po/%s added", pod.ReplicaSet.Name, pod.Name)
 	})
 	feed.OnPodError(func(podError replicaset.ReplicaSetPodError) error {
 		if podError.ReplicaSet.IsNew {
-			fmt.Fprintf(display.Out, "# deploy/%s rs/%s(new) po/%s %s error: %s\n", name, podError.ReplicaSet.Name, podError.PodName, podError.ContainerName, podError.Message)
-		} else {
-			fmt.Fprintf(display.Out, "# deploy/%s rs/%s po/%s %s error: %s\n", name, podError.ReplicaSet.Name, podError.PodName, podError.ContainerName, podError.Message)
+			return display.PrintEvent(display.EventFailed, "deploy", namespace, name, nil, "rs/%s(new) po/%s %s error: %s", podError.ReplicaSet.Name, podError.PodName, podError.ContainerName, podError.Message)
 		}
-		return nil
+		return display.PrintEvent(display.EventFailed, "deploy", namespace, name, nil, "rs/%s po/%s %s error: %s", podError.ReplicaSet.Name, podError.PodName, podError.ContainerName, podError.Message)
 	})
 	feed.OnPodLogChunk(func(chunk *replicaset.ReplicaSetPodLogChunk) error {
 		header := ""
@@ -66,8 +57,10 @@ func TrackDeployment(name, namespace string, kube kubernetes.Interface, opts tra
 		} else {
 			header = fmt.Sprintf("deploy/%s rs/%s po/%s %s", name, chunk.ReplicaSet.Name, chunk.PodName, chunk.ContainerName)
 		}
-		display.OutputLogLines(header, chunk.LogLines)
-		return nil
+		return display.PrintLogLines("deploy", namespace, name, chunk.PodName, chunk.ContainerName, header, chunk.LogLines)
+	})
+	feed.OnStatus(func(status deployment.DeploymentStatus) error {
+		return display.PrintStatus("deploy", namespace, name, status)
 	})
 
 	return feed.Track(name, namespace, kube, opts)
@@ -15,33 +15,32 @@ func TrackJob(name, namespace string, kube kubernetes.Interface, opts tracker.Op
 	feed := job.NewFeed()
 
 	feed.OnAdded(func() error {
-		fmt.Fprintf(display.Out, "# job/%s added\n", name)
-		return nil
+		return display.PrintEvent(display.EventAdded, "job", namespace, name, feed.GetStatus(), "added")
 	})
 	feed.OnSucceeded(func() error {
-		fmt.Fprintf(display.Out, "# job/%s succeeded\n", name)
-		return nil
+		return display.PrintEvent(display.EventSucceeded, "job", namespace, name, feed.GetStatus(), "succeeded")
 	})
 	feed.OnFailed(func(reason string) error {
-		fmt.Fprintf(display.Out, "# job/%s FAIL: %s\n", name, reason)
-		return nil
+		return display.PrintEvent(display.EventFailed, "job", namespace, name, nil, "FAIL: %s", reason)
 	})
 	feed.OnEventMsg(func(msg string) error {
-		fmt.Fprintf(display.Out, "# job/%s event: %s\n", name, msg)
-		return nil
+		return display.PrintEvent(display.EventMessage, "job", namespace, name, nil, "event: %s", msg)
+	})
+	feed.OnServiceMsg(func(msg string) error {
+		return display.PrintEvent(display.EventServiceMessage, "job", namespace, name, nil, "%s", msg)
 	})
 	feed.OnAddedPod(func(podName string) error {
-		fmt.Fprintf(display.Out, "# job/%s po/%s added\n", name, podName)
-		return nil
+		return display.PrintEvent(display.EventServiceMessage, "job", namespace, name, nil, "po/%s added", podName)
 	})
 	feed.OnPodError(func(podError pod.PodError) error {
-		fmt.Fprintf(display.Out, "# job/%s po/%s %s error: %s\n", name, podError.PodName, podError.ContainerName, podError.Message)
-		return nil
+		return display.PrintEvent(display.EventFailed, "job", namespace, name, nil, "po/%s %s error: %s", podError.PodName, podError.ContainerName, podError.Message)
 	})
 	feed.OnPodLogChunk(func(chunk *pod.PodLogChunk) error {
 		header := fmt.Sprintf("po/%s %s", chunk.PodName, chunk.ContainerName)
-		display.OutputLogLines(header, chunk.LogLines)
-		return nil
+		return display.PrintLogLines("job", namespace, name, chunk.PodName, chunk.ContainerName, header, chunk.LogLines)
+	})
+	feed.OnStatus(func(status job.JobStatus) error {
+		return display.PrintStatus("job", namespace, name, status)
 	})
 
 	return feed.Track(name, namespace, kube, opts)
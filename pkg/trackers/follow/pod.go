@@ -14,33 +14,32 @@ func TrackPod(name, namespace string, kube kubernetes.Interface, opts tracker.Op
 	feed := pod.NewFeed()
 
 	feed.OnAdded(func() error {
-		fmt.Fprintf(display.Out, "# po/%s added\n", name)
-		return nil
+		return display.PrintEvent(display.EventAdded, "po", namespace, name, feed.GetStatus(), "added")
 	})
 	feed.OnSucceeded(func() error {
-		fmt.Fprintf(display.Out, "# po/%s succeeded\n", name)
-		return nil
+		return display.PrintEvent(display.EventSucceeded, "po", namespace, name, feed.GetStatus(), "succeeded")
 	})
 	feed.OnFailed(func(reason string) error {
-		fmt.Fprintf(display.Out, "# po/%s failed: %s\n", name, reason)
-		return nil
+		return display.PrintEvent(display.EventFailed, "po", namespace, name, nil, "failed: %s", reason)
 	})
 	feed.OnReady(func() error {
-		fmt.Fprintf(display.Out, "# po/%s become READY\n", name)
-		return nil
+		return display.PrintEvent(display.EventReady, "po", namespace, name, feed.GetStatus(), "become READY")
 	})
 	feed.OnEventMsg(func(msg string) error {
-		fmt.Fprintf(display.Out, "# po/%s event: %s\n", name, msg)
-		return nil
+		return display.PrintEvent(display.EventMessage, "po", namespace, name, nil, "event: %s", msg)
+	})
+	feed.OnServiceMsg(func(msg string) error {
+		return display.PrintEvent(display.EventServiceMessage, "po", namespace, name, nil, "%s", msg)
 	})
 	feed.OnContainerError(func(containerError pod.ContainerError) error {
-		fmt.Fprintf(display.Out, "# po/%s %s error: %s\n", name, containerError.ContainerName, containerError.Message)
-		return nil
+		return display.PrintEvent(display.EventFailed, "po", namespace, name, nil, "%s error: %s", containerError.ContainerName, containerError.Message)
 	})
 	feed.OnContainerLogChunk(func(chunk *pod.ContainerLogChunk) error {
 		header := fmt.Sprintf("po/%s %s", name, chunk.ContainerName)
-		display.OutputLogLines(header, chunk.LogLines)
-		return nil
+		return display.PrintLogLines("po", namespace, name, name, chunk.ContainerName, header, chunk.LogLines)
+	})
+	feed.OnStatus(func(status pod.PodStatus) error {
+		return display.PrintStatus("po", namespace, name, status)
 	})
 
 	return feed.Track(name, namespace, kube, opts)
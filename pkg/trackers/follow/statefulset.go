@@ -16,36 +16,34 @@ func TrackStatefulSet(name, namespace string, kube kubernetes.Interface, opts tr
 
 	feed.OnAdded(func(isReady bool) error {
 		if isReady {
-			fmt.Fprintf(display.Out, "# sts/%s appears to be ready\n", name)
-		} else {
-			fmt.Fprintf(display.Out, "# sts/%s added\n", name)
+			return display.PrintEvent(display.EventReady, "sts", namespace, name, feed.GetStatus(), "appears to be ready")
 		}
-		return nil
+		return display.PrintEvent(display.EventAdded, "sts", namespace, name, feed.GetStatus(), "added")
 	})
 	feed.OnReady(func() error {
-		fmt.Fprintf(display.Out, "# sts/%s become READY\n", name)
-		return nil
+		return display.PrintEvent(display.EventReady, "sts", namespace, name, feed.GetStatus(), "become READY")
 	})
 	feed.OnFailed(func(reason string) error {
-		fmt.Fprintf(display.Out, "# sts/%s FAIL: %s\n", name, reason)
-		return nil
+		return display.PrintEvent(display.EventFailed, "sts", namespace, name, nil, "FAIL: %s", reason)
 	})
 	feed.OnEventMsg(func(msg string) error {
-		fmt.Fprintf(display.Out, "# sts/%s event: %s\n", name, msg)
-		return nil
+		return display.PrintEvent(display.EventMessage, "sts", namespace, name, nil, "event: %s", msg)
+	})
+	feed.OnServiceMsg(func(msg string) error {
+		return display.PrintEvent(display.EventServiceMessage, "sts", namespace, name, nil, "%s", msg)
 	})
 	feed.OnAddedPod(func(pod replicaset.ReplicaSetPod) error {
-		fmt.Fprintf(display.Out, "# sts/%s po/%s added\n", name, pod.Name)
-		return nil
+		return display.PrintEvent(display.EventServiceMessage, "sts", namespace, name, nil, "po/%s added", pod.Name)
 	})
 	feed.OnPodError(func(podError replicaset.ReplicaSetPodError) error {
-		fmt.Fprintf(display.Out, "# sts/%s %s %s error: %s\n", name, podError.PodName, podError.ContainerName, podError.Message)
-		return nil
+		return display.PrintEvent(display.EventFailed, "sts", namespace, name, nil, "%s %s error: %s", podError.PodName, podError.ContainerName, podError.Message)
 	})
 	feed.OnPodLogChunk(func(chunk *replicaset.ReplicaSetPodLogChunk) error {
 		header := fmt.Sprintf("po/%s %s", chunk.PodName, chunk.ContainerName)
-		display.OutputLogLines(header, chunk.LogLines)
-		return nil
+		return display.PrintLogLines("sts", namespace, name, chunk.PodName, chunk.ContainerName, header, chunk.LogLines)
+	})
+	feed.OnStatus(func(status statefulset.StatefulSetStatus) error {
+		return display.PrintStatus("sts", namespace, name, status)
 	})
 
 	return feed.Track(name, namespace, kube, opts)
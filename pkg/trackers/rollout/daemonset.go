@@ -19,36 +19,46 @@ func TrackDaemonSetTillReady(name, namespace string, kube kubernetes.Interface,
 
 	feed.OnAdded(func(isReady bool) error {
 		if isReady {
-			fmt.Fprintf(display.Out, "# ds/%s appears to be ready. Exit\n", name)
+			if err := display.PrintEvent(display.EventReady, "ds", namespace, name, feed.GetStatus(), "appears to be ready. Exit"); err != nil {
+				return err
+			}
 			return tracker.StopTrack
 		}
-		fmt.Fprintf(display.Out, "# ds/%s added\n", name)
-		return nil
+		return display.PrintEvent(display.EventAdded, "ds", namespace, name, feed.GetStatus(), "added")
 	})
 	feed.OnReady(func() error {
-		fmt.Fprintf(display.Out, "# ds/%s become READY\n", name)
+		if err := display.PrintEvent(display.EventReady, "ds", namespace, name, feed.GetStatus(), "become READY"); err != nil {
+			return err
+		}
 		return tracker.StopTrack
 	})
 	feed.OnFailed(func(reason string) error {
-		fmt.Fprintf(display.Err, "# ds/%s FAIL: %s\n", name, reason)
+		if err := display.PrintEvent(display.EventFailed, "ds", namespace, name, nil, "FAIL: %s", reason); err != nil {
+			return err
+		}
 		return tracker.ResourceErrorf("ds/%s failed: %s", name, reason)
 	})
 	feed.OnEventMsg(func(msg string) error {
-		fmt.Fprintf(display.Out, "# ds/%s event: %s\n", name, msg)
-		return nil
+		return display.PrintEvent(display.EventMessage, "ds", namespace, name, nil, "event: %s", msg)
+	})
+	feed.OnServiceMsg(func(msg string) error {
+		return display.PrintEvent(display.EventServiceMessage, "ds", namespace, name, nil, "%s", msg)
 	})
 	feed.OnAddedPod(func(pod replicaset.ReplicaSetPod) error {
-		fmt.Fprintf(display.Out, "# ds/%s po/%s added\n", name, pod.Name)
-		return nil
+		return display.PrintEvent(display.EventServiceMessage, "ds", namespace, name, nil, "po/%s added", pod.Name)
 	})
 	feed.OnPodError(func(podError replicaset.ReplicaSetPodError) error {
-		fmt.Fprintf(display.Err, "# ds/%s %s %s error: %s\n", name, podError.PodName, podError.ContainerName, podError.Message)
+		if err := display.PrintEvent(display.EventFailed, "ds", namespace, name, nil, "%s %s error: %s", podError.PodName, podError.ContainerName, podError.Message); err != nil {
+			return err
+		}
 		return tracker.ResourceErrorf("ds/%s po/%s %s failed: %s", name, podError.PodName, podError.ContainerName, podError.Message)
 	})
 	feed.OnPodLogChunk(func(chunk *replicaset.ReplicaSetPodLogChunk) error {
 		header := fmt.Sprintf("po/%s %s", chunk.PodName, chunk.ContainerName)
-		display.OutputLogLines(header, chunk.LogLines)
-		return nil
+		return display.PrintLogLines("ds", namespace, name, chunk.PodName, chunk.ContainerName, header, chunk.LogLines)
+	})
+	feed.OnStatus(func(status daemonset.DaemonSetStatus) error {
+		return display.PrintStatus("ds", namespace, name, status)
 	})
 
 	err := feed.Track(name, namespace, kube, opts)
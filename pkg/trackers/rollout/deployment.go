@@ -16,43 +16,50 @@ func TrackDeploymentTillReady(name, namespace string, kube kubernetes.Interface,
 
 	feed.OnAdded(func(isReady bool) error {
 		if isReady {
-			fmt.Fprintf(display.Out, "# deploy/%s appears to be ready\n", name)
+			if err := display.PrintEvent(display.EventReady, "deploy", namespace, name, feed.GetStatus(), "appears to be ready"); err != nil {
+				return err
+			}
 			return tracker.StopTrack
 		}
-		fmt.Fprintf(display.Out, "# deploy/%s added\n", name)
-		return nil
+		return display.PrintEvent(display.EventAdded, "deploy", namespace, name, feed.GetStatus(), "added")
 	})
 	feed.OnReady(func() error {
-		fmt.Fprintf(display.Out, "# deploy/%s become READY\n", name)
+		if err := display.PrintEvent(display.EventReady, "deploy", namespace, name, feed.GetStatus(), "become READY"); err != nil {
+			return err
+		}
 		return tracker.StopTrack
 	})
 	feed.OnFailed(func(reason string) error {
-		fmt.Fprintf(display.Out, "# deploy/%s FAIL: %s\n", name, reason)
+		if err := display.PrintEvent(display.EventFailed, "deploy", namespace, name, nil, "FAIL: %s", reason); err != nil {
+			return err
+		}
 		return tracker.ResourceErrorf("failed: %s", reason)
 	})
 	feed.OnEventMsg(func(msg string) error {
-		fmt.Fprintf(display.Out, "# deploy/%s event: %s\n", name, msg)
-		return nil
+		return display.PrintEvent(display.EventMessage, "deploy", namespace, name, nil, "event: %s", msg)
+	})
+	feed.OnServiceMsg(func(msg string) error {
+		return display.PrintEvent(display.EventServiceMessage, "deploy", namespace, name, nil, "%s", msg)
 	})
 	feed.OnAddedReplicaSet(func(rs replicaset.ReplicaSet) error {
 		if !rs.IsNew {
 			return nil
 		}
-		fmt.Fprintf(display.Out, "# deploy/%s rs/%s added\n", name, rs.Name)
-		return nil
+		return display.PrintEvent(display.EventServiceMessage, "deploy", namespace, name, nil, "rs/%s added", rs.Name)
 	})
 	feed.OnAddedPod(func(pod replicaset.ReplicaSetPod) error {
 		if !pod.ReplicaSet.IsNew {
 			return nil
 		}
-		fmt.Fprintf(display.Out, "# deploy/%s po/%s added\n", name, pod.Name)
-		return nil
+		return display.PrintEvent(display.EventServiceMessage, "deploy", namespace, name, nil, "po/%s added", pod.Name)
 	})
 	feed.OnPodError(func(podError replicaset.ReplicaSetPodError) error {
 		if !podError.ReplicaSet.IsNew {
 			return nil
 		}
-		fmt.Fprintf(display.Out, "# deploy/%s po/%s %s error: %s\n", name, podError.PodName, podError.ContainerName, podError.Message)
+		if err := display.PrintEvent(display.EventFailed, "deploy", namespace, name, nil, "po/%s %s error: %s", podError.PodName, podError.ContainerName, podError.Message); err != nil {
+			return err
+		}
 		return tracker.ResourceErrorf("deploy/%s po/%s %s failed: %s", name, podError.PodName, podError.ContainerName, podError.Message)
 	})
 	feed.OnPodLogChunk(func(chunk *replicaset.ReplicaSetPodLogChunk) error {
@@ -60,8 +67,10 @@ func TrackDeploymentTillReady(name, namespace string, kube kubernetes.Interface,
 			return nil
 		}
 		header := fmt.Sprintf("po/%s %s", chunk.PodName, chunk.ContainerName)
-		display.OutputLogLines(header, chunk.LogLines)
-		return nil
+		return display.PrintLogLines("deploy", namespace, name, chunk.PodName, chunk.ContainerName, header, chunk.LogLines)
+	})
+	feed.OnStatus(func(status deployment.DeploymentStatus) error {
+		return display.PrintStatus("deploy", namespace, name, status)
 	})
 
 	err := feed.Track(name, namespace, kube, opts)
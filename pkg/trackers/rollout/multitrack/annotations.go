@@ -0,0 +1,570 @@
+package multitrack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/werf/kubedog/pkg/tracker/pod"
+)
+
+const (
+	FailModeAnnotation                  = "kubedog/fail-mode"
+	TrackTerminationModeAnnotation      = "kubedog/track-termination-mode"
+	AllowFailuresCountAnnotation        = "kubedog/allow-failures-count"
+	CountedFailureTypesAnnotation       = "kubedog/counted-failure-types"
+	FailurePolicyAnnotation             = "kubedog/failure-policy"
+	FailureThresholdSecondsAnnotation   = "kubedog/failure-threshold-seconds"
+	TimeoutSecondsAnnotation            = "kubedog/timeout-seconds"
+	NoProgressTimeoutSecondsAnnotation  = "kubedog/no-progress-timeout-seconds"
+	FailOnProgressDeadlineAnnotation    = "kubedog/fail-on-progress-deadline"
+	RollbackOnFailureAnnotation         = "kubedog/rollback-on-failure"
+	LogRegexAnnotation                  = "kubedog/log-regex"
+	SkipLogsAnnotation                  = "kubedog/skip-logs"
+	SkipLogsForContainersAnnotation     = "kubedog/skip-logs-for-containers"
+	ShowLogsOnlyForContainersAnnotation = "kubedog/show-logs-only-for-containers"
+	ShowServiceMessagesAnnotation       = "kubedog/show-service-messages"
+	DependsOnAnnotation                 = "kubedog/depends-on"
+
+	// LogRegexForAnnotationPrefix is followed by the container name: kubedog/log-regex-for-CONTAINER.
+	LogRegexForAnnotationPrefix = "kubedog/log-regex-for-"
+	// IgnoreReadinessProbeFailsForAnnotationPrefix is followed by the container name: kubedog/ignore-readiness-probe-fails-for-CONTAINER.
+	IgnoreReadinessProbeFailsForAnnotationPrefix = "kubedog/ignore-readiness-probe-fails-for-"
+)
+
+// errUnknownAnnotation is returned for kubedog/* annotations, which are not supported, e.g. added in newer versions.
+var errUnknownAnnotation = errors.New("unknown kubedog annotation")
+
+// SetSpecFromAnnotations configures spec using kubedog/* annotations of the resource.
+// Annotations not related to kubedog are ignored.
+func SetSpecFromAnnotations(spec *MultitrackSpec, annotations map[string]string) error {
+	var errs []string
+
+	for _, name := range getKubedogAnnotationsNames(annotations) {
+		if err := setSpecFromAnnotation(spec, name, annotations[name]); err != nil {
+			errs = append(errs, fmt.Sprintf("annotation %s: %s", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// MergeSpecWithAnnotations sets spec fields, which are not set by the caller, using kubedog/* annotations.
+// Values explicitly set in the spec always take precedence over annotations. Unknown annotations are skipped,
+// so that annotations of newer versions do not break tracking, see GetUnknownAnnotations. Returns annotations
+// which were applied to the spec.
+func MergeSpecWithAnnotations(spec *MultitrackSpec, annotations map[string]string) (map[string]string, error) {
+	applied := make(map[string]string)
+	var errs []string
+
+	for _, name := range getKubedogAnnotationsNames(annotations) {
+		annotationSpec := MultitrackSpec{}
+		if err := setSpecFromAnnotation(&annotationSpec, name, annotations[name]); err == errUnknownAnnotation {
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Sprintf("annotation %s: %s", name, err))
+			continue
+		}
+
+		if mergeSpecFromAnnotation(spec, annotationSpec, name) {
+			applied[name] = annotations[name]
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return applied, nil
+}
+
+// GetUnknownAnnotations returns kubedog/* annotations, which are not supported and are skipped by MergeSpecWithAnnotations.
+func GetUnknownAnnotations(annotations map[string]string) []string {
+	var unknown []string
+	for _, name := range getKubedogAnnotationsNames(annotations) {
+		if err := setSpecFromAnnotation(&MultitrackSpec{}, name, annotations[name]); err == errUnknownAnnotation {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+func getKubedogAnnotationsNames(annotations map[string]string) []string {
+	names := []string{}
+	for name := range annotations {
+		if strings.HasPrefix(name, "kubedog/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func setSpecFromAnnotation(spec *MultitrackSpec, name, value string) error {
+	switch {
+	case name == FailModeAnnotation:
+		switch FailMode(value) {
+		case IgnoreAndContinueDeployProcess, FailWholeDeployProcessImmediately, HopeUntilEndOfDeployProcess:
+			spec.FailMode = FailMode(value)
+		default:
+			return fmt.Errorf("invalid value %q, expected one of: %s, %s, %s", value, IgnoreAndContinueDeployProcess, FailWholeDeployProcessImmediately, HopeUntilEndOfDeployProcess)
+		}
+
+	case name == TrackTerminationModeAnnotation:
+		switch TrackTerminationMode(value) {
+		case WaitUntilResourceReady, NonBlocking:
+			spec.TrackTerminationMode = TrackTerminationMode(value)
+		default:
+			return fmt.Errorf("invalid value %q, expected one of: %s, %s", value, WaitUntilResourceReady, NonBlocking)
+		}
+
+	case name == AllowFailuresCountAnnotation:
+		count, err := parseAnnotationNonNegativeInt(value)
+		if err != nil {
+			return err
+		}
+		spec.AllowFailuresCount = &count
+
+	case name == CountedFailureTypesAnnotation:
+		failureTypes, err := parseAnnotationFailureTypes(value)
+		if err != nil {
+			return err
+		}
+		spec.CountedFailureTypes = failureTypes
+
+	case name == FailurePolicyAnnotation:
+		policy, err := parseFailurePolicy(value)
+		if err != nil {
+			return err
+		}
+		spec.FailurePolicy = policy
+
+	case name == FailureThresholdSecondsAnnotation:
+		seconds, err := parseAnnotationNonNegativeInt(value)
+		if err != nil {
+			return err
+		}
+		spec.FailureThresholdSeconds = &seconds
+
+	case name == TimeoutSecondsAnnotation:
+		seconds, err := parseAnnotationNonNegativeInt(value)
+		if err != nil {
+			return err
+		}
+		spec.TimeoutSeconds = &seconds
+
+	case name == NoProgressTimeoutSecondsAnnotation:
+		seconds, err := parseAnnotationNonNegativeInt(value)
+		if err != nil {
+			return err
+		}
+		spec.NoProgressTimeoutSeconds = &seconds
+
+	case name == FailOnProgressDeadlineAnnotation:
+		failOnProgressDeadline, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q, boolean expected", value)
+		}
+		spec.FailOnProgressDeadline = &failOnProgressDeadline
+
+	case name == RollbackOnFailureAnnotation:
+		rollbackOnFailure, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q, boolean expected", value)
+		}
+		spec.RollbackOnFailure = &rollbackOnFailure
+
+	case name == LogRegexAnnotation:
+		logRegex, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		spec.LogRegex = logRegex
+
+	case name == SkipLogsAnnotation:
+		skipLogs, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q, boolean expected", value)
+		}
+		spec.SkipLogs = skipLogs
+
+	case name == SkipLogsForContainersAnnotation:
+		spec.SkipLogsForContainers = parseAnnotationList(value)
+
+	case name == ShowLogsOnlyForContainersAnnotation:
+		spec.ShowLogsOnlyForContainers = parseAnnotationList(value)
+
+	case name == ShowServiceMessagesAnnotation:
+		showServiceMessages, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q, boolean expected", value)
+		}
+		spec.ShowServiceMessages = showServiceMessages
+
+	case name == DependsOnAnnotation:
+		refs := parseAnnotationList(value)
+		for _, ref := range refs {
+			if err := validateDependencyRef(ref); err != nil {
+				return err
+			}
+		}
+		spec.DependsOn = refs
+
+	case strings.HasPrefix(name, LogRegexForAnnotationPrefix):
+		containerName := strings.TrimPrefix(name, LogRegexForAnnotationPrefix)
+		logRegex, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		if spec.LogRegexByContainerName == nil {
+			spec.LogRegexByContainerName = make(map[string]*regexp.Regexp)
+		}
+		spec.LogRegexByContainerName[containerName] = logRegex
+
+	case strings.HasPrefix(name, IgnoreReadinessProbeFailsForAnnotationPrefix):
+		containerName := strings.TrimPrefix(name, IgnoreReadinessProbeFailsForAnnotationPrefix)
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q, duration expected: %s", value, err)
+		}
+		if spec.IgnoreReadinessProbeFailsByContainerName == nil {
+			spec.IgnoreReadinessProbeFailsByContainerName = make(map[string]time.Duration)
+		}
+		spec.IgnoreReadinessProbeFailsByContainerName[containerName] = duration
+
+	default:
+		return errUnknownAnnotation
+	}
+
+	return nil
+}
+
+func mergeSpecFromAnnotation(spec *MultitrackSpec, annotationSpec MultitrackSpec, name string) bool {
+	switch {
+	case name == FailModeAnnotation:
+		if spec.FailMode != "" {
+			return false
+		}
+		spec.FailMode = annotationSpec.FailMode
+
+	case name == TrackTerminationModeAnnotation:
+		if spec.TrackTerminationMode != "" {
+			return false
+		}
+		spec.TrackTerminationMode = annotationSpec.TrackTerminationMode
+
+	case name == AllowFailuresCountAnnotation:
+		if spec.AllowFailuresCount != nil {
+			return false
+		}
+		spec.AllowFailuresCount = annotationSpec.AllowFailuresCount
+
+	case name == CountedFailureTypesAnnotation:
+		if len(spec.CountedFailureTypes) > 0 {
+			return false
+		}
+		spec.CountedFailureTypes = annotationSpec.CountedFailureTypes
+
+	case name == FailurePolicyAnnotation:
+		if len(spec.FailurePolicy) > 0 {
+			return false
+		}
+		spec.FailurePolicy = annotationSpec.FailurePolicy
+
+	case name == FailureThresholdSecondsAnnotation:
+		if spec.FailureThresholdSeconds != nil {
+			return false
+		}
+		spec.FailureThresholdSeconds = annotationSpec.FailureThresholdSeconds
+
+	case name == TimeoutSecondsAnnotation:
+		if spec.TimeoutSeconds != nil {
+			return false
+		}
+		spec.TimeoutSeconds = annotationSpec.TimeoutSeconds
+
+	case name == NoProgressTimeoutSecondsAnnotation:
+		if spec.NoProgressTimeoutSeconds != nil {
+			return false
+		}
+		spec.NoProgressTimeoutSeconds = annotationSpec.NoProgressTimeoutSeconds
+
+	case name == FailOnProgressDeadlineAnnotation:
+		if spec.FailOnProgressDeadline != nil {
+			return false
+		}
+		spec.FailOnProgressDeadline = annotationSpec.FailOnProgressDeadline
+
+	case name == RollbackOnFailureAnnotation:
+		if spec.RollbackOnFailure != nil {
+			return false
+		}
+		spec.RollbackOnFailure = annotationSpec.RollbackOnFailure
+
+	case name == LogRegexAnnotation:
+		if spec.LogRegex != nil {
+			return false
+		}
+		spec.LogRegex = annotationSpec.LogRegex
+
+	case name == SkipLogsAnnotation:
+		if spec.SkipLogs {
+			return false
+		}
+		spec.SkipLogs = annotationSpec.SkipLogs
+
+	case name == SkipLogsForContainersAnnotation:
+		if len(spec.SkipLogsForContainers) > 0 {
+			return false
+		}
+		spec.SkipLogsForContainers = annotationSpec.SkipLogsForContainers
+
+	case name == ShowLogsOnlyForContainersAnnotation:
+		if len(spec.ShowLogsOnlyForContainers) > 0 {
+			return false
+		}
+		spec.ShowLogsOnlyForContainers = annotationSpec.ShowLogsOnlyForContainers
+
+	case name == ShowServiceMessagesAnnotation:
+		if spec.ShowServiceMessages {
+			return false
+		}
+		spec.ShowServiceMessages = annotationSpec.ShowServiceMessages
+
+	case name == DependsOnAnnotation:
+		if len(spec.DependsOn) > 0 {
+			return false
+		}
+		spec.DependsOn = annotationSpec.DependsOn
+
+	case strings.HasPrefix(name, LogRegexForAnnotationPrefix):
+		containerName := strings.TrimPrefix(name, LogRegexForAnnotationPrefix)
+		if _, hasKey := spec.LogRegexByContainerName[containerName]; hasKey {
+			return false
+		}
+
+		// Copy the map, because it may be shared with the caller specs
+		logRegexByContainerName := map[string]*regexp.Regexp{containerName: annotationSpec.LogRegexByContainerName[containerName]}
+		for k, v := range spec.LogRegexByContainerName {
+			logRegexByContainerName[k] = v
+		}
+		spec.LogRegexByContainerName = logRegexByContainerName
+
+	case strings.HasPrefix(name, IgnoreReadinessProbeFailsForAnnotationPrefix):
+		containerName := strings.TrimPrefix(name, IgnoreReadinessProbeFailsForAnnotationPrefix)
+		if _, hasKey := spec.IgnoreReadinessProbeFailsByContainerName[containerName]; hasKey {
+			return false
+		}
+
+		ignoreByContainerName := map[string]time.Duration{containerName: annotationSpec.IgnoreReadinessProbeFailsByContainerName[containerName]}
+		for k, v := range spec.IgnoreReadinessProbeFailsByContainerName {
+			ignoreByContainerName[k] = v
+		}
+		spec.IgnoreReadinessProbeFailsByContainerName = ignoreByContainerName
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// mergeSpecsWithLiveAnnotations merges kubedog/* annotations of the live Deployments, StatefulSets, DaemonSets and Jobs
+// into the specs. Returns applied annotations by resource and warnings about skipped unknown annotations.
+func mergeSpecsWithLiveAnnotations(ctx context.Context, kube kubernetes.Interface, specs *MultitrackSpecs) (map[string]map[string]string, []string, error) {
+	res := make(map[string]map[string]string)
+	var warnings []string
+
+	for _, desc := range []struct {
+		Kind  string
+		Specs []MultitrackSpec
+	}{
+		{"deploy", specs.Deployments},
+		{"sts", specs.StatefulSets},
+		{"ds", specs.DaemonSets},
+		{"job", specs.Jobs},
+	} {
+		for i := range desc.Specs {
+			spec := &desc.Specs[i]
+
+			annotations, err := getLiveResourceAnnotations(ctx, kube, desc.Kind, *spec)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to get %s/%s annotations: %s", desc.Kind, spec.ResourceName, err)
+			}
+
+			applied, err := MergeSpecWithAnnotations(spec, annotations)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s/%s: %s", desc.Kind, spec.ResourceName, err)
+			}
+
+			if unknown := GetUnknownAnnotations(annotations); len(unknown) > 0 {
+				warnings = append(warnings, fmt.Sprintf("WARNING: unknown annotations of %s/%s are skipped: %s", desc.Kind, spec.ResourceName, strings.Join(unknown, ", ")))
+			}
+
+			if len(applied) > 0 {
+				res[fmt.Sprintf("%s/%s", desc.Kind, spec.ResourceName)] = applied
+			}
+		}
+	}
+
+	return res, warnings, nil
+}
+
+// annotationsFields are spec fields set by annotations, which are checked by ValidateSpecs.
+var annotationsFields = map[string]string{
+	FailModeAnnotation:                 "FailMode",
+	TrackTerminationModeAnnotation:     "TrackTerminationMode",
+	AllowFailuresCountAnnotation:       "AllowFailuresCount",
+	CountedFailureTypesAnnotation:      "CountedFailureTypes",
+	FailurePolicyAnnotation:            "FailurePolicy",
+	FailureThresholdSecondsAnnotation:  "FailureThresholdSeconds",
+	TimeoutSecondsAnnotation:           "TimeoutSeconds",
+	NoProgressTimeoutSecondsAnnotation: "NoProgressTimeoutSeconds",
+	FailOnProgressDeadlineAnnotation:   "FailOnProgressDeadline",
+	RollbackOnFailureAnnotation:        "RollbackOnFailure",
+	DependsOnAnnotation:                "DependsOn",
+}
+
+// validateSpecsWithLiveAnnotations checks the specs merged with live annotations by ValidateSpecs, so that annotations
+// cannot set values rejected for the specs. Every problem caused by the applied annotation names this annotation.
+func validateSpecsWithLiveAnnotations(specs MultitrackSpecs, appliedByResource map[string]map[string]string) error {
+	err := ValidateSpecs(specs)
+	if err == nil {
+		return nil
+	}
+
+	// Problems are reported by ValidateSpecs as "Deployments[0].Field: message" lines
+	appliedByField := make(map[string]map[string]string)
+	for _, desc := range []struct {
+		Kind  string
+		Field string
+		Specs []MultitrackSpec
+	}{
+		{"deploy", "Deployments", specs.Deployments},
+		{"sts", "StatefulSets", specs.StatefulSets},
+		{"ds", "DaemonSets", specs.DaemonSets},
+		{"job", "Jobs", specs.Jobs},
+	} {
+		for i, spec := range desc.Specs {
+			resource := fmt.Sprintf("%s/%s", desc.Kind, spec.ResourceName)
+			if applied, hasKey := appliedByResource[resource]; hasKey {
+				appliedByField[fmt.Sprintf("%s[%d]", desc.Field, i)] = applied
+			}
+		}
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	for i, line := range lines {
+		field := strings.SplitN(strings.SplitN(line, ":", 2)[0], ".", 2)[0]
+		applied, hasKey := appliedByField[field]
+		if !hasKey {
+			continue
+		}
+
+		var names []string
+		for _, name := range getKubedogAnnotationsNames(applied) {
+			if specField, hasKey := annotationsFields[name]; hasKey && strings.Contains(line, specField) {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			lines[i] = fmt.Sprintf("%s (set by %s annotation)", line, strings.Join(names, ", "))
+		}
+	}
+
+	return fmt.Errorf("%s", strings.Join(lines, "\n"))
+}
+
+func getResourcesWithAppliedAnnotation(appliedByResource map[string]map[string]string, name string) []string {
+	var resources []string
+	for resource, applied := range appliedByResource {
+		if _, hasKey := applied[name]; hasKey {
+			resources = append(resources, resource)
+		}
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// getLiveResourceAnnotations returns annotations of the resource from the cluster or nil when the resource does not exist yet.
+func getLiveResourceAnnotations(ctx context.Context, kube kubernetes.Interface, resourceKind string, spec MultitrackSpec) (map[string]string, error) {
+	var meta *metav1.ObjectMeta
+
+	switch resourceKind {
+	case "deploy":
+		obj, err := kube.AppsV1().Deployments(spec.Namespace).Get(ctx, spec.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		meta = &obj.ObjectMeta
+	case "sts":
+		obj, err := kube.AppsV1().StatefulSets(spec.Namespace).Get(ctx, spec.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		meta = &obj.ObjectMeta
+	case "ds":
+		obj, err := kube.AppsV1().DaemonSets(spec.Namespace).Get(ctx, spec.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		meta = &obj.ObjectMeta
+	case "job":
+		obj, err := kube.BatchV1().Jobs(spec.Namespace).Get(ctx, spec.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		meta = &obj.ObjectMeta
+	default:
+		return nil, nil
+	}
+
+	return meta.Annotations, nil
+}
+
+func ignoreNotFound(err error) error {
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func parseAnnotationNonNegativeInt(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid value %q, non-negative integer expected", value)
+	}
+	return n, nil
+}
+
+func parseAnnotationFailureTypes(value string) ([]pod.FailureType, error) {
+	res := []pod.FailureType{}
+	for _, elem := range parseAnnotationList(value) {
+		if err := validateFailureType(pod.FailureType(elem)); err != nil {
+			return nil, err
+		}
+		res = append(res, pod.FailureType(elem))
+	}
+	return res, nil
+}
+
+func parseAnnotationList(value string) []string {
+	res := []string{}
+	for _, elem := range strings.Split(value, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			res = append(res, elem)
+		}
+	}
+	return res
+}
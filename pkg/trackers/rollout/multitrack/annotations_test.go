@@ -0,0 +1,127 @@
+package multitrack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestMergeSpecWithAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		spec        MultitrackSpec
+		annotations map[string]string
+		expected    MultitrackSpec
+		applied     []string
+	}{
+		{
+			name:        "annotation sets not set field",
+			annotations: map[string]string{AllowFailuresCountAnnotation: "3", FailModeAnnotation: string(HopeUntilEndOfDeployProcess)},
+			expected:    MultitrackSpec{AllowFailuresCount: intPtr(3), FailMode: HopeUntilEndOfDeployProcess},
+			applied:     []string{AllowFailuresCountAnnotation, FailModeAnnotation},
+		},
+		{
+			name:        "spec value takes precedence",
+			spec:        MultitrackSpec{AllowFailuresCount: intPtr(0)},
+			annotations: map[string]string{AllowFailuresCountAnnotation: "3"},
+			expected:    MultitrackSpec{AllowFailuresCount: intPtr(0)},
+		},
+		{
+			name:        "explicit false of pointer field takes precedence",
+			spec:        MultitrackSpec{RollbackOnFailure: boolPtr(false), FailOnProgressDeadline: boolPtr(false)},
+			annotations: map[string]string{RollbackOnFailureAnnotation: "true", FailOnProgressDeadlineAnnotation: "true"},
+			expected:    MultitrackSpec{RollbackOnFailure: boolPtr(false), FailOnProgressDeadline: boolPtr(false)},
+		},
+		{
+			name:        "annotation sets not set pointer field",
+			annotations: map[string]string{RollbackOnFailureAnnotation: "true"},
+			expected:    MultitrackSpec{RollbackOnFailure: boolPtr(true)},
+			applied:     []string{RollbackOnFailureAnnotation},
+		},
+		{
+			// false of plain bool fields is the same as not set
+			name:        "annotation overrides false of plain bool field",
+			spec:        MultitrackSpec{SkipLogs: false, ShowServiceMessages: false},
+			annotations: map[string]string{SkipLogsAnnotation: "true", ShowServiceMessagesAnnotation: "true"},
+			expected:    MultitrackSpec{SkipLogs: true, ShowServiceMessages: true},
+			applied:     []string{ShowServiceMessagesAnnotation, SkipLogsAnnotation},
+		},
+		{
+			name:        "true of plain bool field takes precedence",
+			spec:        MultitrackSpec{SkipLogs: true},
+			annotations: map[string]string{SkipLogsAnnotation: "false"},
+			expected:    MultitrackSpec{SkipLogs: true},
+		},
+		{
+			name:        "unknown and not kubedog annotations are skipped",
+			annotations: map[string]string{"kubedog/from-the-future": "x", "app.kubernetes.io/name": "app"},
+			expected:    MultitrackSpec{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := tc.spec
+			applied, err := MergeSpecWithAnnotations(&spec, tc.annotations)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(spec, tc.expected) {
+				t.Errorf("expected spec %+v, got %+v", tc.expected, spec)
+			}
+
+			appliedNames := getKubedogAnnotationsNames(applied)
+			if len(tc.applied) == 0 {
+				tc.applied = []string{}
+			}
+			if !reflect.DeepEqual(appliedNames, tc.applied) {
+				t.Errorf("expected applied annotations %v, got %v", tc.applied, appliedNames)
+			}
+		})
+	}
+}
+
+func TestMergeSpecWithAnnotationsInvalidValue(t *testing.T) {
+	spec := MultitrackSpec{}
+	_, err := MergeSpecWithAnnotations(&spec, map[string]string{AllowFailuresCountAnnotation: "-1"})
+	if err == nil || !strings.Contains(err.Error(), AllowFailuresCountAnnotation) {
+		t.Errorf("expected error naming %s, got %v", AllowFailuresCountAnnotation, err)
+	}
+}
+
+func TestGetUnknownAnnotations(t *testing.T) {
+	unknown := GetUnknownAnnotations(map[string]string{
+		"kubedog/from-the-future":   "x",
+		SkipLogsAnnotation:          "not-a-bool",
+		"kubedog/log-regex-for-app": ".*",
+		"app.kubernetes.io/name":    "app",
+	})
+
+	if expected := []string{"kubedog/from-the-future"}; !reflect.DeepEqual(unknown, expected) {
+		t.Errorf("expected %v, got %v", expected, unknown)
+	}
+}
+
+func TestValidateSpecsWithLiveAnnotations(t *testing.T) {
+	specs := MultitrackSpecs{
+		Jobs: []MultitrackSpec{{ResourceName: "migrate", Namespace: "ns", RollbackOnFailure: boolPtr(true)}},
+	}
+	applied := map[string]map[string]string{
+		"job/migrate": {RollbackOnFailureAnnotation: "true"},
+	}
+
+	err := validateSpecsWithLiveAnnotations(specs, applied)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "Jobs[0].RollbackOnFailure") || !strings.Contains(err.Error(), RollbackOnFailureAnnotation) {
+		t.Errorf("expected error naming the field and the annotation, got %q", err)
+	}
+}
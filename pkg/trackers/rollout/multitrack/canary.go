@@ -3,6 +3,7 @@ package multitrack
 import (
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/werf/kubedog/pkg/display"
 	"github.com/werf/kubedog/pkg/tracker/canary"
 )
 
@@ -41,6 +42,13 @@ func (mt *multitracker) TrackCanary(kube kubernetes.Interface, spec MultitrackSp
 
 		return mt.canaryEventMsg(spec, feed, msg)
 	})
+	feed.OnServiceMsg(func(msg string) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.displayResourceTrackerMessageF("canary", spec, "%s", msg)
+		return nil
+	})
 
 	feed.OnStatus(func(status canary.CanaryStatus) error {
 		mt.mux.Lock()
@@ -55,21 +63,21 @@ func (mt *multitracker) TrackCanary(kube kubernetes.Interface, spec MultitrackSp
 }
 
 func (mt *multitracker) canaryAdded(spec MultitrackSpec, feed canary.Feed) error {
-	mt.displayResourceTrackerMessageF("canary", spec, "added")
+	mt.displayResourceStateMessageF(display.EventAdded, "canary", spec, "added")
 
 	return nil
 }
 
 func (mt *multitracker) canarySucceeded(spec MultitrackSpec, feed canary.Feed) error {
-	mt.displayResourceTrackerMessageF("canary", spec, "succeeded")
+	mt.displayResourceStateMessageF(display.EventSucceeded, "canary", spec, "succeeded")
 
-	return mt.handleResourceReadyCondition(mt.TrackingCanaries, spec)
+	return mt.handleResourceReadyCondition(mt.TrackingCanaries[spec.ResourceName])
 }
 
 func (mt *multitracker) canaryFailed(spec MultitrackSpec, feed canary.Feed, reason string) error {
 	mt.displayResourceErrorF("canary", spec, "%s", reason)
 
-	return mt.handleResourceFailure(mt.TrackingCanaries, "canary", spec, reason)
+	return mt.handleResourceFailure(mt.TrackingCanaries[spec.ResourceName], "canary", spec, getFailureTypeByReason(reason), reason)
 }
 
 func (mt *multitracker) canaryEventMsg(spec MultitrackSpec, feed canary.Feed, msg string) error {
@@ -0,0 +1,271 @@
+package multitrack
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/werf/kubedog/pkg/display"
+)
+
+const (
+	clusterSucceeded = "succeeded"
+	clusterFailed    = "failed"
+	clusterCancelled = "cancelled"
+)
+
+// MultitrackCluster is the group of specs tracked in the single cluster of MultitrackClusters run.
+type MultitrackCluster struct {
+	// Name identifies the cluster in the output and in the report, e.g. the kubeconfig context name.
+	Name          string
+	Kube          kubernetes.Interface
+	DynamicClient dynamic.Interface
+	Specs         MultitrackSpecs
+}
+
+type MultitrackClustersOptions struct {
+	MultitrackOptions
+
+	// RequiredReadyClusters is the number of clusters, in which all resources should become ready.
+	// Zero value requires all clusters: tracking fails as soon as any cluster fails. Otherwise tracking of
+	// the other clusters continues until the required number of clusters cannot be reached anymore.
+	RequiredReadyClusters int
+}
+
+type clusterResult struct {
+	Index  int
+	Report MultitrackReport
+	Err    error
+}
+
+// MultitrackClusters tracks specs of every cluster in parallel the same way as MultitrackWithReport does.
+// MultitrackOptions are shared by the clusters, except for DynamicClient, which is set by the cluster,
+// and Informers, which are created for every cluster. The returned report contains resources of all clusters.
+func MultitrackClusters(clusters []MultitrackCluster, opts MultitrackClustersOptions) (MultitrackReport, error) {
+	startedAt := time.Now()
+
+	requiredReadyClusters := opts.RequiredReadyClusters
+	if requiredReadyClusters == 0 {
+		requiredReadyClusters = len(clusters)
+	}
+	if err := validateClusters(clusters, requiredReadyClusters); err != nil {
+		return MultitrackReport{StartedAt: startedAt, FinishedAt: startedAt}, err
+	}
+
+	parentContext := opts.ParentContext
+	if parentContext == nil {
+		parentContext = context.Background()
+	}
+
+	tracerProvider := opts.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	traceContext, span := tracerProvider.Tracer(tracerName).Start(parentContext, "multitrack clusters", attributeClusters(clusters))
+	defer span.End()
+
+	ctx, cancel := context.WithCancel(traceContext)
+	defer cancel()
+
+	reporter := opts.Reporter
+	if reporter == nil {
+		reporter = NewLogboekReporter()
+	}
+	reporterMux := &sync.Mutex{}
+
+	resultsChan := make(chan clusterResult, len(clusters))
+	for i := range clusters {
+		clusterOpts := opts.MultitrackOptions
+		clusterOpts.ParentContext = ctx
+		clusterOpts.DynamicClient = clusters[i].DynamicClient
+		clusterOpts.Informers = nil
+		clusterOpts.Reporter = newClusterReporter(reporter, clusters[i].Name, reporterMux)
+		clusterOpts.DiagnosticsBundlePath = getClusterDiagnosticsBundlePath(opts.DiagnosticsBundlePath, clusters[i].Name)
+
+		go func(i int, clusterOpts MultitrackOptions) {
+			report, err := MultitrackWithReport(clusters[i].Kube, copySpecs(clusters[i].Specs), clusterOpts)
+			resultsChan <- clusterResult{Index: i, Report: report, Err: err}
+		}(i, clusterOpts)
+	}
+
+	results := make([]clusterResult, len(clusters))
+	statuses := make([]string, len(clusters))
+	var succeededCount, failedCount int
+	for range clusters {
+		res := <-resultsChan
+		results[res.Index] = res
+
+		switch {
+		case ctx.Err() != nil:
+			statuses[res.Index] = clusterCancelled
+		case res.Err != nil || res.Report.HasFailedResources():
+			statuses[res.Index] = clusterFailed
+			failedCount++
+		default:
+			statuses[res.Index] = clusterSucceeded
+			succeededCount++
+		}
+
+		if statuses[res.Index] == clusterFailed && len(clusters)-failedCount < requiredReadyClusters {
+			reporterMux.Lock()
+			reporter.MultitrackMessage(fmt.Sprintf("Cluster %s failed, %d of required %d clusters cannot be ready anymore: stop tracking of other clusters\n", clusters[res.Index].Name, len(clusters)-failedCount, requiredReadyClusters))
+			reporterMux.Unlock()
+
+			cancel()
+		}
+	}
+
+	report := MultitrackReport{StartedAt: startedAt, FinishedAt: time.Now()}
+	var errs []string
+	for i, res := range results {
+		clusterReport := ClusterReport{Name: clusters[i].Name, Status: statuses[i]}
+		if res.Err != nil && statuses[i] != clusterCancelled {
+			clusterReport.Error = res.Err.Error()
+			errs = append(errs, fmt.Sprintf("cluster %s: %s", clusters[i].Name, res.Err))
+		}
+		report.Clusters = append(report.Clusters, clusterReport)
+
+		for _, resourceReport := range res.Report.Resources {
+			resourceReport.Cluster = clusters[i].Name
+			report.Resources = append(report.Resources, resourceReport)
+		}
+	}
+
+	if succeededCount < requiredReadyClusters {
+		err := fmt.Errorf("%d/%d clusters are ready, %d required", succeededCount, len(clusters), requiredReadyClusters)
+		if len(errs) > 0 {
+			err = fmt.Errorf("%s:\n%s", err, strings.Join(errs, "\n"))
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return report, err
+	}
+
+	return report, nil
+}
+
+func validateClusters(clusters []MultitrackCluster, requiredReadyClusters int) error {
+	if len(clusters) == 0 {
+		return fmt.Errorf("no clusters specified")
+	}
+	if requiredReadyClusters < 0 || requiredReadyClusters > len(clusters) {
+		return fmt.Errorf("invalid required ready clusters count %d: expected value from 1 to %d", requiredReadyClusters, len(clusters))
+	}
+
+	names := make(map[string]bool)
+	for _, cluster := range clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("cluster name is required")
+		}
+		if names[cluster.Name] {
+			return fmt.Errorf("duplicated cluster %q", cluster.Name)
+		}
+		names[cluster.Name] = true
+
+		if err := ValidateSpecs(cluster.Specs); err != nil {
+			return fmt.Errorf("cluster %s: %s", cluster.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func attributeClusters(clusters []MultitrackCluster) trace.SpanStartOption {
+	var names []string
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+	return trace.WithAttributes(attribute.StringSlice("kubedog.clusters", names))
+}
+
+// copySpecs copies the specs lists, which are modified by the run, so that the same specs can be used by all clusters.
+func copySpecs(specs MultitrackSpecs) MultitrackSpecs {
+	return MultitrackSpecs{
+		Deployments:  append([]MultitrackSpec(nil), specs.Deployments...),
+		StatefulSets: append([]MultitrackSpec(nil), specs.StatefulSets...),
+		DaemonSets:   append([]MultitrackSpec(nil), specs.DaemonSets...),
+		Jobs:         append([]MultitrackSpec(nil), specs.Jobs...),
+		Canaries:     append([]MultitrackSpec(nil), specs.Canaries...),
+		Generic:      append([]MultitrackGenericSpec(nil), specs.Generic...),
+		Selectors:    append([]MultitrackSelectorSpec(nil), specs.Selectors...),
+	}
+}
+
+// getClusterDiagnosticsBundlePath returns the bundle path of the cluster: directory of the cluster inside
+// the bundle directory or the archive with the cluster name suffix.
+func getClusterDiagnosticsBundlePath(bundlePath, cluster string) string {
+	if bundlePath == "" {
+		return ""
+	}
+
+	for _, ext := range []string{".tar.gz", ".tgz"} {
+		if strings.HasSuffix(bundlePath, ext) {
+			return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(bundlePath, ext), cluster, ext)
+		}
+	}
+	return filepath.Join(bundlePath, cluster)
+}
+
+// clusterReporter serializes calls of the reporters of all clusters.
+type clusterReporter struct {
+	reporter Reporter
+	mux      *sync.Mutex
+}
+
+func newClusterReporter(reporter Reporter, cluster string, mux *sync.Mutex) Reporter {
+	if r, ok := reporter.(ClusterReporter); ok {
+		reporter = r.ForCluster(cluster)
+	}
+	return &clusterReporter{reporter: reporter, mux: mux}
+}
+
+func (r *clusterReporter) ResourceMessage(msgType display.EventType, resourceKind string, spec MultitrackSpec, msg string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.reporter.ResourceMessage(msgType, resourceKind, spec, msg)
+}
+
+func (r *clusterReporter) ResourceEvent(resourceKind string, spec MultitrackSpec, msg string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.reporter.ResourceEvent(resourceKind, spec, msg)
+}
+
+func (r *clusterReporter) ResourceError(resourceKind string, spec MultitrackSpec, reason string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.reporter.ResourceError(resourceKind, spec, reason)
+}
+
+func (r *clusterReporter) ResourceLogChunk(resourceKind string, spec MultitrackSpec, podName, containerName string, lines []display.LogLine) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.reporter.ResourceLogChunk(resourceKind, spec, podName, containerName, lines)
+}
+
+func (r *clusterReporter) MultitrackMessage(msg string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.reporter.MultitrackMessage(msg)
+}
+
+func (r *clusterReporter) StatusProgress(progress StatusProgress) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.reporter.StatusProgress(progress)
+}
+
+func (r *clusterReporter) TrackingFinished(results []ResourceResult) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.reporter.TrackingFinished(results)
+}
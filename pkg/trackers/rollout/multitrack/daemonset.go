@@ -1,10 +1,9 @@
 package multitrack
 
 import (
-	"fmt"
-
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/werf/kubedog/pkg/display"
 	"github.com/werf/kubedog/pkg/tracker/daemonset"
 	"github.com/werf/kubedog/pkg/tracker/replicaset"
 )
@@ -44,6 +43,13 @@ func (mt *multitracker) TrackDaemonSet(kube kubernetes.Interface, spec Multitrac
 
 		return mt.daemonsetEventMsg(spec, feed, msg)
 	})
+	feed.OnServiceMsg(func(msg string) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.displayResourceTrackerMessageF("ds", spec, "%s", msg)
+		return nil
+	})
 	feed.OnAddedReplicaSet(func(rs replicaset.ReplicaSet) error {
 		mt.mux.Lock()
 		defer mt.mux.Unlock()
@@ -90,26 +96,26 @@ func (mt *multitracker) TrackDaemonSet(kube kubernetes.Interface, spec Multitrac
 
 func (mt *multitracker) daemonsetAdded(spec MultitrackSpec, feed daemonset.Feed, isReady bool) error {
 	if isReady {
-		mt.displayResourceTrackerMessageF("ds", spec, "appears to be READY")
+		mt.displayResourceStateMessageF(display.EventReady, "ds", spec, "appears to be READY")
 
-		return mt.handleResourceReadyCondition(mt.TrackingDaemonSets, spec)
+		return mt.handleResourceReadyCondition(mt.TrackingDaemonSets[spec.ResourceName])
 	}
 
-	mt.displayResourceTrackerMessageF("ds", spec, "added")
+	mt.displayResourceStateMessageF(display.EventAdded, "ds", spec, "added")
 
 	return nil
 }
 
 func (mt *multitracker) daemonsetReady(spec MultitrackSpec, feed daemonset.Feed) error {
-	mt.displayResourceTrackerMessageF("ds", spec, "become READY")
+	mt.displayResourceStateMessageF(display.EventReady, "ds", spec, "become READY")
 
-	return mt.handleResourceReadyCondition(mt.TrackingDaemonSets, spec)
+	return mt.handleResourceReadyCondition(mt.TrackingDaemonSets[spec.ResourceName])
 }
 
 func (mt *multitracker) daemonsetFailed(spec MultitrackSpec, feed daemonset.Feed, reason string) error {
 	mt.displayResourceErrorF("ds", spec, "%s", reason)
 
-	return mt.handleResourceFailure(mt.TrackingDaemonSets, "ds", spec, reason)
+	return mt.handleResourceFailure(mt.TrackingDaemonSets[spec.ResourceName], "ds", spec, getFailureTypeByReason(reason), reason)
 }
 
 func (mt *multitracker) daemonsetEventMsg(spec MultitrackSpec, feed daemonset.Feed, msg string) error {
@@ -128,11 +134,7 @@ func (mt *multitracker) daemonsetAddedPod(spec MultitrackSpec, feed daemonset.Fe
 }
 
 func (mt *multitracker) daemonsetPodError(spec MultitrackSpec, feed daemonset.Feed, podError replicaset.ReplicaSetPodError) error {
-	reason := fmt.Sprintf("po/%s container/%s: %s", podError.PodName, podError.ContainerName, podError.Message)
-
-	mt.displayResourceErrorF("ds", spec, "%s", reason)
-
-	return mt.handleResourceFailure(mt.TrackingDaemonSets, "ds", spec, reason)
+	return mt.handlePodError(mt.TrackingDaemonSets[spec.ResourceName], "ds", spec, podError.PodError)
 }
 
 func (mt *multitracker) daemonsetPodLogChunk(spec MultitrackSpec, feed daemonset.Feed, chunk *replicaset.ReplicaSetPodLogChunk) error {
@@ -143,6 +145,6 @@ func (mt *multitracker) daemonsetPodLogChunk(spec MultitrackSpec, feed daemonset
 		}
 	}
 
-	mt.displayResourceLogChunk("ds", spec, podContainerLogChunkHeader(chunk.PodName, chunk.ContainerLogChunk), chunk.ContainerLogChunk)
+	mt.displayResourceLogChunk("ds", spec, chunk.PodName, chunk.ContainerLogChunk)
 	return nil
 }
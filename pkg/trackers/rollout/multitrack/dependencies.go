@@ -0,0 +1,225 @@
+package multitrack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// multitrackerWaitingResource is the resource, which tracking is postponed until all its dependencies are ready.
+type multitrackerWaitingResource struct {
+	Kind string
+	Spec MultitrackSpec
+
+	// Start starts the tracker of the resource
+	Start func()
+	// Drop releases the resource, which will not be tracked
+	Drop func()
+}
+
+// resolveDependencies maps every resource, which has DependsOn references, to the "kind/name" keys of its dependencies.
+// References should point to the tracked resources and should not form cycles.
+func resolveDependencies(specs MultitrackSpecs, genericResources []multitrackGenericResource) (map[string][]string, error) {
+	// aliases maps every accepted reference to the resource key
+	aliases := make(map[string]string)
+	dependsOn := make(map[string][]string)
+
+	for _, desc := range []struct {
+		Kind  string
+		Specs []MultitrackSpec
+	}{
+		{"deploy", specs.Deployments},
+		{"sts", specs.StatefulSets},
+		{"ds", specs.DaemonSets},
+		{"job", specs.Jobs},
+		{"canary", specs.Canaries},
+	} {
+		for _, spec := range desc.Specs {
+			key := fmt.Sprintf("%s/%s", desc.Kind, spec.ResourceName)
+			aliases[key] = key
+			dependsOn[key] = spec.DependsOn
+		}
+	}
+
+	for i, resource := range genericResources {
+		key := resource.key()
+		aliases[key] = key
+		if specs.Generic[i].Kind != "" {
+			aliases[fmt.Sprintf("%s/%s", specs.Generic[i].Kind, resource.Spec.ResourceName)] = key
+		}
+		dependsOn[key] = resource.Spec.DependsOn
+	}
+
+	res := make(map[string][]string)
+	var errs []string
+
+	for _, key := range sortedDependenciesKeys(dependsOn) {
+		for _, ref := range dependsOn[key] {
+			depKey, hasKey := aliases[ref]
+			if !hasKey {
+				errs = append(errs, fmt.Sprintf("%s depends on %s, which is not tracked", key, ref))
+				continue
+			}
+			if depKey == key {
+				errs = append(errs, fmt.Sprintf("%s depends on itself", key))
+				continue
+			}
+			res[key] = append(res[key], depKey)
+		}
+	}
+
+	if len(errs) == 0 {
+		if cycle := findDependenciesCycle(res); cycle != nil {
+			errs = append(errs, fmt.Sprintf("dependencies cycle: %s", strings.Join(cycle, " -> ")))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid dependencies:\n%s", strings.Join(errs, "\n"))
+	}
+	return res, nil
+}
+
+// findDependenciesCycle returns the first found cycle of the dependencies graph or nil.
+func findDependenciesCycle(dependencies map[string][]string) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	marks := make(map[string]int)
+	var path []string
+
+	var visit func(key string) []string
+	visit = func(key string) []string {
+		switch marks[key] {
+		case visited:
+			return nil
+		case visiting:
+			for i, pathKey := range path {
+				if pathKey == key {
+					return append(append([]string{}, path[i:]...), key)
+				}
+			}
+		}
+
+		marks[key] = visiting
+		path = append(path, key)
+		for _, depKey := range dependencies[key] {
+			if cycle := visit(depKey); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		marks[key] = visited
+
+		return nil
+	}
+
+	for _, key := range sortedDependenciesKeys(dependencies) {
+		if cycle := visit(key); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+func sortedDependenciesKeys(dependencies map[string][]string) []string {
+	keys := []string{}
+	for key := range dependencies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getNotReadyDependencies returns dependencies of the resource, which are not ready yet.
+func (mt *multitracker) getNotReadyDependencies(key string) []string {
+	var res []string
+	for _, depKey := range mt.dependencies[key] {
+		if state := mt.getResourceStateByKey(depKey); state == nil || state.Status != resourceSucceeded {
+			res = append(res, depKey)
+		}
+	}
+	return res
+}
+
+// isDependentOn checks whether the resource depends on the other resource directly or through other dependencies.
+func (mt *multitracker) isDependentOn(key, depKey string) bool {
+	for _, dep := range mt.dependencies[key] {
+		if dep == depKey || mt.isDependentOn(dep, depKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func (mt *multitracker) getResourceStateByKey(key string) *multitrackerResourceState {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	return mt.getResourceState(parts[0], MultitrackSpec{ResourceName: parts[1]})
+}
+
+// updateWaitingResources starts tracking of the waiting resources, which dependencies are ready, and fails
+// the waiting resources, which dependencies have failed. Should be called with locked mux.
+func (mt *multitracker) updateWaitingResources() {
+	for changed := true; changed; {
+		changed = false
+
+		for _, key := range mt.getWaitingResourcesKeys() {
+			waiting := mt.waitingResources[key]
+
+			var failedDepKey string
+			for _, depKey := range mt.dependencies[key] {
+				if state := mt.getResourceStateByKey(depKey); state != nil && state.Status == resourceFailed {
+					failedDepKey = depKey
+					break
+				}
+			}
+
+			switch {
+			case failedDepKey != "":
+				delete(mt.waitingResources, key)
+				changed = true
+
+				mt.displayMultitrackServiceMessageF("Dependency %s of %s has failed: stop waiting\n", failedDepKey, key)
+
+				state := mt.getResourceState(waiting.Kind, waiting.Spec)
+				state.Status = resourceFailed
+				state.FailedReason = fmt.Sprintf("dependency %s failed", failedDepKey)
+				state.FailedAt = time.Now()
+				state.endSpan()
+
+				waiting.Drop()
+
+			case len(mt.getNotReadyDependencies(key)) == 0:
+				delete(mt.waitingResources, key)
+
+				mt.displayMultitrackServiceMessageF("Dependencies of %s are ready: start tracking\n", key)
+				mt.addResourceSpanEvent(waiting.Kind, waiting.Spec, "dependencies are ready")
+
+				waiting.Start()
+			}
+		}
+	}
+}
+
+// dropWaitingResources releases all waiting resources, which will not be tracked. Should be called with locked mux.
+func (mt *multitracker) dropWaitingResources() {
+	for key, waiting := range mt.waitingResources {
+		delete(mt.waitingResources, key)
+		waiting.Drop()
+	}
+}
+
+func (mt *multitracker) getWaitingResourcesKeys() []string {
+	keys := []string{}
+	for key := range mt.waitingResources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
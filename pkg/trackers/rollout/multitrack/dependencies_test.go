@@ -0,0 +1,146 @@
+package multitrack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestResolveDependencies(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		specs            MultitrackSpecs
+		genericResources []multitrackGenericResource
+		expected         map[string][]string
+		expectedErrors   []string
+	}{
+		{
+			name: "no dependencies",
+			specs: MultitrackSpecs{
+				Deployments: []MultitrackSpec{{ResourceName: "app"}},
+				Jobs:        []MultitrackSpec{{ResourceName: "migrate"}},
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name: "dependencies between kinds",
+			specs: MultitrackSpecs{
+				Deployments:  []MultitrackSpec{{ResourceName: "app", DependsOn: []string{"job/migrate", "sts/db"}}},
+				StatefulSets: []MultitrackSpec{{ResourceName: "db"}},
+				Jobs:         []MultitrackSpec{{ResourceName: "migrate", DependsOn: []string{"sts/db"}}},
+			},
+			expected: map[string][]string{
+				"deploy/app":  {"job/migrate", "sts/db"},
+				"job/migrate": {"sts/db"},
+			},
+		},
+		{
+			name: "generic resource referenced by kind",
+			specs: MultitrackSpecs{
+				Deployments: []MultitrackSpec{{ResourceName: "app", DependsOn: []string{"certificate/tls"}}},
+				Generic:     []MultitrackGenericSpec{{Kind: "certificate", MultitrackSpec: MultitrackSpec{ResourceName: "tls"}}},
+			},
+			genericResources: []multitrackGenericResource{{
+				Kind:                 "certificates.cert-manager.io",
+				GroupVersionResource: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+				Spec:                 MultitrackSpec{ResourceName: "tls"},
+			}},
+			expected: map[string][]string{
+				"deploy/app": {"certificates.cert-manager.io/tls"},
+			},
+		},
+		{
+			name: "not tracked dependency",
+			specs: MultitrackSpecs{
+				Deployments: []MultitrackSpec{{ResourceName: "app", DependsOn: []string{"job/migrate"}}},
+			},
+			expectedErrors: []string{"deploy/app depends on job/migrate, which is not tracked"},
+		},
+		{
+			name: "dependency on itself",
+			specs: MultitrackSpecs{
+				Deployments: []MultitrackSpec{{ResourceName: "app", DependsOn: []string{"deploy/app"}}},
+			},
+			expectedErrors: []string{"deploy/app depends on itself"},
+		},
+		{
+			name: "dependencies cycle",
+			specs: MultitrackSpecs{
+				Deployments:  []MultitrackSpec{{ResourceName: "app", DependsOn: []string{"sts/db"}}},
+				StatefulSets: []MultitrackSpec{{ResourceName: "db", DependsOn: []string{"job/migrate"}}},
+				Jobs:         []MultitrackSpec{{ResourceName: "migrate", DependsOn: []string{"deploy/app"}}},
+			},
+			expectedErrors: []string{"dependencies cycle: deploy/app -> sts/db -> job/migrate -> deploy/app"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dependencies, err := resolveDependencies(tc.specs, tc.genericResources)
+
+			if len(tc.expectedErrors) > 0 {
+				if err == nil {
+					t.Fatalf("expected error, got dependencies %v", dependencies)
+				}
+				for _, expectedError := range tc.expectedErrors {
+					if !strings.Contains(err.Error(), expectedError) {
+						t.Errorf("expected error containing %q, got %q", expectedError, err)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(dependencies, tc.expected) {
+				t.Errorf("expected dependencies %v, got %v", tc.expected, dependencies)
+			}
+		})
+	}
+}
+
+func TestFindDependenciesCycle(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		dependencies map[string][]string
+		expected     []string
+	}{
+		{
+			name:         "empty graph",
+			dependencies: map[string][]string{},
+		},
+		{
+			name: "diamond without cycle",
+			dependencies: map[string][]string{
+				"a": {"b", "c"},
+				"b": {"d"},
+				"c": {"d"},
+			},
+		},
+		{
+			name: "two resources cycle",
+			dependencies: map[string][]string{
+				"a": {"b"},
+				"b": {"a"},
+			},
+			expected: []string{"a", "b", "a"},
+		},
+		{
+			name: "cycle not including the first resource",
+			dependencies: map[string][]string{
+				"a": {"b"},
+				"b": {"c"},
+				"c": {"d"},
+				"d": {"b"},
+			},
+			expected: []string{"b", "c", "d", "b"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if cycle := findDependenciesCycle(tc.dependencies); !reflect.DeepEqual(cycle, tc.expected) {
+				t.Errorf("expected cycle %v, got %v", tc.expected, cycle)
+			}
+		})
+	}
+}
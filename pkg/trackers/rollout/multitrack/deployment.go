@@ -1,10 +1,9 @@
 package multitrack
 
 import (
-	"fmt"
-
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/werf/kubedog/pkg/display"
 	"github.com/werf/kubedog/pkg/tracker/deployment"
 	"github.com/werf/kubedog/pkg/tracker/replicaset"
 )
@@ -44,6 +43,13 @@ func (mt *multitracker) TrackDeployment(kube kubernetes.Interface, spec Multitra
 
 		return mt.deploymentEventMsg(spec, feed, msg)
 	})
+	feed.OnServiceMsg(func(msg string) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.displayResourceTrackerMessageF("deploy", spec, "%s", msg)
+		return nil
+	})
 	feed.OnAddedReplicaSet(func(rs replicaset.ReplicaSet) error {
 		mt.mux.Lock()
 		defer mt.mux.Unlock()
@@ -90,26 +96,26 @@ func (mt *multitracker) TrackDeployment(kube kubernetes.Interface, spec Multitra
 
 func (mt *multitracker) deploymentAdded(spec MultitrackSpec, feed deployment.Feed, isReady bool) error {
 	if isReady {
-		mt.displayResourceTrackerMessageF("deploy", spec, "appears to be READY")
+		mt.displayResourceStateMessageF(display.EventReady, "deploy", spec, "appears to be READY")
 
-		return mt.handleResourceReadyCondition(mt.TrackingDeployments, spec)
+		return mt.handleResourceReadyCondition(mt.TrackingDeployments[spec.ResourceName])
 	}
 
-	mt.displayResourceTrackerMessageF("deploy", spec, "added")
+	mt.displayResourceStateMessageF(display.EventAdded, "deploy", spec, "added")
 
 	return nil
 }
 
 func (mt *multitracker) deploymentReady(spec MultitrackSpec, feed deployment.Feed) error {
-	mt.displayResourceTrackerMessageF("deploy", spec, "become READY")
+	mt.displayResourceStateMessageF(display.EventReady, "deploy", spec, "become READY")
 
-	return mt.handleResourceReadyCondition(mt.TrackingDeployments, spec)
+	return mt.handleResourceReadyCondition(mt.TrackingDeployments[spec.ResourceName])
 }
 
 func (mt *multitracker) deploymentFailed(spec MultitrackSpec, feed deployment.Feed, reason string) error {
 	mt.displayResourceErrorF("deploy", spec, "%s", reason)
 
-	return mt.handleResourceFailure(mt.TrackingDeployments, "deploy", spec, reason)
+	return mt.handleResourceFailure(mt.TrackingDeployments[spec.ResourceName], "deploy", spec, getFailureTypeByReason(reason), reason)
 }
 
 func (mt *multitracker) deploymentEventMsg(spec MultitrackSpec, feed deployment.Feed, msg string) error {
@@ -142,11 +148,7 @@ func (mt *multitracker) deploymentPodError(spec MultitrackSpec, feed deployment.
 		return nil
 	}
 
-	reason := fmt.Sprintf("po/%s container/%s: %s", podError.PodName, podError.ContainerName, podError.Message)
-
-	mt.displayResourceErrorF("deploy", spec, "%s", reason)
-
-	return mt.handleResourceFailure(mt.TrackingDeployments, "deploy", spec, reason)
+	return mt.handlePodError(mt.TrackingDeployments[spec.ResourceName], "deploy", spec, podError.PodError)
 }
 
 func (mt *multitracker) deploymentPodLogChunk(spec MultitrackSpec, feed deployment.Feed, chunk *replicaset.ReplicaSetPodLogChunk) error {
@@ -161,7 +163,7 @@ func (mt *multitracker) deploymentPodLogChunk(spec MultitrackSpec, feed deployme
 		}
 	}
 
-	mt.displayResourceLogChunk("deploy", spec, podContainerLogChunkHeader(chunk.PodName, chunk.ContainerLogChunk), chunk.ContainerLogChunk)
+	mt.displayResourceLogChunk("deploy", spec, chunk.PodName, chunk.ContainerLogChunk)
 
 	return nil
 }
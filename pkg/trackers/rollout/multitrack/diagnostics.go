@@ -0,0 +1,533 @@
+package multitrack
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/werf/kubedog/pkg/utils"
+)
+
+const (
+	// diagnosticsBundleTimeout limits the time spent on collection of the diagnostics bundle.
+	diagnosticsBundleTimeout = 2 * time.Minute
+	// diagnosticsLogsLimitBytes limits the size of every container log in the diagnostics bundle.
+	diagnosticsLogsLimitBytes = 10 * 1024 * 1024
+)
+
+var canaryGroupVersionResource = schema.GroupVersionResource{Group: "flagger.app", Version: "v1beta1", Resource: "canaries"}
+
+// diagnosticsBundleWriter writes files of the diagnostics bundle by the slash separated names.
+type diagnosticsBundleWriter interface {
+	WriteFile(name string, data []byte) error
+	Close() error
+}
+
+// newDiagnosticsBundleWriter creates tar.gz archive when the path ends with .tar.gz or .tgz and directory otherwise.
+func newDiagnosticsBundleWriter(bundlePath string) (diagnosticsBundleWriter, error) {
+	if strings.HasSuffix(bundlePath, ".tar.gz") || strings.HasSuffix(bundlePath, ".tgz") {
+		if err := os.MkdirAll(filepath.Dir(bundlePath), 0755); err != nil {
+			return nil, err
+		}
+
+		file, err := os.Create(bundlePath)
+		if err != nil {
+			return nil, err
+		}
+
+		gzipWriter := gzip.NewWriter(file)
+		return &tarGzBundleWriter{file: file, gzip: gzipWriter, tar: tar.NewWriter(gzipWriter)}, nil
+	}
+
+	if err := os.MkdirAll(bundlePath, 0755); err != nil {
+		return nil, err
+	}
+	return &dirBundleWriter{dir: bundlePath}, nil
+}
+
+type dirBundleWriter struct {
+	dir string
+}
+
+func (w *dirBundleWriter) WriteFile(name string, data []byte) error {
+	filePath := filepath.Join(w.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
+
+func (w *dirBundleWriter) Close() error {
+	return nil
+}
+
+type tarGzBundleWriter struct {
+	file *os.File
+	gzip *gzip.Writer
+	tar  *tar.Writer
+}
+
+func (w *tarGzBundleWriter) WriteFile(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := w.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := w.tar.Write(data)
+	return err
+}
+
+func (w *tarGzBundleWriter) Close() error {
+	if err := w.tar.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.gzip.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// writeDiagnosticsBundle collects the diagnostics bundle of every resource, which has not become ready by the time
+// tracking has failed. Errors are displayed and do not affect the result of tracking.
+func (mt *multitracker) writeDiagnosticsBundle(kube kubernetes.Interface, dynamicClient dynamic.Interface, bundlePath string, report MultitrackReport) {
+	genericGroupVersionResources := make(map[string]schema.GroupVersionResource)
+	mt.mux.Lock()
+	for _, res := range mt.GenericResources {
+		genericGroupVersionResources[res.key()] = res.GroupVersionResource
+	}
+	mt.mux.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsBundleTimeout)
+	defer cancel()
+
+	err := func() error {
+		writer, err := newDiagnosticsBundleWriter(bundlePath)
+		if err != nil {
+			return err
+		}
+
+		collector := &diagnosticsCollector{kube: kube, dynamicClient: dynamicClient, writer: writer}
+		for _, res := range report.Resources {
+			if res.Status == formatReportResourceStatus(resourceSucceeded) {
+				continue
+			}
+
+			gvr, isGeneric := genericGroupVersionResources[fmt.Sprintf("%s/%s", res.Kind, res.Name)]
+			if res.Kind == "canary" {
+				gvr, isGeneric = canaryGroupVersionResource, true
+			}
+
+			if err := collector.collectResource(ctx, res, gvr, isGeneric); err != nil {
+				writer.Close()
+				return err
+			}
+		}
+
+		return writer.Close()
+	}()
+
+	mt.mux.Lock()
+	defer mt.mux.Unlock()
+
+	if err != nil {
+		mt.displayMultitrackServiceMessageF("Unable to write diagnostics bundle %s: %s\n", bundlePath, err)
+		return
+	}
+	mt.displayMultitrackServiceMessageF("Diagnostics bundle has been written to %s\n", bundlePath)
+}
+
+type diagnosticsCollector struct {
+	kube          kubernetes.Interface
+	dynamicClient dynamic.Interface
+	writer        diagnosticsBundleWriter
+}
+
+// diagnosticsResource accumulates the files of the resource directory in the bundle.
+type diagnosticsResource struct {
+	dir        string
+	conditions []string
+	events     []string
+	nodes      []string
+	errors     []string
+}
+
+func (res *diagnosticsResource) addError(format string, a ...interface{}) {
+	res.errors = append(res.errors, fmt.Sprintf(format, a...))
+}
+
+// collectResource writes the resource directory <namespace>/<kind>-<name>: report, object, owned replicasets and pods,
+// conditions, logs of failing containers, events and conditions of the pods nodes. Only write errors are returned,
+// errors of the kubernetes api are saved into errors.txt of the resource directory.
+func (c *diagnosticsCollector) collectResource(ctx context.Context, report ResourceReport, gvr schema.GroupVersionResource, isGeneric bool) error {
+	res := &diagnosticsResource{dir: path.Join(report.Namespace, fmt.Sprintf("%s-%s", report.Kind, report.Name))}
+
+	if err := c.writeYaml(res, "report.yaml", report); err != nil {
+		return err
+	}
+
+	obj, selector, err := c.getObject(ctx, report, gvr, isGeneric)
+	if err != nil {
+		res.addError("get %s/%s: %s", report.Kind, report.Name, err)
+		return c.writeResourceSummary(res)
+	}
+
+	if err := c.writeYaml(res, "object.yaml", obj); err != nil {
+		return err
+	}
+	c.addObjectDiagnostics(ctx, res, fmt.Sprintf("%s/%s", report.Kind, report.Name), obj)
+
+	if selector != nil {
+		if err := c.collectPods(ctx, res, report.Kind, obj, selector); err != nil {
+			return err
+		}
+	}
+
+	return c.writeResourceSummary(res)
+}
+
+// getObject returns the live object and the selector of its pods, selector is nil for resources without pods.
+func (c *diagnosticsCollector) getObject(ctx context.Context, report ResourceReport, gvr schema.GroupVersionResource, isGeneric bool) (runtime.Object, *metav1.LabelSelector, error) {
+	switch {
+	case isGeneric:
+		if c.dynamicClient == nil {
+			return nil, nil, fmt.Errorf("dynamic client is required")
+		}
+		obj, err := c.dynamicClient.Resource(gvr).Namespace(report.Namespace).Get(ctx, report.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return obj, nil, nil
+	case report.Kind == "deploy":
+		obj, err := c.kube.AppsV1().Deployments(report.Namespace).Get(ctx, report.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		obj.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+		return obj, obj.Spec.Selector, nil
+	case report.Kind == "sts":
+		obj, err := c.kube.AppsV1().StatefulSets(report.Namespace).Get(ctx, report.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		obj.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"}
+		return obj, obj.Spec.Selector, nil
+	case report.Kind == "ds":
+		obj, err := c.kube.AppsV1().DaemonSets(report.Namespace).Get(ctx, report.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		obj.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"}
+		return obj, obj.Spec.Selector, nil
+	case report.Kind == "job":
+		obj, err := c.kube.BatchV1().Jobs(report.Namespace).Get(ctx, report.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		obj.TypeMeta = metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"}
+		return obj, obj.Spec.Selector, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown resource kind %q", report.Kind)
+	}
+}
+
+// collectPods writes pods owned by the object, for deployments pods are owned by the deployment replicasets.
+func (c *diagnosticsCollector) collectPods(ctx context.Context, res *diagnosticsResource, kind string, obj runtime.Object, labelSelector *metav1.LabelSelector) error {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		res.addError("%s", err)
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		res.addError("pods selector: %s", err)
+		return nil
+	}
+	listOpts := metav1.ListOptions{LabelSelector: selector.String()}
+
+	owners := map[types.UID]bool{objMeta.GetUID(): true}
+
+	if kind == "deploy" {
+		rsList, err := c.kube.AppsV1().ReplicaSets(objMeta.GetNamespace()).List(ctx, listOpts)
+		if err != nil {
+			res.addError("list replicasets: %s", err)
+		} else {
+			replicaSets := make([]appsv1.ReplicaSet, 0)
+			for _, rs := range rsList.Items {
+				if ref := utils.GetControllerOf(&rs); ref != nil && ref.UID == objMeta.GetUID() {
+					replicaSets = append(replicaSets, rs)
+				}
+			}
+			sort.Slice(replicaSets, func(i, j int) bool { return replicaSets[i].Name < replicaSets[j].Name })
+
+			owners = make(map[types.UID]bool)
+			for i := range replicaSets {
+				rs := &replicaSets[i]
+				owners[rs.UID] = true
+
+				rs.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"}
+				if err := c.writeYaml(res, fmt.Sprintf("replicasets/%s.yaml", rs.Name), rs); err != nil {
+					return err
+				}
+				c.addObjectDiagnostics(ctx, res, fmt.Sprintf("rs/%s", rs.Name), rs)
+			}
+		}
+	}
+
+	podList, err := c.kube.CoreV1().Pods(objMeta.GetNamespace()).List(ctx, listOpts)
+	if err != nil {
+		res.addError("list pods: %s", err)
+		return nil
+	}
+
+	pods := make([]corev1.Pod, 0)
+	for _, pod := range podList.Items {
+		if ref := utils.GetControllerOf(&pod); ref != nil && owners[ref.UID] {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	nodesNames := make(map[string]bool)
+	for i := range pods {
+		pod := &pods[i]
+
+		pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+		if err := c.writeYaml(res, fmt.Sprintf("pods/%s.yaml", pod.Name), pod); err != nil {
+			return err
+		}
+		c.addObjectDiagnostics(ctx, res, fmt.Sprintf("po/%s", pod.Name), pod)
+		res.conditions = append(res.conditions, formatContainersStates(pod)...)
+
+		if err := c.collectFailingContainersLogs(ctx, res, pod); err != nil {
+			return err
+		}
+
+		if pod.Spec.NodeName != "" {
+			nodesNames[pod.Spec.NodeName] = true
+		}
+	}
+
+	c.addNodesConditions(ctx, res, nodesNames)
+
+	return nil
+}
+
+// collectFailingContainersLogs writes logs of restarted, failed and not ready containers of the pod,
+// logs of the previous instance are written for the restarted containers.
+func (c *diagnosticsCollector) collectFailingContainersLogs(ctx context.Context, res *diagnosticsResource, pod *corev1.Pod) error {
+	allContainerStatuses := make([]corev1.ContainerStatus, 0)
+	allContainerStatuses = append(allContainerStatuses, pod.Status.InitContainerStatuses...)
+	allContainerStatuses = append(allContainerStatuses, pod.Status.ContainerStatuses...)
+
+	for _, cs := range allContainerStatuses {
+		if !isContainerFailing(cs) {
+			continue
+		}
+
+		instances := []bool{false}
+		if cs.RestartCount > 0 {
+			instances = append(instances, true)
+		}
+
+		for _, previous := range instances {
+			name := fmt.Sprintf("pods/%s/%s.log", pod.Name, cs.Name)
+			if previous {
+				name = fmt.Sprintf("pods/%s/%s.previous.log", pod.Name, cs.Name)
+			}
+
+			limitBytes := int64(diagnosticsLogsLimitBytes)
+			logOpts := &corev1.PodLogOptions{
+				Container:  cs.Name,
+				Previous:   previous,
+				Timestamps: true,
+				LimitBytes: &limitBytes,
+			}
+
+			data, err := c.kube.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOpts).DoRaw(ctx)
+			if err != nil {
+				res.addError("get logs %s: %s", name, err)
+				continue
+			}
+
+			if err := c.writer.WriteFile(path.Join(res.dir, name), data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func isContainerFailing(cs corev1.ContainerStatus) bool {
+	switch {
+	case cs.RestartCount > 0:
+		return true
+	case cs.State.Terminated != nil:
+		return cs.State.Terminated.ExitCode != 0
+	case cs.State.Running != nil:
+		return !cs.Ready
+	default:
+		return false
+	}
+}
+
+// addObjectDiagnostics adds conditions and events of the object.
+func (c *diagnosticsCollector) addObjectDiagnostics(ctx context.Context, res *diagnosticsResource, name string, obj runtime.Object) {
+	res.conditions = append(res.conditions, fmt.Sprintf("%s:", name))
+	res.conditions = append(res.conditions, formatObjectConditions(obj)...)
+
+	res.events = append(res.events, fmt.Sprintf("%s:", name))
+	eventList, err := utils.ListEventsForObject(ctx, c.kube, obj)
+	if err != nil {
+		res.addError("list %s events: %s", name, err)
+		return
+	}
+
+	sort.Sort(utils.SortableEvents(eventList.Items))
+	for _, event := range eventList.Items {
+		line := fmt.Sprintf("  %s %s %s (x%d)", event.LastTimestamp.UTC().Format(time.RFC3339), event.Type, event.Reason, event.Count)
+		if source := utils.FormatEventSource(event.Source); source != "" {
+			line += fmt.Sprintf(" from %s", source)
+		}
+		res.events = append(res.events, fmt.Sprintf("%s: %s", line, strings.TrimSpace(event.Message)))
+	}
+}
+
+func (c *diagnosticsCollector) addNodesConditions(ctx context.Context, res *diagnosticsResource, nodesNames map[string]bool) {
+	names := make([]string, 0, len(nodesNames))
+	for name := range nodesNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		node, err := c.kube.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			res.addError("get node/%s: %s", name, err)
+			continue
+		}
+
+		res.nodes = append(res.nodes, fmt.Sprintf("node/%s:", name))
+		res.nodes = append(res.nodes, formatObjectConditions(node)...)
+		for _, taint := range node.Spec.Taints {
+			res.nodes = append(res.nodes, fmt.Sprintf("  taint %s", taint.ToString()))
+		}
+	}
+}
+
+// formatObjectConditions returns status.conditions of any object as "  Type=Status Reason: Message" lines.
+func formatObjectConditions(obj runtime.Object) []string {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return []string{fmt.Sprintf("  unable to get conditions: %s", err)}
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(content, "status", "conditions")
+
+	var lines []string
+	for _, elem := range conditions {
+		condition, ok := elem.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		line := fmt.Sprintf("  %v=%v", condition["type"], condition["status"])
+		if reason, ok := condition["reason"].(string); ok && reason != "" {
+			line += fmt.Sprintf(" %s", reason)
+		}
+		if message, ok := condition["message"].(string); ok && message != "" {
+			line += fmt.Sprintf(": %s", strings.TrimSpace(message))
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func formatContainersStates(pod *corev1.Pod) []string {
+	allContainerStatuses := make([]corev1.ContainerStatus, 0)
+	allContainerStatuses = append(allContainerStatuses, pod.Status.InitContainerStatuses...)
+	allContainerStatuses = append(allContainerStatuses, pod.Status.ContainerStatuses...)
+
+	var lines []string
+	for _, cs := range allContainerStatuses {
+		var state string
+		switch {
+		case cs.State.Waiting != nil:
+			state = strings.TrimSpace(fmt.Sprintf("waiting %s %s", cs.State.Waiting.Reason, cs.State.Waiting.Message))
+		case cs.State.Running != nil:
+			state = "running"
+		case cs.State.Terminated != nil:
+			state = fmt.Sprintf("terminated %s, exit code %d", cs.State.Terminated.Reason, cs.State.Terminated.ExitCode)
+		}
+
+		line := fmt.Sprintf("  container/%s: %s, ready %t, restarts %d", cs.Name, state, cs.Ready, cs.RestartCount)
+		if terminated := cs.LastTerminationState.Terminated; terminated != nil {
+			line += fmt.Sprintf(", last terminated %s with exit code %d", terminated.Reason, terminated.ExitCode)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func (c *diagnosticsCollector) writeYaml(res *diagnosticsResource, name string, obj interface{}) error {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		res.addError("marshal %s: %s", name, err)
+		return nil
+	}
+	return c.writer.WriteFile(path.Join(res.dir, name), data)
+}
+
+func (c *diagnosticsCollector) writeResourceSummary(res *diagnosticsResource) error {
+	for _, desc := range []struct {
+		Name  string
+		Lines []string
+	}{
+		{"conditions.txt", res.conditions},
+		{"events.txt", res.events},
+		{"nodes.txt", res.nodes},
+		{"errors.txt", res.errors},
+	} {
+		if len(desc.Lines) == 0 {
+			continue
+		}
+		if err := c.writer.WriteFile(path.Join(res.dir, desc.Name), []byte(strings.Join(desc.Lines, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package multitrack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/werf/kubedog/pkg/tracker/pod"
+)
+
+// FailureCategory groups failure types, which are handled the same way by the FailurePolicy.
+type FailureCategory string
+
+const (
+	// ProbeFailureCategory is failed readiness probes and containers restarted by liveness and startup probes.
+	ProbeFailureCategory FailureCategory = "Probe"
+	// CrashFailureCategory is crashed, OOM killed and not created containers.
+	CrashFailureCategory FailureCategory = "Crash"
+	// ImagePullFailureCategory is containers which image cannot be pulled.
+	ImagePullFailureCategory FailureCategory = "ImagePull"
+	// SchedulingFailureCategory is unschedulable and evicted pods.
+	SchedulingFailureCategory FailureCategory = "Scheduling"
+	// EventFailureCategory is failures reported by events and resources statuses, e.g. FailedCreate event
+	// of the Deployment, FailedMount event of the pod or BackoffLimitExceeded condition of the Job.
+	EventFailureCategory FailureCategory = "Event"
+)
+
+var failureCategories = []FailureCategory{ProbeFailureCategory, CrashFailureCategory, ImagePullFailureCategory, SchedulingFailureCategory, EventFailureCategory}
+
+// FailureAction defines how the failure of the category is handled.
+type FailureAction string
+
+const (
+	// IgnoreFailureAction displays the failure and continues tracking.
+	IgnoreFailureAction FailureAction = "Ignore"
+	// CountFailureAction handles the failure according to FailMode and AllowFailuresCount, which is the default.
+	CountFailureAction FailureAction = "Count"
+	// FailImmediatelyFailureAction fails the whole deploy process on the first failure regardless of AllowFailuresCount.
+	FailImmediatelyFailureAction FailureAction = "FailImmediately"
+	// HopeUntilEndOfDeployProcessFailureAction handles the failure as with HopeUntilEndOfDeployProcess FailMode.
+	HopeUntilEndOfDeployProcessFailureAction FailureAction = "HopeUntilEndOfDeployProcess"
+)
+
+var failureActions = []FailureAction{IgnoreFailureAction, CountFailureAction, FailImmediatelyFailureAction, HopeUntilEndOfDeployProcessFailureAction}
+
+// FailurePolicy maps failure categories to actions, failures of categories not in the policy are counted.
+type FailurePolicy map[FailureCategory]FailureAction
+
+// GetFailureCategory returns the category of the failure type.
+func GetFailureCategory(failureType pod.FailureType) FailureCategory {
+	switch failureType {
+	case pod.ProbeFailure:
+		return ProbeFailureCategory
+	case pod.CrashLoopBackOffFailure, pod.OOMKilledFailure, pod.CreateContainerConfigFailure:
+		return CrashFailureCategory
+	case pod.ImagePullFailure:
+		return ImagePullFailureCategory
+	case pod.UnschedulableFailure, pod.EvictedFailure:
+		return SchedulingFailureCategory
+	default:
+		return EventFailureCategory
+	}
+}
+
+func (policy FailurePolicy) getAction(failureType pod.FailureType) FailureAction {
+	if action, hasKey := policy[GetFailureCategory(failureType)]; hasKey {
+		return action
+	}
+	return CountFailureAction
+}
+
+func validateFailurePolicy(policy FailurePolicy) []string {
+	var errs []string
+
+	categories := make([]string, 0, len(policy))
+	for category := range policy {
+		categories = append(categories, string(category))
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		if err := validateFailureCategory(FailureCategory(category)); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if err := validateFailureAction(policy[FailureCategory(category)]); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", category, err))
+		}
+	}
+
+	return errs
+}
+
+func validateFailureCategory(category FailureCategory) error {
+	var expected []string
+	for _, knownCategory := range failureCategories {
+		if category == knownCategory {
+			return nil
+		}
+		expected = append(expected, string(knownCategory))
+	}
+	return fmt.Errorf("invalid failure category %q, expected one of: %s", category, strings.Join(expected, ", "))
+}
+
+func validateFailureAction(action FailureAction) error {
+	var expected []string
+	for _, knownAction := range failureActions {
+		if action == knownAction {
+			return nil
+		}
+		expected = append(expected, string(knownAction))
+	}
+	return fmt.Errorf("invalid value %q, expected one of: %s", action, strings.Join(expected, ", "))
+}
+
+// validateFailureHandling checks that CountedFailureTypes, FailurePolicy and FailOnProgressDeadline of the spec
+// do not contradict each other.
+func validateFailureHandling(spec MultitrackSpec) []string {
+	var errs []string
+
+	for i, failureType := range spec.CountedFailureTypes {
+		category := GetFailureCategory(failureType)
+		if spec.FailurePolicy[category] == IgnoreFailureAction {
+			errs = append(errs, fmt.Sprintf("CountedFailureTypes[%d]: %s failures are counted, but %s category is ignored by FailurePolicy", i, failureType, category))
+		}
+		if failureType == pod.ProgressDeadlineExceededFailure {
+			errs = append(errs, fmt.Sprintf("CountedFailureTypes[%d]: %s failures are not counted, they are handled according to FailOnProgressDeadline", i, failureType))
+		}
+	}
+
+	if len(spec.CountedFailureTypes) > 0 {
+		for _, category := range failureCategories {
+			if action, hasKey := spec.FailurePolicy[category]; !hasKey || action != CountFailureAction {
+				continue
+			}
+
+			isCounted := false
+			for _, failureType := range spec.CountedFailureTypes {
+				if GetFailureCategory(failureType) == category {
+					isCounted = true
+					break
+				}
+			}
+			if !isCounted {
+				errs = append(errs, fmt.Sprintf("FailurePolicy: %s category is counted, but none of its failure types is in CountedFailureTypes", category))
+			}
+		}
+	}
+
+	return errs
+}
+
+// parseFailurePolicy parses the policy in the CATEGORY=ACTION[,CATEGORY=ACTION...] format.
+func parseFailurePolicy(value string) (FailurePolicy, error) {
+	policy := FailurePolicy{}
+	for _, elem := range parseAnnotationList(value) {
+		parts := strings.SplitN(elem, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid policy %q, CATEGORY=ACTION expected", elem)
+		}
+		policy[FailureCategory(strings.TrimSpace(parts[0]))] = FailureAction(strings.TrimSpace(parts[1]))
+	}
+
+	if errs := validateFailurePolicy(policy); len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return policy, nil
+}
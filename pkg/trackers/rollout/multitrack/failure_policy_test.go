@@ -0,0 +1,120 @@
+package multitrack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/werf/kubedog/pkg/tracker/pod"
+)
+
+func TestParseFailurePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		value         string
+		expected      FailurePolicy
+		expectedError string
+	}{
+		{
+			name:     "empty policy",
+			value:    "",
+			expected: FailurePolicy{},
+		},
+		{
+			name:  "several categories with spaces",
+			value: "Probe=Ignore, ImagePull = FailImmediately",
+			expected: FailurePolicy{
+				ProbeFailureCategory:     IgnoreFailureAction,
+				ImagePullFailureCategory: FailImmediatelyFailureAction,
+			},
+		},
+		{
+			name:          "missing action",
+			value:         "Probe",
+			expectedError: `invalid policy "Probe", CATEGORY=ACTION expected`,
+		},
+		{
+			name:          "unknown category",
+			value:         "Network=Ignore",
+			expectedError: `invalid failure category "Network"`,
+		},
+		{
+			name:          "unknown action",
+			value:         "Crash=Retry",
+			expectedError: `Crash: invalid value "Retry"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := parseFailurePolicy(tc.value)
+
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(policy, tc.expected) {
+				t.Errorf("expected policy %v, got %v", tc.expected, policy)
+			}
+		})
+	}
+}
+
+func TestValidateFailureHandling(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		spec           MultitrackSpec
+		expectedErrors []string
+	}{
+		{
+			name: "empty spec",
+			spec: MultitrackSpec{},
+		},
+		{
+			name: "counted types of counted categories",
+			spec: MultitrackSpec{
+				CountedFailureTypes: []pod.FailureType{pod.CrashLoopBackOffFailure},
+				FailurePolicy:       FailurePolicy{CrashFailureCategory: CountFailureAction, ProbeFailureCategory: IgnoreFailureAction},
+			},
+		},
+		{
+			name: "counted type of ignored category",
+			spec: MultitrackSpec{
+				CountedFailureTypes: []pod.FailureType{pod.ProbeFailure},
+				FailurePolicy:       FailurePolicy{ProbeFailureCategory: IgnoreFailureAction},
+			},
+			expectedErrors: []string{"CountedFailureTypes[0]: ProbeFailure failures are counted, but Probe category is ignored by FailurePolicy"},
+		},
+		{
+			name: "counted progress deadline",
+			spec: MultitrackSpec{
+				CountedFailureTypes: []pod.FailureType{pod.OOMKilledFailure, pod.ProgressDeadlineExceededFailure},
+			},
+			expectedErrors: []string{"CountedFailureTypes[1]: ProgressDeadlineExceeded failures are not counted, they are handled according to FailOnProgressDeadline"},
+		},
+		{
+			name: "counted category without counted types",
+			spec: MultitrackSpec{
+				CountedFailureTypes: []pod.FailureType{pod.CrashLoopBackOffFailure},
+				FailurePolicy:       FailurePolicy{ImagePullFailureCategory: CountFailureAction},
+			},
+			expectedErrors: []string{"FailurePolicy: ImagePull category is counted, but none of its failure types is in CountedFailureTypes"},
+		},
+		{
+			name: "counted category without CountedFailureTypes",
+			spec: MultitrackSpec{
+				FailurePolicy: FailurePolicy{ImagePullFailureCategory: CountFailureAction},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if errs := validateFailureHandling(tc.spec); !reflect.DeepEqual(errs, tc.expectedErrors) {
+				t.Errorf("expected errors %q, got %q", tc.expectedErrors, errs)
+			}
+		})
+	}
+}
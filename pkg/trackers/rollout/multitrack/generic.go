@@ -0,0 +1,173 @@
+package multitrack
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/werf/kubedog/pkg/display"
+	"github.com/werf/kubedog/pkg/tracker/generic"
+)
+
+// MultitrackGenericSpec describes arbitrary resource (Service, PersistentVolumeClaim, Ingress, custom resource, etc.),
+// which readiness is judged by the resource status: status.conditions (Ready, Available, Reconciling, Stalled),
+// status.observedGeneration and the rules for the well-known kinds.
+type MultitrackGenericSpec struct {
+	MultitrackSpec
+
+	// GroupVersionResource of the resource, e.g. {Group: cert-manager.io, Version: v1, Resource: certificates}.
+	GroupVersionResource *schema.GroupVersionResource
+	// Kind of the resource is used when GroupVersionResource is not set and is mapped to the resource
+	// with the discovery API, e.g. Service, Ingress.networking.k8s.io or Certificate.v1.cert-manager.io.
+	Kind string
+}
+
+type multitrackGenericResource struct {
+	// Kind is the resource kind in the kubectl format, e.g. certificates.cert-manager.io.
+	Kind                 string
+	GroupVersionResource schema.GroupVersionResource
+	Spec                 MultitrackSpec
+}
+
+func (res multitrackGenericResource) key() string {
+	return fmt.Sprintf("%s/%s", res.Kind, res.Spec.ResourceName)
+}
+
+func resolveGenericResources(kube kubernetes.Interface, dynamicClient dynamic.Interface, specs []MultitrackGenericSpec) ([]multitrackGenericResource, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	if dynamicClient == nil {
+		return nil, fmt.Errorf("MultitrackOptions.DynamicClient is required to track generic resources")
+	}
+
+	var mapper meta.RESTMapper
+	var res []multitrackGenericResource
+
+	for _, spec := range specs {
+		var groupVersionResource schema.GroupVersionResource
+
+		if spec.GroupVersionResource != nil {
+			groupVersionResource = *spec.GroupVersionResource
+		} else {
+			if mapper == nil {
+				groupResources, err := restmapper.GetAPIGroupResources(kube.Discovery())
+				if err != nil {
+					return nil, fmt.Errorf("unable to discover api resources: %s", err)
+				}
+				mapper = restmapper.NewDiscoveryRESTMapper(groupResources)
+			}
+
+			var err error
+			groupVersionResource, err = mapKindToResource(mapper, spec.Kind)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, multitrackGenericResource{
+			Kind:                 generic.FormatResourceKind(groupVersionResource),
+			GroupVersionResource: groupVersionResource,
+			Spec:                 spec.MultitrackSpec,
+		})
+	}
+
+	return res, nil
+}
+
+func mapKindToResource(mapper meta.RESTMapper, kind string) (schema.GroupVersionResource, error) {
+	fullySpecifiedGVK, groupKind := schema.ParseKindArg(kind)
+	if fullySpecifiedGVK != nil {
+		if mapping, err := mapper.RESTMapping(fullySpecifiedGVK.GroupKind(), fullySpecifiedGVK.Version); err == nil {
+			return mapping.Resource, nil
+		}
+	}
+
+	mapping, err := mapper.RESTMapping(groupKind)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("unable to map kind %q to resource: %s", kind, err)
+	}
+	return mapping.Resource, nil
+}
+
+func (mt *multitracker) TrackGeneric(kube kubernetes.Interface, dynamicClient dynamic.Interface, resource multitrackGenericResource, spec MultitrackSpec, opts MultitrackOptions) error {
+	feed := generic.NewFeed()
+	key := resource.key()
+
+	feed.OnAdded(func() error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.GenericStatuses[key] = feed.GetStatus()
+
+		return mt.genericAdded(resource, spec, feed)
+	})
+	feed.OnReady(func() error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.GenericStatuses[key] = feed.GetStatus()
+
+		return mt.genericReady(resource, spec, feed)
+	})
+	feed.OnFailed(func(reason string) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.GenericStatuses[key] = feed.GetStatus()
+
+		return mt.genericFailed(resource, spec, feed, reason)
+	})
+	feed.OnEventMsg(func(msg string) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		return mt.genericEventMsg(resource, spec, feed, msg)
+	})
+	feed.OnServiceMsg(func(msg string) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.displayResourceTrackerMessageF(resource.Kind, spec, "%s", msg)
+		return nil
+	})
+
+	feed.OnStatus(func(status generic.ResourceStatus) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.GenericStatuses[key] = status
+
+		return nil
+	})
+
+	return feed.Track(spec.ResourceName, spec.Namespace, resource.GroupVersionResource, kube, dynamicClient, opts.Options)
+}
+
+func (mt *multitracker) genericAdded(resource multitrackGenericResource, spec MultitrackSpec, feed generic.Feed) error {
+	mt.displayResourceStateMessageF(display.EventAdded, resource.Kind, spec, "added")
+
+	return nil
+}
+
+func (mt *multitracker) genericReady(resource multitrackGenericResource, spec MultitrackSpec, feed generic.Feed) error {
+	mt.displayResourceStateMessageF(display.EventReady, resource.Kind, spec, "appears to be READY")
+
+	return mt.handleResourceReadyCondition(mt.TrackingGenerics[resource.key()])
+}
+
+func (mt *multitracker) genericFailed(resource multitrackGenericResource, spec MultitrackSpec, feed generic.Feed, reason string) error {
+	mt.displayResourceErrorF(resource.Kind, spec, "%s", reason)
+
+	return mt.handleResourceFailure(mt.TrackingGenerics[resource.key()], resource.Kind, spec, getFailureTypeByReason(reason), reason)
+}
+
+func (mt *multitracker) genericEventMsg(resource multitrackGenericResource, spec MultitrackSpec, feed generic.Feed, msg string) error {
+	mt.displayResourceEventF(resource.Kind, spec, "%s", msg)
+	return nil
+}
@@ -1,10 +1,9 @@
 package multitrack
 
 import (
-	"fmt"
-
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/werf/kubedog/pkg/display"
 	"github.com/werf/kubedog/pkg/tracker/job"
 	"github.com/werf/kubedog/pkg/tracker/pod"
 )
@@ -44,6 +43,13 @@ func (mt *multitracker) TrackJob(kube kubernetes.Interface, spec MultitrackSpec,
 
 		return mt.jobEventMsg(spec, feed, msg)
 	})
+	feed.OnServiceMsg(func(msg string) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.displayResourceTrackerMessageF("job", spec, "%s", msg)
+		return nil
+	})
 	feed.OnAddedPod(func(podName string) error {
 		mt.mux.Lock()
 		defer mt.mux.Unlock()
@@ -81,20 +87,20 @@ func (mt *multitracker) TrackJob(kube kubernetes.Interface, spec MultitrackSpec,
 }
 
 func (mt *multitracker) jobAdded(spec MultitrackSpec, feed job.Feed) error {
-	mt.displayResourceTrackerMessageF("job", spec, "added")
+	mt.displayResourceStateMessageF(display.EventAdded, "job", spec, "added")
 
 	return nil
 }
 
 func (mt *multitracker) jobSucceeded(spec MultitrackSpec, feed job.Feed) error {
-	mt.displayResourceTrackerMessageF("job", spec, "succeeded")
+	mt.displayResourceStateMessageF(display.EventSucceeded, "job", spec, "succeeded")
 
-	return mt.handleResourceReadyCondition(mt.TrackingJobs, spec)
+	return mt.handleResourceReadyCondition(mt.TrackingJobs[spec.ResourceName])
 }
 
 func (mt *multitracker) jobFailed(spec MultitrackSpec, feed job.Feed, reason string) error {
 	mt.displayResourceErrorF("job", spec, "%s", reason)
-	return mt.handleResourceFailure(mt.TrackingJobs, "job", spec, reason)
+	return mt.handleResourceFailure(mt.TrackingJobs[spec.ResourceName], "job", spec, getFailureTypeByReason(reason), reason)
 }
 
 func (mt *multitracker) jobEventMsg(spec MultitrackSpec, feed job.Feed, msg string) error {
@@ -108,14 +114,10 @@ func (mt *multitracker) jobAddedPod(spec MultitrackSpec, feed job.Feed, podName
 }
 
 func (mt *multitracker) jobPodLogChunk(spec MultitrackSpec, feed job.Feed, chunk *pod.PodLogChunk) error {
-	mt.displayResourceLogChunk("job", spec, podContainerLogChunkHeader(chunk.PodName, chunk.ContainerLogChunk), chunk.ContainerLogChunk)
+	mt.displayResourceLogChunk("job", spec, chunk.PodName, chunk.ContainerLogChunk)
 	return nil
 }
 
 func (mt *multitracker) jobPodError(spec MultitrackSpec, feed job.Feed, podError pod.PodError) error {
-	reason := fmt.Sprintf("po/%s container/%s: %s", podError.PodName, podError.ContainerName, podError.Message)
-
-	mt.displayResourceErrorF("job", spec, "%s", reason)
-
-	return mt.handleResourceFailure(mt.TrackingJobs, "job", spec, reason)
+	return mt.handlePodError(mt.TrackingJobs[spec.ResourceName], "job", spec, podError)
 }
@@ -0,0 +1,88 @@
+package multitrack
+
+import (
+	"io"
+	"strings"
+
+	"github.com/werf/kubedog/pkg/display"
+)
+
+// NewJSONReporter returns multitrack Reporter, which writes
+// the tracking stream into w as one JSON object per line.
+func NewJSONReporter(w io.Writer) Reporter {
+	return &jsonReporter{w: w}
+}
+
+type jsonReporter struct {
+	w io.Writer
+
+	// cluster is written into the events when not empty
+	cluster string
+}
+
+func (r *jsonReporter) ForCluster(cluster string) Reporter {
+	return &jsonReporter{w: r.w, cluster: cluster}
+}
+
+func (r *jsonReporter) ResourceMessage(msgType display.EventType, resourceKind string, spec MultitrackSpec, msg string) {
+	r.writeResourceEvent(msgType, resourceKind, spec, display.MessagePayload{Message: msg})
+}
+
+func (r *jsonReporter) ResourceEvent(resourceKind string, spec MultitrackSpec, msg string) {
+	r.writeResourceEvent(display.EventMessage, resourceKind, spec, display.MessagePayload{Message: msg})
+}
+
+func (r *jsonReporter) ResourceError(resourceKind string, spec MultitrackSpec, reason string) {
+	r.writeResourceEvent(display.EventFailed, resourceKind, spec, display.MessagePayload{Message: reason})
+}
+
+func (r *jsonReporter) ResourceLogChunk(resourceKind string, spec MultitrackSpec, podName, containerName string, lines []display.LogLine) {
+	for _, line := range lines {
+		r.writeResourceEvent(display.EventLogLine, resourceKind, spec, display.LogLinePayload{
+			Pod:       podName,
+			Container: containerName,
+			Timestamp: line.Timestamp,
+			Message:   line.Message,
+		})
+	}
+}
+
+func (r *jsonReporter) MultitrackMessage(msg string) {
+	event := display.NewEvent(display.EventServiceMessage, "", "", "", display.MessagePayload{Message: strings.TrimSuffix(msg, "\n")})
+	event.Cluster = r.cluster
+	_ = display.WriteEvent(r.w, event)
+}
+
+func (r *jsonReporter) StatusProgress(progress StatusProgress) {
+	for _, p := range progress.Deployments {
+		r.writeResourceEvent(display.EventStatus, "deploy", p.Spec, p.Status)
+	}
+	for _, p := range progress.StatefulSets {
+		r.writeResourceEvent(display.EventStatus, "sts", p.Spec, p.Status)
+	}
+	for _, p := range progress.DaemonSets {
+		r.writeResourceEvent(display.EventStatus, "ds", p.Spec, p.Status)
+	}
+	for _, p := range progress.Jobs {
+		r.writeResourceEvent(display.EventStatus, "job", p.Spec, p.Status)
+	}
+	for _, p := range progress.Canaries {
+		r.writeResourceEvent(display.EventStatus, "canary", p.Spec, p.Status)
+	}
+	for _, p := range progress.Generics {
+		r.writeResourceEvent(display.EventStatus, p.Kind, p.Spec, p.Status)
+	}
+	for _, p := range progress.Waiting {
+		r.writeResourceEvent(display.EventStatus, p.Kind, p.Spec, display.WaitingPayload{WaitingFor: p.WaitingFor})
+	}
+}
+
+func (r *jsonReporter) TrackingFinished(results []ResourceResult) {
+	// All resources messages have already been written as separate events
+}
+
+func (r *jsonReporter) writeResourceEvent(eventType display.EventType, resourceKind string, spec MultitrackSpec, payload interface{}) {
+	event := display.NewEvent(eventType, resourceKind, spec.Namespace, spec.ResourceName, payload)
+	event.Cluster = r.cluster
+	_ = display.WriteEvent(r.w, event)
+}
@@ -0,0 +1,230 @@
+package multitrack
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitSuiteName struct {
+	Kind  string
+	Suite string
+}
+
+var junitSuitesNamesByKind = []junitSuiteName{
+	{"deploy", "Deployments"},
+	{"sts", "StatefulSets"},
+	{"ds", "DaemonSets"},
+	{"job", "Jobs"},
+	{"canary", "Canaries"},
+}
+
+// WriteJUnit writes the report in JUnit XML format: every tracked resource is a test case
+// and every resource kind is a test suite.
+func (report MultitrackReport) WriteJUnit(w io.Writer) error {
+	suites := junitTestSuites{
+		Name: "kubedog multitrack",
+		Time: formatJUnitSeconds(report.FinishedAt.Sub(report.StartedAt).Seconds()),
+	}
+
+	for _, desc := range report.getJUnitSuitesNamesByKind() {
+		suite := junitTestSuite{
+			Name:      desc.Suite,
+			Timestamp: report.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+		}
+
+		var suiteSeconds float64
+		for _, res := range report.Resources {
+			if res.Kind != desc.Kind {
+				continue
+			}
+
+			testCase := newJUnitTestCase(desc.Suite, res)
+			switch {
+			case testCase.Failure != nil:
+				suite.Failures++
+			case testCase.Skipped != nil:
+				suite.Skipped++
+			}
+			if res.DurationSeconds > suiteSeconds {
+				suiteSeconds = res.DurationSeconds
+			}
+
+			suite.Tests++
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		if suite.Tests == 0 {
+			continue
+		}
+		suite.Time = formatJUnitSeconds(suiteSeconds)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// getJUnitSuitesNamesByKind returns the predefined suites followed by the suites of generic resources kinds.
+func (report MultitrackReport) getJUnitSuitesNamesByKind() []junitSuiteName {
+	res := append([]junitSuiteName{}, junitSuitesNamesByKind...)
+
+	var genericKinds []string
+	for _, resource := range report.Resources {
+		isKnown := false
+		for _, desc := range res {
+			if desc.Kind == resource.Kind {
+				isKnown = true
+			}
+		}
+		for _, kind := range genericKinds {
+			if kind == resource.Kind {
+				isKnown = true
+			}
+		}
+
+		if !isKnown {
+			genericKinds = append(genericKinds, resource.Kind)
+		}
+	}
+	sort.Strings(genericKinds)
+
+	for _, kind := range genericKinds {
+		res = append(res, junitSuiteName{Kind: kind, Suite: kind})
+	}
+
+	return res
+}
+
+func newJUnitTestCase(suiteName string, res ResourceReport) junitTestCase {
+	name := res.Name
+	if res.Namespace != "" {
+		name = fmt.Sprintf("%s/%s", res.Namespace, res.Name)
+	}
+	if res.Cluster != "" {
+		name = fmt.Sprintf("[%s] %s", res.Cluster, name)
+	}
+
+	testCase := junitTestCase{
+		Name:      name,
+		ClassName: suiteName,
+		Time:      formatJUnitSeconds(res.DurationSeconds),
+	}
+
+	switch res.Status {
+	case formatReportResourceStatus(resourceFailed):
+		failureType := "ResourceFailed"
+		if strings.HasPrefix(res.FailedReason, TimeoutReason+":") || strings.HasPrefix(res.FailedReason, NoProgressReason+":") {
+			failureType = "ResourceTimedOut"
+		}
+
+		testCase.Failure = &junitFailure{
+			Message:  res.FailedReason,
+			Type:     failureType,
+			Contents: formatJUnitRollback(res.Rollback) + formatJUnitUnschedulablePods(res.UnschedulablePods) + formatJUnitFailedContainersLogs(res.FailedContainersLogs),
+		}
+	case formatReportResourceStatus(resourceSucceeded):
+	default:
+		testCase.Skipped = &junitSkipped{Message: fmt.Sprintf("tracking finished with resource status %q", res.Status)}
+		if len(res.UnschedulablePods) > 0 {
+			testCase.Skipped.Message += "\n" + strings.TrimSuffix(formatJUnitUnschedulablePods(res.UnschedulablePods), "\n")
+		}
+	}
+
+	if len(res.Events) > 0 {
+		testCase.SystemOut = strings.Join(res.Events, "\n")
+	}
+
+	return testCase
+}
+
+func formatJUnitRollback(rollback *RollbackReport) string {
+	switch {
+	case rollback == nil:
+		return ""
+	case rollback.Status == rollbackSucceeded:
+		return fmt.Sprintf("rolled back to revision %d\n", rollback.Revision)
+	case rollback.Revision > 0:
+		return fmt.Sprintf("rollback to revision %d failed: %s\n", rollback.Revision, rollback.FailedReason)
+	default:
+		return fmt.Sprintf("rollback failed: %s\n", rollback.FailedReason)
+	}
+}
+
+func formatJUnitUnschedulablePods(pods []UnschedulablePodReport) string {
+	var b strings.Builder
+	for _, unschedulablePod := range pods {
+		fmt.Fprintf(&b, "po/%s is not scheduled: %s\n", unschedulablePod.Pod, unschedulablePod.Diagnosis)
+	}
+	return b.String()
+}
+
+func formatJUnitFailedContainersLogs(logs []ContainerLogReport) string {
+	var b strings.Builder
+	for _, containerLog := range logs {
+		fmt.Fprintf(&b, "po/%s container/%s logs:\n", containerLog.Pod, containerLog.Container)
+		for _, line := range containerLog.Lines {
+			fmt.Fprintf(&b, "%s\n", line)
+		}
+	}
+	return b.String()
+}
+
+func formatJUnitSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
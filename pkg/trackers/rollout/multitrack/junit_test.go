@@ -0,0 +1,133 @@
+package multitrack
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	startedAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	report := MultitrackReport{
+		StartedAt:  startedAt,
+		FinishedAt: startedAt.Add(90 * time.Second),
+		Resources: []ResourceReport{
+			{Kind: "job", Name: "migrate", Namespace: "ns", Status: "succeeded", DurationSeconds: 10},
+			{Kind: "deploy", Name: "app", Namespace: "ns", Status: "failed", FailedReason: "po/app-1 container/app: CrashLoopBackOff", DurationSeconds: 60,
+				Rollback:             &RollbackReport{Revision: 3, Status: rollbackSucceeded},
+				FailedContainersLogs: []ContainerLogReport{{Pod: "app-1", Container: "app", Lines: []string{"panic: boom"}}},
+				Events:               []string{"BackOff: Back-off restarting failed container"},
+			},
+			{Kind: "deploy", Name: "worker", Namespace: "ns", Status: "failed", FailedReason: TimeoutReason + ": not ready in 5m0s", DurationSeconds: 90},
+			{Kind: "deploy", Name: "cron", Namespace: "ns", Status: "active", DurationSeconds: 90,
+				UnschedulablePods: []UnschedulablePodReport{{Pod: "cron-1", Diagnosis: "insufficient memory"}},
+			},
+			{Kind: "services", Name: "app", Namespace: "ns", Status: "succeeded", DurationSeconds: 1},
+			{Kind: "certificates.cert-manager.io", Name: "tls", Namespace: "ns", Status: "succeeded", DurationSeconds: 30},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJUnit(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("expected XML header, got %q", buf.String())
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("cannot decode written report: %s", err)
+	}
+
+	if suites.Tests != 6 || suites.Failures != 2 || suites.Skipped != 1 || suites.Time != "90.000" {
+		t.Errorf("unexpected totals: tests=%d failures=%d skipped=%d time=%s", suites.Tests, suites.Failures, suites.Skipped, suites.Time)
+	}
+
+	var suitesNames []string
+	for _, suite := range suites.Suites {
+		suitesNames = append(suitesNames, suite.Name)
+	}
+	// Predefined kinds go first in the fixed order, then generic kinds sorted by name
+	if expected := []string{"Deployments", "Jobs", "certificates.cert-manager.io", "services"}; !reflect.DeepEqual(suitesNames, expected) {
+		t.Errorf("expected suites %v, got %v", expected, suitesNames)
+	}
+
+	deployments := suites.Suites[0]
+	if deployments.Tests != 3 || deployments.Failures != 2 || deployments.Skipped != 1 || deployments.Time != "90.000" {
+		t.Errorf("unexpected Deployments suite: %+v", deployments)
+	}
+
+	for _, tc := range []struct {
+		name             string
+		testCase         junitTestCase
+		expectedName     string
+		expectedFailure  *junitFailure
+		expectedSkipped  string
+		expectedOutput   string
+		expectedDuration string
+	}{
+		{
+			name:         "failed resource with rollback, logs and events",
+			testCase:     deployments.TestCases[0],
+			expectedName: "ns/app",
+			expectedFailure: &junitFailure{
+				Message:  "po/app-1 container/app: CrashLoopBackOff",
+				Type:     "ResourceFailed",
+				Contents: "rolled back to revision 3\npo/app-1 container/app logs:\npanic: boom\n",
+			},
+			expectedOutput:   "BackOff: Back-off restarting failed container",
+			expectedDuration: "60.000",
+		},
+		{
+			name:         "timed out resource",
+			testCase:     deployments.TestCases[1],
+			expectedName: "ns/worker",
+			expectedFailure: &junitFailure{
+				Message: TimeoutReason + ": not ready in 5m0s",
+				Type:    "ResourceTimedOut",
+			},
+			expectedDuration: "90.000",
+		},
+		{
+			name:             "not finished resource with unschedulable pods",
+			testCase:         deployments.TestCases[2],
+			expectedName:     "ns/cron",
+			expectedSkipped:  "tracking finished with resource status \"active\"\npo/cron-1 is not scheduled: insufficient memory",
+			expectedDuration: "90.000",
+		},
+		{
+			name:             "succeeded resource",
+			testCase:         suites.Suites[1].TestCases[0],
+			expectedName:     "ns/migrate",
+			expectedDuration: "10.000",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.testCase.Name != tc.expectedName {
+				t.Errorf("expected name %q, got %q", tc.expectedName, tc.testCase.Name)
+			}
+			if tc.testCase.Time != tc.expectedDuration {
+				t.Errorf("expected time %q, got %q", tc.expectedDuration, tc.testCase.Time)
+			}
+			if !reflect.DeepEqual(tc.testCase.Failure, tc.expectedFailure) {
+				t.Errorf("expected failure %+v, got %+v", tc.expectedFailure, tc.testCase.Failure)
+			}
+
+			var skipped string
+			if tc.testCase.Skipped != nil {
+				skipped = tc.testCase.Skipped.Message
+			}
+			if skipped != tc.expectedSkipped {
+				t.Errorf("expected skipped message %q, got %q", tc.expectedSkipped, skipped)
+			}
+
+			if tc.testCase.SystemOut != tc.expectedOutput {
+				t.Errorf("expected system-out %q, got %q", tc.expectedOutput, tc.testCase.SystemOut)
+			}
+		})
+	}
+}
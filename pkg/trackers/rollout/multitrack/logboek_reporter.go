@@ -0,0 +1,680 @@
+package multitrack
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/werf/logboek"
+	"github.com/werf/logboek/pkg/style"
+	"github.com/werf/logboek/pkg/types"
+
+	"github.com/werf/kubedog/pkg/display"
+	"github.com/werf/kubedog/pkg/tracker/deployment"
+	"github.com/werf/kubedog/pkg/tracker/indicators"
+	"github.com/werf/kubedog/pkg/tracker/pod"
+	"github.com/werf/kubedog/pkg/utils"
+)
+
+var (
+	statusProgressTableRatio    = []float64{.58, .12, .15, .15}
+	statusProgressSubTableRatio = []float64{.40, .10, .10, .20, .10, .10}
+	waitingProgressTableRatio   = []float64{.45, .20, .35}
+)
+
+// NewLogboekReporter returns the default multitrack Reporter,
+// which renders the tracking stream into logboek.
+func NewLogboekReporter() Reporter {
+	return &logboekReporter{logboekReporterState: &logboekReporterState{}}
+}
+
+type logboekReporter struct {
+	// State is shared by the reporters of all clusters of MultitrackClusters run, which write into the same logboek
+	*logboekReporterState
+
+	// cluster is shown with the resources names when not empty
+	cluster string
+}
+
+type logboekReporterState struct {
+	displayCalled           bool
+	currentLogProcessHeader string
+	currentLogProcess       types.LogProcessInterface
+}
+
+func (r *logboekReporter) ForCluster(cluster string) Reporter {
+	return &logboekReporter{logboekReporterState: r.logboekReporterState, cluster: cluster}
+}
+
+func (r *logboekReporter) formatResource(resourceKind, resourceName string) string {
+	if r.cluster == "" {
+		return fmt.Sprintf("%s/%s", resourceKind, resourceName)
+	}
+	return fmt.Sprintf("[%s] %s/%s", r.cluster, resourceKind, resourceName)
+}
+
+func (r *logboekReporter) ResourceMessage(msgType display.EventType, resourceKind string, spec MultitrackSpec, msg string) {
+	r.displayResourceServiceMessage(resourceKind, spec, msg)
+}
+
+func (r *logboekReporter) ResourceEvent(resourceKind string, spec MultitrackSpec, msg string) {
+	r.displayResourceServiceMessage(resourceKind, spec, fmt.Sprintf("event: %s", msg))
+}
+
+func (r *logboekReporter) displayResourceServiceMessage(resourceKind string, spec MultitrackSpec, msg string) {
+	if !spec.ShowServiceMessages {
+		return
+	}
+
+	r.setLogProcess(
+		fmt.Sprintf("%s service messages", r.formatResource(resourceKind, spec.ResourceName)),
+		func(options types.LogProcessOptionsInterface) {
+			options.Style(style.Details())
+			options.WithoutElapsedTime()
+		},
+	)
+
+	logboek.Context(context.Background()).Default().LogFDetails("%s\n", msg)
+}
+
+func (r *logboekReporter) ResourceError(resourceKind string, spec MultitrackSpec, reason string) {
+	r.resetLogProcess()
+	logboek.Context(context.Background()).Warn().LogF("%s ERROR: %s\n", r.formatResource(resourceKind, spec.ResourceName), reason)
+}
+
+func (r *logboekReporter) ResourceLogChunk(resourceKind string, spec MultitrackSpec, podName, containerName string, lines []display.LogLine) {
+	r.setLogProcess(fmt.Sprintf("%s %s logs", r.formatResource(resourceKind, spec.ResourceName), podContainerLogChunkHeader(podName, containerName)), func(options types.LogProcessOptionsInterface) {
+		options.WithoutElapsedTime()
+	})
+
+	for _, line := range lines {
+		logboek.Context(context.Background()).LogF("%s\n", line.Message)
+	}
+}
+
+func (r *logboekReporter) MultitrackMessage(msg string) {
+	r.resetLogProcess()
+	logboek.Context(context.Background()).Default().LogFHighlight("%s", msg)
+}
+
+func (r *logboekReporter) StatusProgress(progress StatusProgress) {
+	displayLn := false
+	if r.displayCalled {
+		displayLn = true
+	}
+
+	r.resetLogProcess()
+
+	if displayLn {
+		logboek.Context(context.Background()).LogOptionalLn()
+	}
+
+	caption := utils.BoldF("Status progress")
+	if r.cluster != "" {
+		caption = utils.BoldF("Status progress [%s]", r.cluster)
+	}
+
+	logboek.Context(context.Background()).Default().LogBlock(caption).
+		Options(func(options types.LogBlockOptionsInterface) {
+			options.WithoutLogOptionalLn()
+		}).
+		Do(func() {
+			r.displayDeploymentsStatusProgress(progress.Deployments)
+			r.displayDaemonSetsStatusProgress(progress.DaemonSets)
+			r.displayStatefulSetsStatusProgress(progress.StatefulSets)
+			r.displayJobsProgress(progress.Jobs)
+			r.displayCanariesProgress(progress.Canaries)
+			r.displayGenericsProgress(progress.Generics)
+			r.displayWaitingProgress(progress.Waiting)
+		})
+
+	logboek.Context(context.Background()).LogOptionalLn()
+}
+
+func (r *logboekReporter) TrackingFinished(results []ResourceResult) {
+	hasFailed := false
+
+	for _, res := range results {
+		if !res.IsFailed {
+			continue
+		}
+		hasFailed = true
+
+		r.displayFailedResourceServiceMessages(res)
+	}
+
+	if !hasFailed {
+		fmt.Printf("获取成功\n")
+	}
+}
+
+func (r *logboekReporter) displayFailedResourceServiceMessages(res ResourceResult) {
+	if len(res.ServiceMessages) == 0 && len(res.UnschedulablePods) == 0 {
+		return
+	}
+
+	r.resetLogProcess()
+
+	logboek.Context(context.Background()).LogOptionalLn()
+
+	logboek.Context(context.Background()).Default().LogBlock("Failed resource %s service messages", r.formatResource(res.Kind, res.Spec.ResourceName)).
+		Options(func(options types.LogBlockOptionsInterface) {
+			options.WithoutLogOptionalLn()
+			options.Style(style.Details())
+		}).
+		Do(func() {
+			for _, line := range res.ServiceMessages {
+				logboek.Context(context.Background()).Default().LogFDetails("%s\n", line)
+			}
+			for _, unschedulablePod := range res.UnschedulablePods {
+				logboek.Context(context.Background()).Default().LogFDetails("po/%s is not scheduled: %s\n", unschedulablePod.Pod, unschedulablePod.Diagnosis)
+			}
+		})
+
+	logboek.Context(context.Background()).LogOptionalLn()
+}
+
+func (r *logboekReporter) setLogProcess(header string, optionsFunc func(types.LogProcessOptionsInterface)) {
+	if r.currentLogProcessHeader != header {
+		r.resetLogProcess()
+
+		logProcess := logboek.Context(context.Background()).Default().LogProcess(header)
+
+		if optionsFunc != nil {
+			logProcess.Options(optionsFunc)
+		}
+
+		logProcess.Start()
+
+		r.currentLogProcessHeader = header
+		r.currentLogProcess = logProcess
+	}
+}
+
+func (r *logboekReporter) resetLogProcess() {
+	r.displayCalled = true
+
+	if r.currentLogProcess != nil {
+		r.currentLogProcess.End()
+		r.currentLogProcess = nil
+		r.currentLogProcessHeader = ""
+	}
+}
+
+func (r *logboekReporter) displayCanariesProgress(progress []CanaryStatusProgress) {
+	t := utils.NewTable(statusProgressTableRatio...)
+	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
+	t.Header("CANARY", "STATUS", "WEIGHT", "LASTUPDATE")
+
+	for _, p := range progress {
+		status := p.Status
+
+		spec := p.Spec
+		resource := formatResourceCaption(spec.ResourceName, spec.FailMode, status.IsSucceeded, status.IsFailed, true)
+
+		if status.IsFailed {
+			t.Row(resource, status.FailedReason, status.CanaryWeight, status.LastTransitionTime)
+		} else {
+			t.Row(resource, status.CanaryStatus.Phase, status.CanaryWeight, status.LastTransitionTime)
+		}
+	}
+
+	if len(progress) > 0 {
+		logboek.Context(context.Background()).Log(t.Render())
+	}
+}
+
+func (r *logboekReporter) displayGenericsProgress(progress []GenericStatusProgress) {
+	t := utils.NewTable(statusProgressTableRatio...)
+	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
+	t.Header("RESOURCE", "STATUS", "AGE", "MESSAGE")
+
+	for _, p := range progress {
+		prevStatus := p.PrevStatus
+		status := p.Status
+
+		spec := p.Spec
+
+		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
+		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
+
+		resource := formatResourceCaption(fmt.Sprintf("%s/%s", p.Kind, spec.ResourceName), spec.FailMode, status.IsReady, status.IsFailed, true)
+
+		state := "-"
+		if status.StatusIndicator != nil {
+			state = status.StatusIndicator.FormatTableElem(prevStatus.StatusIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+				IsResourceNew:        true,
+			})
+		}
+
+		if status.IsFailed {
+			t.Row(resource, state, status.Age, formatResourceError(disableWarningColors, status.FailedReason))
+		} else {
+			t.Row(resource, state, status.Age, status.Message)
+		}
+	}
+
+	if len(progress) > 0 {
+		logboek.Context(context.Background()).Log(t.Render())
+	}
+}
+
+func (r *logboekReporter) displayWaitingProgress(progress []WaitingStatusProgress) {
+	t := utils.NewTable(waitingProgressTableRatio...)
+	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
+	t.Header("RESOURCE", "STATUS", "DEPENDENCIES")
+
+	for _, p := range progress {
+		resource := formatResourceCaption(fmt.Sprintf("%s/%s", p.Kind, p.Spec.ResourceName), p.Spec.FailMode, false, false, true)
+		t.Row(resource, "waiting for deps", strings.Join(p.WaitingFor, ", "))
+	}
+
+	if len(progress) > 0 {
+		logboek.Context(context.Background()).Log(t.Render())
+	}
+}
+
+func (r *logboekReporter) displayJobsProgress(progress []JobStatusProgress) {
+	t := utils.NewTable(statusProgressTableRatio...)
+	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
+	t.Header("JOB", "ACTIVE", "DURATION", "SUCCEEDED/FAILED")
+
+	for _, p := range progress {
+		prevStatus := p.PrevStatus
+		status := p.Status
+
+		spec := p.Spec
+
+		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
+		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
+
+		resource := formatResourceCaption(spec.ResourceName, spec.FailMode, status.IsSucceeded, status.IsFailed, true)
+
+		succeeded := "-"
+		if status.SucceededIndicator != nil {
+			succeeded = status.SucceededIndicator.FormatTableElem(prevStatus.SucceededIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+			})
+		}
+
+		if status.IsFailed {
+			t.Row(resource, status.Active, status.Duration, strings.Join([]string{succeeded, fmt.Sprintf("%d", status.Failed)}, "/"), formatResourceError(disableWarningColors, status.FailedReason))
+		} else {
+			t.Row(resource, status.Active, status.Duration, strings.Join([]string{succeeded, fmt.Sprintf("%d", status.Failed)}, "/"))
+		}
+
+		if len(status.Pods) > 0 {
+			newPodsNames := []string{}
+			for podName := range status.Pods {
+				newPodsNames = append(newPodsNames, podName)
+			}
+
+			st := r.displayChildPodsStatusProgress(&t, prevStatus.Pods, status.Pods, newPodsNames, spec.FailMode, showProgress, disableWarningColors)
+
+			extraMsg := ""
+			if waitingForMessages := getWaitingForMessages(status.WaitingForMessages, status.Pods); len(waitingForMessages) > 0 {
+				extraMsg += "---\n"
+				extraMsg += utils.BlueF("Waiting for: %s", strings.Join(waitingForMessages, ", "))
+			}
+			st.Commit(extraMsg)
+		}
+	}
+
+	if len(progress) > 0 {
+		logboek.Context(context.Background()).Log(t.Render())
+	}
+}
+
+func (r *logboekReporter) displayStatefulSetsStatusProgress(progress []StatefulSetStatusProgress) {
+	t := utils.NewTable(statusProgressTableRatio...)
+	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
+	t.Header("STATEFULSET", "REPLICAS", "READY", "UP-TO-DATE")
+
+	for _, p := range progress {
+		prevStatus := p.PrevStatus
+		status := p.Status
+
+		spec := p.Spec
+
+		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
+		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
+
+		resource := formatResourceCaption(spec.ResourceName, spec.FailMode, status.IsReady, status.IsFailed, true)
+
+		replicas := "-"
+		if status.ReplicasIndicator != nil {
+			replicas = status.ReplicasIndicator.FormatTableElem(prevStatus.ReplicasIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+				WithTargetValue:      true,
+			})
+		}
+
+		ready := "-"
+		if status.ReadyIndicator != nil {
+			ready = status.ReadyIndicator.FormatTableElem(prevStatus.ReadyIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+			})
+		}
+
+		uptodate := "-"
+		if status.UpToDateIndicator != nil {
+			uptodate = status.UpToDateIndicator.FormatTableElem(prevStatus.UpToDateIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+			})
+		}
+
+		if status.IsFailed {
+			t.Row(resource, replicas, ready, uptodate, formatResourceError(disableWarningColors, status.FailedReason))
+		} else {
+			args := []interface{}{}
+			args = append(args, resource, replicas, ready, uptodate)
+			for _, w := range status.WarningMessages {
+				args = append(args, formatResourceWarning(disableWarningColors, w))
+			}
+			t.Row(args...)
+		}
+
+		if len(status.Pods) > 0 {
+			st := r.displayChildPodsStatusProgress(&t, prevStatus.Pods, status.Pods, status.NewPodsNames, spec.FailMode, showProgress, disableWarningColors)
+			extraMsg := ""
+			if waitingForMessages := getWaitingForMessages(status.WaitingForMessages, status.Pods); len(waitingForMessages) > 0 {
+				extraMsg += "---\n"
+				extraMsg += utils.BlueF("Waiting for: %s", strings.Join(waitingForMessages, ", "))
+			}
+			st.Commit(extraMsg)
+		}
+	}
+
+	if len(progress) > 0 {
+		logboek.Context(context.Background()).Log(t.Render())
+	}
+}
+
+func (r *logboekReporter) displayDaemonSetsStatusProgress(progress []DaemonSetStatusProgress) {
+	t := utils.NewTable(statusProgressTableRatio...)
+	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
+	t.Header("DAEMONSET", "REPLICAS", "AVAILABLE", "UP-TO-DATE")
+
+	for _, p := range progress {
+		prevStatus := p.PrevStatus
+		status := p.Status
+
+		spec := p.Spec
+
+		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
+		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
+
+		resource := formatResourceCaption(spec.ResourceName, spec.FailMode, status.IsReady, status.IsFailed, true)
+
+		replicas := "-"
+		if status.ReplicasIndicator != nil {
+			replicas = status.ReplicasIndicator.FormatTableElem(prevStatus.ReplicasIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+				WithTargetValue:      true,
+			})
+		}
+
+		available := "-"
+		if status.AvailableIndicator != nil {
+			available = status.AvailableIndicator.FormatTableElem(prevStatus.AvailableIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+			})
+		}
+
+		uptodate := "-"
+		if status.UpToDateIndicator != nil {
+			uptodate = status.UpToDateIndicator.FormatTableElem(prevStatus.UpToDateIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+			})
+		}
+
+		if status.IsFailed {
+			t.Row(resource, replicas, available, uptodate, formatResourceError(disableWarningColors, status.FailedReason))
+		} else {
+			t.Row(resource, replicas, available, uptodate)
+		}
+
+		if len(status.Pods) > 0 {
+			st := r.displayChildPodsStatusProgress(&t, prevStatus.Pods, status.Pods, status.NewPodsNames, spec.FailMode, showProgress, disableWarningColors)
+			extraMsg := ""
+			if waitingForMessages := getWaitingForMessages(status.WaitingForMessages, status.Pods); len(waitingForMessages) > 0 {
+				extraMsg += "---\n"
+				extraMsg += utils.BlueF("Waiting for: %s", strings.Join(waitingForMessages, ", "))
+			}
+			st.Commit(extraMsg)
+		}
+	}
+
+	if len(progress) > 0 {
+		logboek.Context(context.Background()).Log(t.Render())
+	}
+}
+
+func (r *logboekReporter) displayDeploymentsStatusProgress(progress []DeploymentStatusProgress) {
+	//fmt.Println("displayDeploymentsStatusProgress:", statusProgressTableRatio)
+	t := utils.NewTable(statusProgressTableRatio...)
+	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
+	t.Header("DEPLOYMENT", "REPLICAS", "AVAILABLE", "UP-TO-DATE")
+
+	for _, p := range progress {
+		prevStatus := p.PrevStatus
+		status := p.Status
+		spec := p.Spec
+
+		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
+		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
+
+		resource := formatResourceCaption(spec.ResourceName, spec.FailMode, status.IsReady, status.IsFailed, true)
+
+		replicas := "-"
+		if status.ReplicasIndicator != nil {
+			replicas = status.ReplicasIndicator.FormatTableElem(prevStatus.ReplicasIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+				WithTargetValue:      true,
+			})
+		}
+
+		available := "-"
+		if status.AvailableIndicator != nil {
+			available = status.AvailableIndicator.FormatTableElem(prevStatus.AvailableIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+			})
+		}
+
+		uptodate := "-"
+		if status.UpToDateIndicator != nil {
+			uptodate = status.UpToDateIndicator.FormatTableElem(prevStatus.UpToDateIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+			})
+		}
+
+		row := []interface{}{resource, replicas, available, uptodate}
+		if status.IsFailed {
+			row = append(row, formatResourceError(disableWarningColors, status.FailedReason))
+		}
+		if progressDeadline := formatProgressDeadline(disableWarningColors, status); progressDeadline != "" {
+			row = append(row, progressDeadline)
+		}
+		t.Row(row...)
+
+		if len(status.Pods) > 0 {
+			//fmt.Println("current status pods:", len(status.Pods))
+			st := r.displayChildPodsStatusProgress(&t, prevStatus.Pods, status.Pods, status.NewPodsNames, spec.FailMode, showProgress, disableWarningColors)
+			extraMsg := ""
+			if waitingForMessages := getWaitingForMessages(status.WaitingForMessages, status.Pods); len(waitingForMessages) > 0 {
+				extraMsg += "---\n"
+				extraMsg += utils.BlueF("Waiting for: %s", strings.Join(waitingForMessages, ", "))
+			}
+			st.Commit(extraMsg)
+		}
+	}
+
+	if len(progress) > 0 {
+		logboek.Context(context.Background()).Log(t.Render())
+	}
+}
+
+func (r *logboekReporter) displayChildPodsStatusProgress(t *utils.Table, prevPods map[string]pod.PodStatus, pods map[string]pod.PodStatus, newPodsNames []string, failMode FailMode, showProgress, disableWarningColors bool) *utils.Table {
+	st := t.SubTable(statusProgressSubTableRatio...)
+	st.Header("POD", "READY", "RESTARTS", "STATUS", "AGE", "NODEIP")
+
+	podsNames := []string{}
+	for podName := range pods {
+		podsNames = append(podsNames, podName)
+	}
+	sort.Strings(podsNames)
+
+	var podRows [][]interface{}
+
+	for _, podName := range podsNames {
+		var podRow []interface{}
+
+		isPodNew := false
+		for _, newPodName := range newPodsNames {
+			if newPodName == podName {
+				isPodNew = true
+			}
+		}
+
+		prevPodStatus := prevPods[podName]
+		podStatus := pods[podName]
+
+		isReady := false
+		if podStatus.StatusIndicator != nil {
+			isReady = podStatus.StatusIndicator.IsReady()
+		}
+
+		resource := formatResourceCaption(strings.Join(strings.Split(podName, "-")[1:], "-"), failMode, isReady, podStatus.IsFailed, isPodNew)
+
+		ready := fmt.Sprintf("%d/%d", podStatus.ReadyContainers, podStatus.TotalContainers)
+
+		status := "-"
+		if podStatus.StatusIndicator != nil {
+			status = podStatus.StatusIndicator.FormatTableElem(prevPodStatus.StatusIndicator, indicators.FormatTableElemOptions{
+				ShowProgress:         showProgress,
+				DisableWarningColors: disableWarningColors,
+				IsResourceNew:        isPodNew,
+			})
+			if status == "Running -> Terminating" {
+				status = "正在删除"
+			}
+			podRow = append(podRow, resource, ready, podStatus.Restarts, status, podStatus.Age, podStatus.HostIP)
+			if podStatus.IsFailed {
+				podRow = append(podRow, formatResourceError(disableWarningColors, podStatus.FailedReason))
+			}
+			podRows = append(podRows, podRow)
+		}
+	}
+
+	st.Rows(podRows...)
+
+	return &st
+}
+
+// getWaitingForMessages returns messages of the resource followed by messages of its pods, e.g. unschedulable pods diagnosis.
+func getWaitingForMessages(resourceMessages []string, pods map[string]pod.PodStatus) []string {
+	res := append([]string{}, resourceMessages...)
+
+	podsNames := []string{}
+	for podName := range pods {
+		podsNames = append(podsNames, podName)
+	}
+	sort.Strings(podsNames)
+
+	for _, podName := range podsNames {
+		for _, msg := range pods[podName].WaitingForMessages {
+			res = append(res, fmt.Sprintf("po/%s %s", podName, msg))
+		}
+	}
+
+	return res
+}
+
+func formatResourceWarning(disableWarningColors bool, reason string) string {
+	msg := fmt.Sprintf("warning: %s", reason)
+	if disableWarningColors {
+		return msg
+	}
+	return utils.YellowF("%s", msg)
+}
+
+// formatProgressDeadline returns the time left until the progress deadline of the Deployment rollout
+// or the error when the deadline is exceeded.
+func formatProgressDeadline(disableWarningColors bool, status deployment.DeploymentStatus) string {
+	switch {
+	case status.IsProgressDeadlineExceeded:
+		if status.IsFailed {
+			return ""
+		}
+		return formatResourceError(disableWarningColors, fmt.Sprintf("%s: %s", utils.TimedOutReason, status.ProgressDeadlineMessage))
+	case status.ProgressDeadline != nil:
+		left := time.Until(*status.ProgressDeadline).Truncate(time.Second)
+		if left < 0 {
+			left = 0
+		}
+		return utils.BlueF("progress deadline in %s", left)
+	default:
+		return ""
+	}
+}
+
+func formatResourceError(disableWarningColors bool, reason string) string {
+	msg := fmt.Sprintf("error: %s", reason)
+	if disableWarningColors {
+		return msg
+	}
+	return utils.RedF("%s", msg)
+}
+
+func formatResourceCaption(resourceCaption string, resourceFailMode FailMode, isReady bool, isFailed bool, isNew bool) string {
+	if !isNew {
+		return resourceCaption
+	}
+
+	switch resourceFailMode {
+	case FailWholeDeployProcessImmediately:
+		switch {
+		case isReady:
+			return utils.GreenF("%s", resourceCaption)
+		case isFailed:
+			return utils.RedF("%s", resourceCaption)
+		default:
+			return utils.YellowF("%s", resourceCaption)
+		}
+
+	case IgnoreAndContinueDeployProcess:
+		if isReady {
+			return utils.GreenF("%s", resourceCaption)
+		} else {
+			return resourceCaption
+		}
+
+	case HopeUntilEndOfDeployProcess:
+		if isReady {
+			return utils.GreenF("%s", resourceCaption)
+		} else {
+			return utils.YellowF("%s", resourceCaption)
+		}
+
+	default:
+		panic(fmt.Sprintf("unsupported resource fail mode '%s'", resourceFailMode))
+	}
+}
+
+func podContainerLogChunkHeader(podName, containerName string) string {
+	return fmt.Sprintf("po/%s container/%s", podName, containerName)
+}
@@ -0,0 +1,160 @@
+package multitrack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+type manifestObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Items []json.RawMessage `json:"items"`
+}
+
+// LoadManifestsFiles builds MultitrackSpecs from the Kubernetes manifests files.
+// Paths are expanded the same way as in LoadSpecsFiles.
+func LoadManifestsFiles(paths []string, defaultNamespace string) (MultitrackSpecs, error) {
+	files, err := expandSpecsFilesPaths(paths)
+	if err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	var allSpecs []MultitrackSpecs
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return MultitrackSpecs{}, fmt.Errorf("unable to read %s: %s", file, err)
+		}
+
+		specs, err := parseManifests(data, file, defaultNamespace)
+		if err != nil {
+			return MultitrackSpecs{}, err
+		}
+		allSpecs = append(allSpecs, specs)
+	}
+
+	specs := MergeSpecs(allSpecs...)
+	if err := ValidateSpecs(specs); err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	return specs, nil
+}
+
+// ParseManifests builds MultitrackSpecs from the multi-document YAML or JSON with Kubernetes manifests.
+// Deployments, StatefulSets, DaemonSets, Jobs and Flagger Canaries are tracked, other resources are skipped.
+// Every spec is configured with kubedog/* annotations of the resource (see SetSpecFromAnnotations).
+// Resources without namespace are tracked in the defaultNamespace.
+func ParseManifests(data []byte, defaultNamespace string) (MultitrackSpecs, error) {
+	specs, err := parseManifests(data, "", defaultNamespace)
+	if err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	if err := ValidateSpecs(specs); err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	return specs, nil
+}
+
+func parseManifests(data []byte, source, defaultNamespace string) (MultitrackSpecs, error) {
+	specs := MultitrackSpecs{}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for docIndex := 1; ; docIndex++ {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return MultitrackSpecs{}, formatSpecsSourceError(source, 0, err)
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		jsonData, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return MultitrackSpecs{}, formatSpecsSourceError(source, docIndex, err)
+		}
+
+		if err := addManifestSpecs(&specs, jsonData, defaultNamespace); err != nil {
+			return MultitrackSpecs{}, formatSpecsSourceError(source, docIndex, err)
+		}
+	}
+
+	return specs, nil
+}
+
+func addManifestSpecs(specs *MultitrackSpecs, jsonData []byte, defaultNamespace string) error {
+	if bytes.Equal(bytes.TrimSpace(jsonData), []byte("null")) {
+		return nil
+	}
+
+	obj := manifestObject{}
+	if err := json.Unmarshal(jsonData, &obj); err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(obj.Kind, "List") {
+		for _, item := range obj.Items {
+			if err := addManifestSpecs(specs, item, defaultNamespace); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var specsList *[]MultitrackSpec
+	var resourceKind string
+
+	group := strings.Split(obj.APIVersion, "/")[0]
+	switch {
+	case obj.Kind == "Deployment" && (group == "apps" || group == "extensions"):
+		specsList, resourceKind = &specs.Deployments, "deploy"
+	case obj.Kind == "StatefulSet" && group == "apps":
+		specsList, resourceKind = &specs.StatefulSets, "sts"
+	case obj.Kind == "DaemonSet" && (group == "apps" || group == "extensions"):
+		specsList, resourceKind = &specs.DaemonSets, "ds"
+	case obj.Kind == "Job" && group == "batch":
+		specsList, resourceKind = &specs.Jobs, "job"
+	case obj.Kind == "Canary" && group == "flagger.app":
+		specsList, resourceKind = &specs.Canaries, "canary"
+	default:
+		return nil
+	}
+
+	if obj.Metadata.Name == "" {
+		return fmt.Errorf("%s without metadata.name", obj.Kind)
+	}
+
+	spec := MultitrackSpec{
+		ResourceName: obj.Metadata.Name,
+		Namespace:    obj.Metadata.Namespace,
+	}
+	if spec.Namespace == "" {
+		spec.Namespace = defaultNamespace
+	}
+
+	if err := SetSpecFromAnnotations(&spec, obj.Metadata.Annotations); err != nil {
+		return fmt.Errorf("%s/%s: %s", resourceKind, spec.ResourceName, err)
+	}
+
+	*specsList = append(*specsList, spec)
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package multitrack
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/werf/kubedog/pkg/tracker/pod"
+)
+
+// Metrics collects prometheus metrics of the multitrack runs, single Metrics can be shared by any number of runs.
+// Metrics is the prometheus.Collector, so it can be registered in the registry of the application,
+// or it can be served with Handler and pushed to the Pushgateway with Push by itself.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	runs            *prometheus.CounterVec
+	rolloutDuration *prometheus.HistogramVec
+	timeToReady     *prometheus.HistogramVec
+	failures        *prometheus.CounterVec
+	restarts        *prometheus.CounterVec
+	logBytes        *prometheus.CounterVec
+	watchReconnects prometheus.Counter
+}
+
+// NewMetrics creates Metrics with all metrics set to zero.
+func NewMetrics() *Metrics {
+	durationBuckets := prometheus.ExponentialBuckets(1, 2, 13)
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kubedog_multitrack_runs_total",
+			Help: "Number of finished multitrack runs by result: succeeded or failed.",
+		}, []string{"result"}),
+		rolloutDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kubedog_resource_rollout_duration_seconds",
+			Help:    "Time spent on tracking of the resource until it became ready, failed or tracking has finished, by resource kind and final status.",
+			Buckets: durationBuckets,
+		}, []string{"kind", "status"}),
+		timeToReady: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kubedog_resource_time_to_ready_seconds",
+			Help:    "Time since the start of the resource tracking until the resource became ready, by resource kind.",
+			Buckets: durationBuckets,
+		}, []string{"kind"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kubedog_resource_failures_total",
+			Help: "Number of the resources failures by resource kind and failure type, including not counted and ignored failures.",
+		}, []string{"kind", "reason"}),
+		restarts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kubedog_container_restarts_total",
+			Help: "Number of the containers restarts observed while tracking, by resource kind.",
+		}, []string{"kind"}),
+		logBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kubedog_container_log_bytes_total",
+			Help: "Size of the containers logs streamed, by resource kind.",
+		}, []string{"kind"}),
+		watchReconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "kubedog_watch_reconnects_total",
+			Help: "Number of the repeated watch requests to the API server after the watch has been closed or failed.",
+		}),
+	}
+
+	m.registry.MustRegister(m)
+
+	return m
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.runs, m.rolloutDuration, m.timeToReady, m.failures, m.restarts, m.logBytes, m.watchReconnects}
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// Handler returns http.Handler serving the metrics in the prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Push replaces metrics of the job on the Pushgateway-compatible endpoint with the current metrics.
+func (m *Metrics) Push(url, job string) error {
+	return push.New(url, job).Gatherer(m.registry).Push()
+}
+
+func (m *Metrics) observeRun(report MultitrackReport, err error) {
+	if m == nil {
+		return
+	}
+
+	result := "succeeded"
+	if err != nil || report.HasFailedResources() {
+		result = "failed"
+	}
+	m.runs.WithLabelValues(result).Inc()
+
+	for _, res := range report.Resources {
+		m.rolloutDuration.WithLabelValues(res.Kind, res.Status).Observe(res.DurationSeconds)
+		if res.ReadyAt != nil {
+			m.timeToReady.WithLabelValues(res.Kind).Observe(res.TimeToReadySeconds)
+		}
+	}
+}
+
+func (m *Metrics) observeFailure(kind string, failureType pod.FailureType) {
+	if m == nil {
+		return
+	}
+
+	if failureType == "" {
+		failureType = pod.OtherFailure
+	}
+	m.failures.WithLabelValues(kind, string(failureType)).Inc()
+}
+
+func (m *Metrics) observeLogChunk(kind string, chunk *pod.ContainerLogChunk) {
+	if m == nil {
+		return
+	}
+
+	// Previous instance logs are fetched once on the container restart and are not part of the logs stream
+	if chunk.PreviousInstance != nil {
+		m.restarts.WithLabelValues(kind).Inc()
+		return
+	}
+
+	var size int
+	for _, line := range chunk.LogLines {
+		size += len(line.Message) + 1
+	}
+	m.logBytes.WithLabelValues(kind).Add(float64(size))
+}
+
+func (m *Metrics) observeWatchReconnect(_ string) {
+	if m == nil {
+		return
+	}
+
+	m.watchReconnects.Inc()
+}
@@ -9,7 +9,8 @@ import (
 	"sync"
 	"time"
 
-	"github.com/werf/logboek/pkg/types"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/werf/kubedog/pkg/tracker"
@@ -17,7 +18,10 @@ import (
 	"github.com/werf/kubedog/pkg/tracker/daemonset"
 	"github.com/werf/kubedog/pkg/tracker/debug"
 	"github.com/werf/kubedog/pkg/tracker/deployment"
+	"github.com/werf/kubedog/pkg/tracker/generic"
+	"github.com/werf/kubedog/pkg/tracker/informer"
 	"github.com/werf/kubedog/pkg/tracker/job"
+	"github.com/werf/kubedog/pkg/tracker/pod"
 	"github.com/werf/kubedog/pkg/tracker/statefulset"
 )
 
@@ -52,6 +56,12 @@ type MultitrackSpecs struct {
 	DaemonSets   []MultitrackSpec
 	Jobs         []MultitrackSpec
 	Canaries     []MultitrackSpec
+
+	// Generic resources of any kind are tracked by their status, see MultitrackGenericSpec.
+	Generic []MultitrackGenericSpec
+
+	// Selectors discover resources to track by labels, see MultitrackSelectorSpec.
+	Selectors []MultitrackSelectorSpec
 }
 
 type MultitrackSpec struct {
@@ -62,32 +72,85 @@ type MultitrackSpec struct {
 	FailMode                FailMode
 	AllowFailuresCount      *int
 	FailureThresholdSeconds *int
+	// CountedFailureTypes are failure types counted toward AllowFailuresCount, all failures are counted when empty.
+	// Failures of other types are displayed, but do not affect the resource tracking. Failures of the pod status,
+	// which are not container errors, e.g. Unschedulable, Evicted or FailedMount, are only handled when their type
+	// is listed here or their category is in the FailurePolicy.
+	CountedFailureTypes []pod.FailureType
+	// FailurePolicy overrides handling of the failures by categories, e.g. to ignore readiness probe failures
+	// and to fail immediately on image pull errors. Failures of categories not in the policy are counted,
+	// CountedFailureTypes only filter failures with the Count action.
+	FailurePolicy FailurePolicy
+	// TimeoutSeconds fails the resource, which is not ready within the specified time since its tracking has started,
+	// instead of MultitrackOptions.Timeout. Timeout is the critical failure regardless of FailMode.
+	TimeoutSeconds *int
+	// NoProgressTimeoutSeconds fails the resource, which status indicators, such as ready and up-to-date replicas counts,
+	// have not changed for the specified time. Timeout is the critical failure regardless of FailMode.
+	NoProgressTimeoutSeconds *int
+	// FailOnProgressDeadline defines handling of the ProgressDeadlineExceeded failure of the Deployment regardless
+	// of FailurePolicy and CountedFailureTypes: the failure is fatal and fails the whole deploy process immediately
+	// when not set or true, false only displays the failure and continues tracking.
+	FailOnProgressDeadline *bool
+	// RollbackOnFailure reverts the failed Deployment, StatefulSet or DaemonSet to its previous revision
+	// and tracks the rollback until the resource is ready, see RollbackReport. Pointer distinguishes explicit false,
+	// which takes precedence over the kubedog/rollback-on-failure annotation, from not set.
+	RollbackOnFailure *bool
 
 	IgnoreReadinessProbeFailsByContainerName map[string]time.Duration
 
 	LogRegex                *regexp.Regexp
 	LogRegexByContainerName map[string]*regexp.Regexp
 
+	// SkipLogs and ShowServiceMessages cannot be explicitly set to false: false is the same as not set,
+	// so kubedog/skip-logs and kubedog/show-service-messages annotations set to true take precedence over it.
 	SkipLogs                  bool
 	SkipLogsForContainers     []string
 	ShowLogsOnlyForContainers []string
 	// ShowLogsUntil             DeployCondition TODO
 
 	ShowServiceMessages bool
+
+	// DependsOn postpones tracking of the resource until all referenced resources are ready.
+	// References are in the "KIND/NAME" format, where KIND is deploy, sts, ds, job, canary or the kind of the generic resource.
+	// The resource fails when any of its dependencies fails.
+	DependsOn []string
 }
 
 type MultitrackOptions struct {
 	tracker.Options
 	StatusProgressPeriod time.Duration
+
+	// Reporter receives all tracking output, logboek reporter is used by default.
+	Reporter Reporter
+	// UseResourceAnnotations enables configuration of the specs with kubedog/* annotations of the live
+	// Deployments, StatefulSets, DaemonSets and Jobs. Values explicitly set in the specs take precedence
+	// over annotations, default values are used for the fields set neither in the specs nor in annotations.
+	UseResourceAnnotations bool
+	// ReportLogLinesCount is the number of last log lines of failed containers kept in the MultitrackReport.
+	ReportLogLinesCount int
+	// DynamicClient is required to track MultitrackSpecs.Generic resources.
+	DynamicClient dynamic.Interface
+	// DiagnosticsBundlePath enables collection of the diagnostics bundle when tracking fails: objects, owned replicasets
+	// and pods, conditions, logs of failing containers, events and nodes conditions of every not ready resource.
+	// Bundle is written as tar.gz archive when the path ends with .tar.gz or .tgz and as a directory otherwise.
+	DiagnosticsBundlePath string
+	// Metrics collects prometheus metrics of the run, see Metrics.
+	Metrics *Metrics
+	// TracerProvider creates spans of the run: the root span of the run is the child of the ParentContext span
+	// and every tracked resource has the child span from adding until ready or failed. Global provider is used when not set.
+	TracerProvider trace.TracerProvider
 }
 
-func newMultitrackOptions(parentContext context.Context, timeout, statusProgessPeriod time.Duration, logsFromTime time.Time, ignoreReadinessProbeFailsByContainerName map[string]time.Duration) MultitrackOptions {
+func newMultitrackOptions(parentContext context.Context, timeout, statusProgessPeriod time.Duration, logsFromTime time.Time, ignoreReadinessProbeFailsByContainerName map[string]time.Duration, informers *informer.Factory, watchRetryBudget int) MultitrackOptions {
 	return MultitrackOptions{
 		Options: tracker.Options{
 			ParentContext:                            parentContext,
 			Timeout:                                  timeout,
 			LogsFromTime:                             logsFromTime,
 			IgnoreReadinessProbeFailsByContainerName: ignoreReadinessProbeFailsByContainerName,
+			Informers:                                informers,
+			WatchRetryBudget:                         watchRetryBudget,
+			ReportStatusFailures:                     true,
 		},
 		StatusProgressPeriod: statusProgessPeriod,
 	}
@@ -120,12 +183,65 @@ func setDefaultSpecValues(spec *MultitrackSpec) {
 }
 
 func Multitrack(kube kubernetes.Interface, specs MultitrackSpecs, opts MultitrackOptions) error {
-	if len(specs.Deployments)+len(specs.StatefulSets)+len(specs.DaemonSets)+len(specs.Jobs)+len(specs.Canaries) == 0 {
-		return nil
+	_, err := MultitrackWithReport(kube, specs, opts)
+	return err
+}
+
+// MultitrackWithReport tracks resources the same way as Multitrack and additionally returns
+// the report about every tracked resource, which is available even when tracking has failed.
+func MultitrackWithReport(kube kubernetes.Interface, specs MultitrackSpecs, opts MultitrackOptions) (MultitrackReport, error) {
+	return multitrack(kube, specs, opts, false)
+}
+
+// WatchNamespace tracks all Deployments, StatefulSets, DaemonSets and Jobs of the namespace like a dashboard:
+// resources are attached as they appear and dropped on deletion, ready and failed resources are tracked further
+// and status progress is displayed periodically. WatchNamespace returns when opts.ParentContext is done.
+func WatchNamespace(kube kubernetes.Interface, namespace string, opts MultitrackOptions) error {
+	specs := MultitrackSpecs{
+		Selectors: []MultitrackSelectorSpec{
+			{Namespace: namespace, Template: MultitrackSpec{FailMode: IgnoreAndContinueDeployProcess}},
+		},
+	}
+
+	_, err := multitrack(kube, specs, opts, true)
+	return err
+}
+
+func multitrack(kube kubernetes.Interface, specs MultitrackSpecs, opts MultitrackOptions, watchMode bool) (report MultitrackReport, err error) {
+	parentContext := opts.ParentContext
+	if parentContext == nil {
+		parentContext = context.Background()
+	}
+
+	if len(specs.Selectors) > 0 {
+		if err := discoverSelectorsResources(parentContext, kube, &specs); err != nil {
+			now := time.Now()
+			return MultitrackReport{StartedAt: now, FinishedAt: now}, err
+		}
+	}
+
+	if !watchMode && len(specs.Deployments)+len(specs.StatefulSets)+len(specs.DaemonSets)+len(specs.Jobs)+len(specs.Canaries)+len(specs.Generic) == 0 {
+		now := time.Now()
+		return MultitrackReport{StartedAt: now, FinishedAt: now}, nil
+	}
+
+	specsFromAnnotations := make(map[string]map[string]string)
+	var annotationsWarnings []string
+	if opts.UseResourceAnnotations {
+		var err error
+		specsFromAnnotations, annotationsWarnings, err = mergeSpecsWithLiveAnnotations(parentContext, kube, &specs)
+		if err != nil {
+			now := time.Now()
+			return MultitrackReport{StartedAt: now, FinishedAt: now}, err
+		}
+
+		if err := validateSpecsWithLiveAnnotations(specs, specsFromAnnotations); err != nil {
+			now := time.Now()
+			return MultitrackReport{StartedAt: now, FinishedAt: now}, err
+		}
 	}
 
 	for i := range specs.Deployments {
-		//fmt.Println("遍历的deployments:", i)
 		setDefaultSpecValues(&specs.Deployments[i])
 	}
 
@@ -141,6 +257,25 @@ func Multitrack(kube kubernetes.Interface, specs MultitrackSpecs, opts Multitrac
 	for i := range specs.Canaries {
 		setDefaultCanarySpecValues(&specs.Canaries[i])
 	}
+	for i := range specs.Generic {
+		setDefaultSpecValues(&specs.Generic[i].MultitrackSpec)
+	}
+
+	genericResources, err := resolveGenericResources(kube, opts.DynamicClient, specs.Generic)
+	if err != nil {
+		now := time.Now()
+		return MultitrackReport{StartedAt: now, FinishedAt: now}, err
+	}
+
+	dependencies, err := resolveDependencies(specs, genericResources)
+	if err != nil {
+		if resources := getResourcesWithAppliedAnnotation(specsFromAnnotations, DependsOnAnnotation); len(resources) > 0 {
+			err = fmt.Errorf("%s\n%s annotation is applied to: %s", err, DependsOnAnnotation, strings.Join(resources, ", "))
+		}
+
+		now := time.Now()
+		return MultitrackReport{StartedAt: now, FinishedAt: now}, err
+	}
 
 	mt := multitracker{
 		DeploymentsSpecs:        make(map[string]MultitrackSpec),
@@ -173,8 +308,55 @@ func Multitrack(kube kubernetes.Interface, specs MultitrackSpecs, opts Multitrac
 		CanariesStatuses:     make(map[string]canary.CanaryStatus),
 		PrevCanariesStatuses: make(map[string]canary.CanaryStatus),
 
-		serviceMessagesByResource: make(map[string][]string),
+		GenericSpecs:        make(map[string]MultitrackSpec),
+		GenericResources:    make(map[string]multitrackGenericResource),
+		GenericContexts:     make(map[string]*multitrackerContext),
+		TrackingGenerics:    make(map[string]*multitrackerResourceState),
+		GenericStatuses:     make(map[string]generic.ResourceStatus),
+		PrevGenericStatuses: make(map[string]generic.ResourceStatus),
+
+		serviceMessagesByResource:  make(map[string][]string),
+		eventsByResource:           make(map[string][]string),
+		logLinesByResource:         make(map[string]map[containerRef][]string),
+		failedContainersByResource: make(map[string][]containerRef),
+		specsFromAnnotations:       specsFromAnnotations,
+		dependencies:               dependencies,
+		waitingResources:           make(map[string]*multitrackerWaitingResource),
+		metrics:                    opts.Metrics,
+		startedAt:                  time.Now(),
+		reportLogLinesCount:        opts.ReportLogLinesCount,
+		watchMode:                  watchMode,
+	}
+
+	if mt.reportLogLinesCount == 0 {
+		mt.reportLogLinesCount = defaultReportLogLinesCount
+	}
+
+	mt.reporter = opts.Reporter
+	if mt.reporter == nil {
+		mt.reporter = NewLogboekReporter()
+	}
+
+	for _, warning := range annotationsWarnings {
+		mt.displayMultitrackServiceMessageF("%s\n", warning)
+	}
+
+	// All trackers of the run share namespace informers, so that the number of watches does not grow with the number of resources
+	if opts.Informers == nil {
+		informersCtx, cancelInformers := context.WithCancel(parentContext)
+		defer cancelInformers()
+
+		opts.Informers = informer.NewFactory(informersCtx, kube)
 	}
+	mt.informers = opts.Informers
+
+	if opts.Metrics != nil {
+		opts.Informers.SetWatchReconnectHandler(opts.Metrics.observeWatchReconnect)
+		defer func() { opts.Metrics.observeRun(report, err) }()
+	}
+
+	runSpan := mt.startRunSpan(parentContext, opts.TracerProvider)
+	defer func() { mt.endRunSpan(runSpan, report, err) }()
 
 	errorChan := make(chan error)
 	doneChan := make(chan struct{})
@@ -200,97 +382,195 @@ func Multitrack(kube kubernetes.Interface, specs MultitrackSpecs, opts Multitrac
 		return mt.displayStatusProgress()
 	}
 
-	mt.Start(kube, specs, doneChan, errorChan, opts)
+	mt.Start(kube, specs, genericResources, doneChan, errorChan, opts)
+
+	if len(specs.Selectors) > 0 {
+		selectorsCtx, cancelSelectors := context.WithCancel(parentContext)
+		defer cancelSelectors()
+
+		mt.runSelectorsWatchers(selectorsCtx, kube, specs.Selectors, opts.UseResourceAnnotations, opts.WatchRetryBudget)
+	}
+
+	// Only watch mode is stopped by the parent context, multitrack is stopped by trackers
+	var parentDoneChan <-chan struct{}
+	if watchMode {
+		parentDoneChan = parentContext.Done()
+	}
 
 	for {
 		select {
 		case <-statusProgressChan:
 			if err := doDisplayStatusProgress(); err != nil {
-				return err
+				return mt.getReport(), err
 			}
 
+		case <-parentDoneChan:
+			return mt.getReport(), nil
+
 		case <-doneChan:
-			fmt.Printf("获取成功\n")
 			if debug.Debug() {
 				fmt.Printf("-- Multitrack doneChan signal received => exiting\n")
 			}
-			return nil
+			return mt.rollbackFailedResources(kube, opts, mt.getReport(), nil)
 
 		case err := <-errorChan:
 			if err == nil {
 				panic("unexpected nil error received through errorChan")
 			}
-			return err
+
+			report = mt.getReport()
+			if opts.DiagnosticsBundlePath != "" {
+				mt.writeDiagnosticsBundle(kube, opts.DynamicClient, opts.DiagnosticsBundlePath, report)
+			}
+			return mt.rollbackFailedResources(kube, opts, report, err)
 		}
 	}
 }
 
-func (mt *multitracker) Start(kube kubernetes.Interface, specs MultitrackSpecs, doneChan chan struct{}, errorChan chan error, opts MultitrackOptions) {
+func (mt *multitracker) Start(kube kubernetes.Interface, specs MultitrackSpecs, genericResources []multitrackGenericResource, doneChan chan struct{}, errorChan chan error, opts MultitrackOptions) {
 	mt.mux.Lock()
 	defer mt.mux.Unlock()
 
 	var wg sync.WaitGroup
 
-	for _, spec := range specs.Deployments {
-		mt.DeploymentsContexts[spec.ResourceName] = newMultitrackerContext(opts.ParentContext)
-		mt.DeploymentsSpecs[spec.ResourceName] = spec
-		mt.TrackingDeployments[spec.ResourceName] = newMultitrackerResourceState(spec)
+	newTrackerOptions := func(mtCtx *multitrackerContext, spec MultitrackSpec) MultitrackOptions {
+		timeout := opts.Timeout
+		if spec.TimeoutSeconds != nil && *spec.TimeoutSeconds > 0 {
+			// Resource timeout is handled by multitracker
+			timeout = 0
+		}
+		return newMultitrackOptions(mtCtx.Context, timeout, opts.StatusProgressPeriod, opts.LogsFromTime, spec.IgnoreReadinessProbeFailsByContainerName, opts.Informers, opts.WatchRetryBudget)
+	}
 
-		wg.Add(1)
+	// runResourceTracker starts tracker of the resource identified by the key in the contexts map,
+	// tracker of the resource with not ready dependencies is started when dependencies are ready
+	runResourceTracker := func(kind, key string, spec MultitrackSpec, contexts map[string]*multitrackerContext, trackerFunc func(MultitrackSpec, *multitrackerContext) error) {
+		mt.startResourceSpan(kind, spec)
+		mt.displaySpecFromAnnotations(kind, spec)
 
-		go mt.runSpecTracker("deploy", spec, mt.DeploymentsContexts[spec.ResourceName], &wg, mt.DeploymentsContexts, doneChan, errorChan, func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
-			return mt.TrackDeployment(kube, spec, newMultitrackOptions(mtCtx.Context, opts.Timeout, opts.StatusProgressPeriod, opts.LogsFromTime, spec.IgnoreReadinessProbeFailsByContainerName))
-		})
-	}
+		start := func() {
+			contexts[key] = newMultitrackerContext(opts.ParentContext)
+			mt.getResourceState(kind, spec).StartedAt = time.Now()
 
-	for _, spec := range specs.StatefulSets {
-		mt.StatefulSetsContexts[spec.ResourceName] = newMultitrackerContext(opts.ParentContext)
-		mt.StatefulSetsSpecs[spec.ResourceName] = spec
-		mt.TrackingStatefulSets[spec.ResourceName] = newMultitrackerResourceState(spec)
+			mt.activeTrackersCount++
+			wg.Add(1)
 
-		wg.Add(1)
+			go mt.runSpecTracker(kind, key, spec, contexts[key], &wg, contexts, doneChan, errorChan, trackerFunc)
 
-		go mt.runSpecTracker("sts", spec, mt.StatefulSetsContexts[spec.ResourceName], &wg, mt.StatefulSetsContexts, doneChan, errorChan, func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
-			return mt.TrackStatefulSet(kube, spec, newMultitrackOptions(mtCtx.Context, opts.Timeout, opts.StatusProgressPeriod, opts.LogsFromTime, spec.IgnoreReadinessProbeFailsByContainerName))
-		})
-	}
+			if hasResourceTimeouts(spec) {
+				go mt.watchResourceTimeouts(kind, key, spec, contexts[key])
+			}
+		}
 
-	for _, spec := range specs.DaemonSets {
-		mt.DaemonSetsContexts[spec.ResourceName] = newMultitrackerContext(opts.ParentContext)
-		mt.DaemonSetsSpecs[spec.ResourceName] = spec
-		mt.TrackingDaemonSets[spec.ResourceName] = newMultitrackerResourceState(spec)
+		resourceKey := fmt.Sprintf("%s/%s", kind, spec.ResourceName)
+		notReadyDependencies := mt.getNotReadyDependencies(resourceKey)
+		if len(notReadyDependencies) == 0 {
+			start()
+			return
+		}
 
-		wg.Add(1)
+		mt.getResourceState(kind, spec).Status = resourceWaiting
+		mt.displayMultitrackServiceMessageF("%s is waiting for dependencies: %s\n", resourceKey, strings.Join(notReadyDependencies, ", "))
 
-		go mt.runSpecTracker("ds", spec, mt.DaemonSetsContexts[spec.ResourceName], &wg, mt.DaemonSetsContexts, doneChan, errorChan, func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
-			return mt.TrackDaemonSet(kube, spec, newMultitrackOptions(mtCtx.Context, opts.Timeout, opts.StatusProgressPeriod, opts.LogsFromTime, spec.IgnoreReadinessProbeFailsByContainerName))
-		})
+		// Waiting resource is done when its tracker is done or when it is dropped
+		wg.Add(1)
+		mt.waitingResources[resourceKey] = &multitrackerWaitingResource{
+			Kind: kind,
+			Spec: spec,
+			Start: func() {
+				mt.getResourceState(kind, spec).Status = resourceActive
+				start()
+				wg.Done()
+			},
+			Drop: wg.Done,
+		}
 	}
 
-	for _, spec := range specs.Jobs {
-		mt.JobsContexts[spec.ResourceName] = newMultitrackerContext(opts.ParentContext)
-		mt.JobsSpecs[spec.ResourceName] = spec
-		mt.TrackingJobs[spec.ResourceName] = newMultitrackerResourceState(spec)
+	mt.startResourceTracker = func(kind string, spec MultitrackSpec) {
+		// Resource is re-created in watch mode
+		if state := mt.getResourceState(kind, spec); state != nil {
+			state.endSpan()
+		}
 
-		wg.Add(1)
+		var contexts map[string]*multitrackerContext
+		var trackerFunc func(MultitrackSpec, *multitrackerContext) error
 
-		go mt.runSpecTracker("job", spec, mt.JobsContexts[spec.ResourceName], &wg, mt.JobsContexts, doneChan, errorChan, func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
-			return mt.TrackJob(kube, spec, newMultitrackOptions(mtCtx.Context, opts.Timeout, opts.StatusProgressPeriod, opts.LogsFromTime, spec.IgnoreReadinessProbeFailsByContainerName))
-		})
+		switch kind {
+		case "deploy":
+			contexts = mt.DeploymentsContexts
+			mt.DeploymentsSpecs[spec.ResourceName] = spec
+			mt.TrackingDeployments[spec.ResourceName] = newMultitrackerResourceState(spec)
+			trackerFunc = func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
+				return mt.TrackDeployment(kube, spec, newTrackerOptions(mtCtx, spec))
+			}
+		case "sts":
+			contexts = mt.StatefulSetsContexts
+			mt.StatefulSetsSpecs[spec.ResourceName] = spec
+			mt.TrackingStatefulSets[spec.ResourceName] = newMultitrackerResourceState(spec)
+			trackerFunc = func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
+				return mt.TrackStatefulSet(kube, spec, newTrackerOptions(mtCtx, spec))
+			}
+		case "ds":
+			contexts = mt.DaemonSetsContexts
+			mt.DaemonSetsSpecs[spec.ResourceName] = spec
+			mt.TrackingDaemonSets[spec.ResourceName] = newMultitrackerResourceState(spec)
+			trackerFunc = func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
+				return mt.TrackDaemonSet(kube, spec, newTrackerOptions(mtCtx, spec))
+			}
+		case "job":
+			contexts = mt.JobsContexts
+			mt.JobsSpecs[spec.ResourceName] = spec
+			mt.TrackingJobs[spec.ResourceName] = newMultitrackerResourceState(spec)
+			trackerFunc = func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
+				return mt.TrackJob(kube, spec, newTrackerOptions(mtCtx, spec))
+			}
+		case "canary":
+			contexts = mt.CanariesContexts
+			mt.CanariesSpecs[spec.ResourceName] = spec
+			mt.TrackingCanaries[spec.ResourceName] = newMultitrackerResourceState(spec)
+			trackerFunc = func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
+				return mt.TrackCanary(kube, spec, newTrackerOptions(mtCtx, spec))
+			}
+		default:
+			panic(fmt.Sprintf("unsupported resource kind %q", kind))
+		}
+
+		runResourceTracker(kind, spec.ResourceName, spec, contexts, trackerFunc)
 	}
 
+	for _, spec := range specs.Deployments {
+		mt.startResourceTracker("deploy", spec)
+	}
+	for _, spec := range specs.StatefulSets {
+		mt.startResourceTracker("sts", spec)
+	}
+	for _, spec := range specs.DaemonSets {
+		mt.startResourceTracker("ds", spec)
+	}
+	for _, spec := range specs.Jobs {
+		mt.startResourceTracker("job", spec)
+	}
 	for _, spec := range specs.Canaries {
-		mt.CanariesContexts[spec.ResourceName] = newMultitrackerContext(opts.ParentContext)
-		mt.CanariesSpecs[spec.ResourceName] = spec
-		mt.TrackingCanaries[spec.ResourceName] = newMultitrackerResourceState(spec)
+		mt.startResourceTracker("canary", spec)
+	}
+	for _, resource := range genericResources {
+		resource := resource
 
-		wg.Add(1)
+		key := resource.key()
+		mt.GenericSpecs[key] = resource.Spec
+		mt.GenericResources[key] = resource
+		mt.TrackingGenerics[key] = newMultitrackerResourceState(resource.Spec)
 
-		go mt.runSpecTracker("canary", spec, mt.CanariesContexts[spec.ResourceName], &wg, mt.CanariesContexts, doneChan, errorChan, func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
-			return mt.TrackCanary(kube, spec, newMultitrackOptions(mtCtx.Context, opts.Timeout, opts.StatusProgressPeriod, opts.LogsFromTime, spec.IgnoreReadinessProbeFailsByContainerName))
+		runResourceTracker(resource.Kind, key, resource.Spec, mt.GenericContexts, func(spec MultitrackSpec, mtCtx *multitrackerContext) error {
+			return mt.TrackGeneric(kube, opts.DynamicClient, resource, spec, newTrackerOptions(mtCtx, spec))
 		})
 	}
 
+	if mt.watchMode {
+		// Watch mode runs until the parent context is done
+		return
+	}
+
 	if err := mt.applyTrackTerminationMode(); err != nil {
 		errorChan <- fmt.Errorf("unable to apply termination mode: %s", err)
 		return
@@ -318,8 +598,13 @@ func (mt *multitracker) Start(kube kubernetes.Interface, specs MultitrackSpecs,
 			return
 		}
 
+		func() {
+			mt.mux.Lock()
+			defer mt.mux.Unlock()
+			mt.displayTrackingResults()
+		}()
+
 		if mt.hasFailedTrackingResources() {
-			mt.displayFailedTrackingResourcesServiceMessages()
 			errorChan <- mt.formatFailedTrackingResourcesError()
 		} else {
 
@@ -395,8 +680,22 @@ func (mt *multitracker) applyTrackTerminationMode() error {
 		}
 		contextsToStop = append(contextsToStop, ctx)
 	}
+	for key, ctx := range mt.GenericContexts {
+		if shouldContinueTracking(key, mt.GenericSpecs[key]) {
+			return nil
+		}
+		debugMsg = append(debugMsg, fmt.Sprintf("will stop context for %s", key))
+		contextsToStop = append(contextsToStop, ctx)
+	}
+	for key, waiting := range mt.waitingResources {
+		if shouldContinueTracking(key, waiting.Spec) {
+			return nil
+		}
+		debugMsg = append(debugMsg, fmt.Sprintf("will drop waiting %s", key))
+	}
 
 	mt.isTerminating = true
+	mt.dropWaitingResources()
 
 	if debug.Debug() {
 		for _, msg := range debugMsg {
@@ -411,7 +710,7 @@ func (mt *multitracker) applyTrackTerminationMode() error {
 	return nil
 }
 
-func (mt *multitracker) runSpecTracker(kind string, spec MultitrackSpec, mtCtx *multitrackerContext, wg *sync.WaitGroup, contexts map[string]*multitrackerContext, doneChan chan struct{}, errorChan chan error, trackerFunc func(MultitrackSpec, *multitrackerContext) error) {
+func (mt *multitracker) runSpecTracker(kind, key string, spec MultitrackSpec, mtCtx *multitrackerContext, wg *sync.WaitGroup, contexts map[string]*multitrackerContext, doneChan chan struct{}, errorChan chan error, trackerFunc func(MultitrackSpec, *multitrackerContext) error) {
 	defer wg.Done()
 
 	err := trackerFunc(spec, mtCtx)
@@ -419,10 +718,32 @@ func (mt *multitracker) runSpecTracker(kind string, spec MultitrackSpec, mtCtx *
 	mt.mux.Lock()
 	defer mt.mux.Unlock()
 
-	delete(contexts, spec.ResourceName)
+	mt.activeTrackersCount--
+	// The context could be already replaced by the tracker of the re-created resource in watch mode
+	if contexts[key] == mtCtx {
+		delete(contexts, key)
+	}
+
+	if mt.watchMode {
+		// Errors of the trackers stopped because of the resource deletion are expected
+		if err != nil && mtCtx.Context.Err() == nil {
+			mt.displayMultitrackServiceMessageF("%s/%s track failed: %s\n", kind, spec.ResourceName, err)
+		}
+		return
+	}
+
+	// Tracker of the timed out resource is stopped by the context
+	if mtCtx.IsTimedOut {
+		err = ErrFailWholeDeployProcessImmediately
+	}
+
+	// Waiting resources will not be tracked when tracking fails or is canceled by the parent context
+	if err != nil || mtCtx.Context.Err() != nil {
+		mt.dropWaitingResources()
+	}
 
 	if err == ErrFailWholeDeployProcessImmediately {
-		mt.displayFailedTrackingResourcesServiceMessages()
+		mt.displayTrackingResults()
 		errorChan <- mt.formatFailedTrackingResourcesError()
 		mt.isFailed = true
 		return
@@ -471,20 +792,49 @@ type multitracker struct {
 	CanariesStatuses     map[string]canary.CanaryStatus
 	PrevCanariesStatuses map[string]canary.CanaryStatus
 
+	// Generic resources are identified by the "kind/name" key
+	GenericSpecs        map[string]MultitrackSpec
+	GenericResources    map[string]multitrackGenericResource
+	GenericContexts     map[string]*multitrackerContext
+	TrackingGenerics    map[string]*multitrackerResourceState
+	GenericStatuses     map[string]generic.ResourceStatus
+	PrevGenericStatuses map[string]generic.ResourceStatus
+
 	mux sync.Mutex
 
 	isFailed      bool
 	isTerminating bool
 
-	displayCalled             bool
-	currentLogProcessHeader   string
-	currentLogProcess         types.LogProcessInterface
+	// startResourceTracker starts tracker for the new resource, should be called with locked mux
+	startResourceTracker func(kind string, spec MultitrackSpec)
+	activeTrackersCount  int
+	watchMode            bool
+	informers            *informer.Factory
+
+	reporter                  Reporter
+	metrics                   *Metrics
 	serviceMessagesByResource map[string][]string
+
+	tracer       trace.Tracer
+	traceContext context.Context
+
+	startedAt                  time.Time
+	reportLogLinesCount        int
+	eventsByResource           map[string][]string
+	logLinesByResource         map[string]map[containerRef][]string
+	failedContainersByResource map[string][]containerRef
+	specsFromAnnotations       map[string]map[string]string
+
+	// dependencies maps the "kind/name" key of the resource to the keys of the resources it depends on
+	dependencies     map[string][]string
+	waitingResources map[string]*multitrackerWaitingResource
 }
 
 type multitrackerContext struct {
 	Context    context.Context
 	CancelFunc context.CancelFunc
+	// IsTimedOut is set when the tracker is stopped because of the resource timeout
+	IsTimedOut bool
 }
 
 func newMultitrackerContext(parentContext context.Context) *multitrackerContext {
@@ -503,6 +853,7 @@ const (
 	resourceFailed            multitrackerResourceStatus = "resourceFailed"
 	resourceHoping            multitrackerResourceStatus = "resourceHoping"
 	resourceActiveAfterHoping multitrackerResourceStatus = "resourceActiveAfterHoping"
+	resourceWaiting           multitrackerResourceStatus = "resourceWaiting"
 )
 
 type multitrackerResourceState struct {
@@ -510,18 +861,44 @@ type multitrackerResourceState struct {
 	FailedReason             string
 	FailuresCount            int
 	FailuresCountAfterHoping int
+	// StartedAt is the time the tracker of the resource has started, zero for the resource waiting for dependencies
+	StartedAt time.Time
+	ReadyAt   time.Time
+	FailedAt  time.Time
+
+	span trace.Span
 }
 
 func newMultitrackerResourceState(spec MultitrackSpec) *multitrackerResourceState {
 	return &multitrackerResourceState{Status: resourceActive}
 }
 
+// getResourceState returns the state of the tracked resource of the kind, nil for unknown resources.
+func (mt *multitracker) getResourceState(kind string, spec MultitrackSpec) *multitrackerResourceState {
+	switch kind {
+	case "deploy":
+		return mt.TrackingDeployments[spec.ResourceName]
+	case "sts":
+		return mt.TrackingStatefulSets[spec.ResourceName]
+	case "ds":
+		return mt.TrackingDaemonSets[spec.ResourceName]
+	case "job":
+		return mt.TrackingJobs[spec.ResourceName]
+	case "canary":
+		return mt.TrackingCanaries[spec.ResourceName]
+	default:
+		return mt.TrackingGenerics[fmt.Sprintf("%s/%s", kind, spec.ResourceName)]
+	}
+}
+
 func (mt *multitracker) hasFailedTrackingResources() bool {
 	for _, states := range []map[string]*multitrackerResourceState{
 		mt.TrackingDeployments,
 		mt.TrackingStatefulSets,
 		mt.TrackingDaemonSets,
 		mt.TrackingJobs,
+		mt.TrackingCanaries,
+		mt.TrackingGenerics,
 	} {
 		for _, state := range states {
 			if state.Status == resourceFailed {
@@ -565,111 +942,235 @@ func (mt *multitracker) formatFailedTrackingResourcesError() error {
 		}
 		msgParts = append(msgParts, fmt.Sprintf("canary/%s failed: %s", name, state.FailedReason))
 	}
+	for key, state := range mt.TrackingGenerics {
+		if state.Status != resourceFailed {
+			continue
+		}
+		msgParts = append(msgParts, fmt.Sprintf("%s failed: %s", key, state.FailedReason))
+	}
 
 	return fmt.Errorf("%s", strings.Join(msgParts, "\n"))
 }
 
-func (mt *multitracker) handleResourceReadyCondition(resourcesStates map[string]*multitrackerResourceState, spec MultitrackSpec) error {
-	resourcesStates[spec.ResourceName].Status = resourceSucceeded
+func (mt *multitracker) handleResourceReadyCondition(state *multitrackerResourceState) error {
+	state.Status = resourceSucceeded
+	state.ReadyAt = time.Now()
+	state.endSpan()
+	mt.updateWaitingResources()
+
+	if mt.watchMode {
+		return nil
+	}
 	return tracker.StopTrack
 }
 
-func (mt *multitracker) handleResourceFailure(resourcesStates map[string]*multitrackerResourceState, kind string, spec MultitrackSpec, reason string) error {
+func isFailureTypeCounted(spec MultitrackSpec, failureType pod.FailureType) bool {
+	if len(spec.CountedFailureTypes) == 0 {
+		return true
+	}
+
+	if failureType == "" {
+		failureType = pod.OtherFailure
+	}
+
+	for _, countedType := range spec.CountedFailureTypes {
+		if countedType == failureType {
+			return true
+		}
+	}
+	return false
+}
+
+// isStatusFailureTypeEnabled checks whether the failure of the pod status, which is not a container error,
+// is enabled by CountedFailureTypes or FailurePolicy of the spec, these failures are not handled by default.
+func isStatusFailureTypeEnabled(spec MultitrackSpec, failureType pod.FailureType) bool {
+	for _, countedType := range spec.CountedFailureTypes {
+		if countedType == failureType {
+			return true
+		}
+	}
+
+	_, hasKey := spec.FailurePolicy[GetFailureCategory(failureType)]
+	return hasKey
+}
+
+func validateFailureType(failureType pod.FailureType) error {
+	var expected []string
+	for _, knownType := range pod.FailureTypes {
+		if failureType == knownType {
+			return nil
+		}
+		expected = append(expected, string(knownType))
+	}
+	return fmt.Errorf("invalid value %q, expected one of: %s", failureType, strings.Join(expected, ", "))
+}
+
+// getFailureTypeByReason classifies the resource failure reason, which is either the reason of the resource condition
+// or the "Reason: message" of the failed event.
+func getFailureTypeByReason(reason string) pod.FailureType {
+	return pod.FailureTypeByReason(strings.SplitN(reason, ":", 2)[0])
+}
+
+// handlePodError displays the error of the pod or its container and handles it as the failure of the resource.
+func (mt *multitracker) handlePodError(state *multitrackerResourceState, kind string, spec MultitrackSpec, podError pod.PodError) error {
+	if podError.IsStatusFailure && !isStatusFailureTypeEnabled(spec, podError.Type) {
+		mt.metrics.observeFailure(kind, podError.Type)
+		return nil
+	}
+
+	var reason string
+	if podError.ContainerName == "" {
+		reason = fmt.Sprintf("po/%s: %s", podError.PodName, podError.Message)
+	} else {
+		reason = fmt.Sprintf("po/%s container/%s: %s", podError.PodName, podError.ContainerName, podError.Message)
+		mt.addResourceFailedContainer(kind, spec, podError)
+	}
+
+	mt.displayResourceErrorF(kind, spec, "%s", reason)
+
+	return mt.handleResourceFailure(state, kind, spec, podError.Type, reason)
+}
+
+func (mt *multitracker) handleResourceFailure(state *multitrackerResourceState, kind string, spec MultitrackSpec, failureType pod.FailureType, reason string) error {
+	mt.metrics.observeFailure(kind, failureType)
+
 	forceFailure := false
 	if strings.Contains(reason, "ErrImageNeverPull") {
 		forceFailure = true
 	}
 
-	switch spec.FailMode {
+	// FailOnProgressDeadline takes precedence over FailurePolicy, CountedFailureTypes only filter counted failures
+	action := spec.FailurePolicy.getAction(failureType)
+	if failureType == pod.ProgressDeadlineExceededFailure {
+		if spec.FailOnProgressDeadline != nil && !*spec.FailOnProgressDeadline {
+			mt.displayMultitrackServiceMessageF("Progress deadline of %s/%s is exceeded, but it is not fatal: continue tracking\n", kind, spec.ResourceName)
+			return nil
+		}
+		action = FailImmediatelyFailureAction
+	}
+
+	if action == CountFailureAction && !isFailureTypeCounted(spec, failureType) {
+		mt.displayMultitrackServiceMessageF("%s failure of %s/%s is not counted: continue tracking\n", failureType, kind, spec.ResourceName)
+		return nil
+	}
+
+	failMode := spec.FailMode
+	switch action {
+	case IgnoreFailureAction:
+		mt.displayMultitrackServiceMessageF("%s failure of %s/%s is ignored by the failure policy: continue tracking\n", GetFailureCategory(failureType), kind, spec.ResourceName)
+		return nil
+	case FailImmediatelyFailureAction:
+		failMode = FailWholeDeployProcessImmediately
+		forceFailure = true
+	case HopeUntilEndOfDeployProcessFailureAction:
+		failMode = HopeUntilEndOfDeployProcess
+	}
+
+	switch failMode {
 	case FailWholeDeployProcessImmediately:
-		resourcesStates[spec.ResourceName].FailuresCount++
+		state.FailuresCount++
 
-		if !forceFailure && resourcesStates[spec.ResourceName].FailuresCount <= *spec.AllowFailuresCount {
-			mt.displayMultitrackServiceMessageF("%d/%d allowed errors occurred for %s/%s: continue tracking\n", resourcesStates[spec.ResourceName].FailuresCount, *spec.AllowFailuresCount, kind, spec.ResourceName)
+		if !forceFailure && state.FailuresCount <= *spec.AllowFailuresCount {
+			mt.displayMultitrackServiceMessageF("%d/%d allowed errors occurred for %s/%s: continue tracking\n", state.FailuresCount, *spec.AllowFailuresCount, kind, spec.ResourceName)
 			return nil
 		}
 
 		if forceFailure {
-			mt.displayMultitrackServiceMessageF("Critical failure for %s/%s has been occurred: stop tracking immediately!\n", kind, spec.ResourceName, *spec.AllowFailuresCount)
+			mt.displayMultitrackServiceMessageF("Critical failure for %s/%s has been occurred: stop tracking immediately!\n", kind, spec.ResourceName)
 		} else {
 			mt.displayMultitrackServiceMessageF("Allowed failures count for %s/%s exceeded %d errors: stop tracking immediately!\n", kind, spec.ResourceName, *spec.AllowFailuresCount)
 		}
 
-		resourcesStates[spec.ResourceName].Status = resourceFailed
-		resourcesStates[spec.ResourceName].FailedReason = reason
+		state.Status = resourceFailed
+		state.FailedReason = reason
+		state.FailedAt = time.Now()
+		state.endSpan()
+		mt.updateWaitingResources()
 
 		return ErrFailWholeDeployProcessImmediately
 
 	case HopeUntilEndOfDeployProcess:
 
 	handleResourceState:
-		switch resourcesStates[spec.ResourceName].Status {
+		switch state.Status {
 		case resourceActive:
-			resourcesStates[spec.ResourceName].Status = resourceHoping
+			state.Status = resourceHoping
 			goto handleResourceState
 
 		case resourceHoping:
-			activeResourcesNames := mt.getActiveResourcesNames()
+			activeResourcesNames := mt.getActiveResourcesNames(fmt.Sprintf("%s/%s", kind, spec.ResourceName))
 			if len(activeResourcesNames) > 0 {
 				mt.displayMultitrackServiceMessageF("Error occurred for %s/%s, waiting until following resources are ready before counting errors (HopeUntilEndOfDeployProcess fail mode is active): %s\n", kind, spec.ResourceName, strings.Join(activeResourcesNames, ", "))
 				return nil
 			}
 
-			resourcesStates[spec.ResourceName].Status = resourceActiveAfterHoping
+			state.Status = resourceActiveAfterHoping
 			goto handleResourceState
 
 		case resourceActiveAfterHoping:
-			resourcesStates[spec.ResourceName].FailuresCount++
+			state.FailuresCount++
 
-			if resourcesStates[spec.ResourceName].FailuresCount <= *spec.AllowFailuresCount {
-				mt.displayMultitrackServiceMessageF("%d/%d allowed errors occurred for %s/%s: continue tracking\n", resourcesStates[spec.ResourceName].FailuresCount, *spec.AllowFailuresCount, kind, spec.ResourceName)
+			if state.FailuresCount <= *spec.AllowFailuresCount {
+				mt.displayMultitrackServiceMessageF("%d/%d allowed errors occurred for %s/%s: continue tracking\n", state.FailuresCount, *spec.AllowFailuresCount, kind, spec.ResourceName)
 				return nil
 			}
 
 			mt.displayMultitrackServiceMessageF("Allowed failures count for %s/%s exceeded %d errors: stop tracking immediately!\n", kind, spec.ResourceName, *spec.AllowFailuresCount)
 
-			resourcesStates[spec.ResourceName].Status = resourceFailed
-			resourcesStates[spec.ResourceName].FailedReason = reason
+			state.Status = resourceFailed
+			state.FailedReason = reason
+			state.FailedAt = time.Now()
+			state.endSpan()
+			mt.updateWaitingResources()
 
 			return ErrFailWholeDeployProcessImmediately
 
+		case resourceSucceeded, resourceFailed, resourceWaiting:
+			// Pod errors are still relayed after the resource has succeeded or failed, e.g. for the pods of the finished Job
+			state.FailuresCount++
+			mt.displayMultitrackServiceMessageF("%d errors occurred for %s/%s, which is not tracked as active: continue tracking\n", state.FailuresCount, kind, spec.ResourceName)
+			return nil
+
 		default:
-			panic(fmt.Sprintf("%s/%s tracker is in unexpected state %#v", kind, spec.ResourceName, resourcesStates[spec.ResourceName].Status))
+			return fmt.Errorf("%s/%s tracker is in unexpected state %q", kind, spec.ResourceName, state.Status)
 		}
 
 	case IgnoreAndContinueDeployProcess:
-		resourcesStates[spec.ResourceName].FailuresCount++
-		mt.displayMultitrackServiceMessageF("%d errors occurred for %s/%s\n", resourcesStates[spec.ResourceName].FailuresCount, kind, spec.ResourceName)
+		state.FailuresCount++
+		mt.displayMultitrackServiceMessageF("%d errors occurred for %s/%s\n", state.FailuresCount, kind, spec.ResourceName)
 		return nil
 
 	default:
-		panic(fmt.Sprintf("bad fail mode %#v for resource %s/%s", spec.FailMode, kind, spec.ResourceName))
+		panic(fmt.Sprintf("bad fail mode %#v for resource %s/%s", failMode, kind, spec.ResourceName))
 	}
 }
 
-func (mt *multitracker) getActiveResourcesNames() []string {
+// getActiveResourcesNames returns resources, which are tracked or waiting for dependencies, except the waiting resources
+// depending on the resource with the key, because they are not started until that resource is ready.
+func (mt *multitracker) getActiveResourcesNames(key string) []string {
 	activeResources := []string{}
 
-	for name, state := range mt.TrackingDeployments {
-		if state.Status == resourceActive {
-			activeResources = append(activeResources, fmt.Sprintf("deploy/%s", name))
-		}
-	}
-	for name, state := range mt.TrackingStatefulSets {
-		if state.Status == resourceActive {
-			activeResources = append(activeResources, fmt.Sprintf("sts/%s", name))
-		}
-	}
-	for name, state := range mt.TrackingDaemonSets {
-		if state.Status == resourceActive {
-			activeResources = append(activeResources, fmt.Sprintf("ds/%s", name))
+	for _, desc := range []struct {
+		Kind   string
+		States map[string]*multitrackerResourceState
+	}{
+		{"deploy", mt.TrackingDeployments},
+		{"sts", mt.TrackingStatefulSets},
+		{"ds", mt.TrackingDaemonSets},
+		{"job", mt.TrackingJobs},
+		{"canary", mt.TrackingCanaries},
+	} {
+		for name, state := range desc.States {
+			resourceKey := fmt.Sprintf("%s/%s", desc.Kind, name)
+			if state.Status == resourceActive || (state.Status == resourceWaiting && !mt.isDependentOn(resourceKey, key)) {
+				activeResources = append(activeResources, resourceKey)
+			}
 		}
 	}
-	for name, state := range mt.TrackingJobs {
-		if state.Status == resourceActive {
-			activeResources = append(activeResources, fmt.Sprintf("job/%s", name))
+	for resourceKey, state := range mt.TrackingGenerics {
+		if state.Status == resourceActive || (state.Status == resourceWaiting && !mt.isDependentOn(resourceKey, key)) {
+			activeResources = append(activeResources, resourceKey)
 		}
 	}
-
 	return activeResources
 }
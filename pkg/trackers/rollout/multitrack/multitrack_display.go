@@ -1,27 +1,20 @@
 package multitrack
 
 import (
-	"context"
 	"fmt"
 	"regexp"
 	"sort"
-	"strings"
 
-	"github.com/werf/logboek"
-	"github.com/werf/logboek/pkg/style"
-	"github.com/werf/logboek/pkg/types"
+	"go.opentelemetry.io/otel/attribute"
 
-	"github.com/werf/kubedog/pkg/tracker/indicators"
+	"github.com/werf/kubedog/pkg/display"
 	"github.com/werf/kubedog/pkg/tracker/pod"
-	"github.com/werf/kubedog/pkg/utils"
 )
 
-var (
-	statusProgressTableRatio    = []float64{.58, .12, .15, .15}
-	statusProgressSubTableRatio = []float64{.40, .10, .10, .20, .10, .10}
-)
+func (mt *multitracker) displayResourceLogChunk(resourceKind string, spec MultitrackSpec, podName string, chunk *pod.ContainerLogChunk) {
+	mt.addResourceLogLines(resourceKind, spec, podName, chunk.ContainerName, chunk.LogLines)
+	mt.metrics.observeLogChunk(resourceKind, chunk)
 
-func (mt *multitracker) displayResourceLogChunk(resourceKind string, spec MultitrackSpec, header string, chunk *pod.ContainerLogChunk) {
 	if spec.SkipLogs {
 		return
 	}
@@ -50,617 +43,189 @@ func (mt *multitracker) displayResourceLogChunk(resourceKind string, spec Multit
 		logRegexp = spec.LogRegex
 	}
 
-	showLines := []string{}
+	showLines := []display.LogLine{}
 
 	if logRegexp != nil {
-		for _, logLine := range chunk.LogLines {
+		for i, logLine := range chunk.LogLines {
+			// Delimiters of the previous instance logs block are always shown
+			isDelimiter := chunk.PreviousInstance != nil && (i == 0 || i == len(chunk.LogLines)-1)
+
 			message := logRegexp.FindString(logLine.Message)
-			if message != "" {
-				showLines = append(showLines, logLine.Message)
+			if message != "" || isDelimiter {
+				showLines = append(showLines, logLine)
 			}
 		}
 	} else {
-		for _, logLine := range chunk.LogLines {
-			showLines = append(showLines, logLine.Message)
-		}
+		showLines = append(showLines, chunk.LogLines...)
 	}
 
-	if len(showLines) > 0 {
-		mt.setLogProcess(fmt.Sprintf("%s/%s %s logs", resourceKind, spec.ResourceName, header), func(options types.LogProcessOptionsInterface) {
-			options.WithoutElapsedTime()
-		})
-
-		for _, line := range showLines {
-			logboek.Context(context.Background()).LogF("%s\n", line)
-		}
+	if len(showLines) == 0 {
+		return
 	}
-}
-
-func (mt *multitracker) setLogProcess(header string, optionsFunc func(types.LogProcessOptionsInterface)) {
-	if mt.currentLogProcessHeader != header {
-		mt.resetLogProcess()
 
-		logProcess := logboek.Context(context.Background()).Default().LogProcess(header)
+	mt.reporter.ResourceLogChunk(resourceKind, spec, podName, chunk.ContainerName, showLines)
+}
 
-		if optionsFunc != nil {
-			logProcess.Options(optionsFunc)
-		}
+func (mt *multitracker) displayResourceTrackerMessageF(resourceKind string, spec MultitrackSpec, format string, a ...interface{}) {
+	mt.displayResourceStateMessageF(display.EventServiceMessage, resourceKind, spec, format, a...)
+}
 
-		logProcess.Start()
+func (mt *multitracker) displayResourceStateMessageF(eventType display.EventType, resourceKind string, spec MultitrackSpec, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	mt.addResourceServiceMessage(resourceKind, spec, msg)
+	mt.addResourceSpanEvent(resourceKind, spec, msg)
 
-		mt.currentLogProcessHeader = header
-		mt.currentLogProcess = logProcess
-	}
+	mt.reporter.ResourceMessage(eventType, resourceKind, spec, msg)
 }
 
-func (mt *multitracker) resetLogProcess() {
-	mt.displayCalled = true
-
-	if mt.currentLogProcess != nil {
-		mt.currentLogProcess.End()
-		mt.currentLogProcess = nil
-		mt.currentLogProcessHeader = ""
+func (mt *multitracker) displaySpecFromAnnotations(resourceKind string, spec MultitrackSpec) {
+	applied := mt.specsFromAnnotations[fmt.Sprintf("%s/%s", resourceKind, spec.ResourceName)]
+	for _, name := range getKubedogAnnotationsNames(applied) {
+		mt.displayResourceTrackerMessageF(resourceKind, spec, "spec configured with annotation %s=%q", name, applied[name])
 	}
 }
 
-func (mt *multitracker) displayResourceTrackerMessageF(resourceKind string, spec MultitrackSpec, format string, a ...interface{}) {
-	resource := fmt.Sprintf("%s/%s", resourceKind, spec.ResourceName)
+func (mt *multitracker) displayResourceEventF(resourceKind string, spec MultitrackSpec, format string, a ...interface{}) {
 	msg := fmt.Sprintf(format, a...)
-	mt.serviceMessagesByResource[resource] = append(mt.serviceMessagesByResource[resource], msg)
+	mt.addResourceServiceMessage(resourceKind, spec, fmt.Sprintf("event: %s", msg))
+	mt.addResourceEvent(resourceKind, spec, msg)
+	mt.addResourceSpanEvent(resourceKind, spec, "event", attribute.String("kubedog.message", msg))
 
-	if spec.ShowServiceMessages {
-		mt.setLogProcess(
-			fmt.Sprintf("%s/%s service messages", resourceKind, spec.ResourceName),
-			func(options types.LogProcessOptionsInterface) {
-				options.Style(style.Details())
-				options.WithoutElapsedTime()
-			},
-		)
-
-		logboek.Context(context.Background()).Default().LogFDetails("%s\n", msg)
-	}
+	mt.reporter.ResourceEvent(resourceKind, spec, msg)
 }
 
-func (mt *multitracker) displayResourceEventF(resourceKind string, spec MultitrackSpec, format string, a ...interface{}) {
+func (mt *multitracker) addResourceServiceMessage(resourceKind string, spec MultitrackSpec, msg string) {
 	resource := fmt.Sprintf("%s/%s", resourceKind, spec.ResourceName)
-	msg := fmt.Sprintf(fmt.Sprintf("event: %s", format), a...)
 	mt.serviceMessagesByResource[resource] = append(mt.serviceMessagesByResource[resource], msg)
-
-	if spec.ShowServiceMessages {
-		mt.setLogProcess(
-			fmt.Sprintf("%s/%s service messages", resourceKind, spec.ResourceName),
-			func(options types.LogProcessOptionsInterface) {
-				options.Style(style.Details())
-				options.WithoutElapsedTime()
-			},
-		)
-
-		logboek.Context(context.Background()).Default().LogFDetails("%s\n", msg)
-	}
 }
 
 func (mt *multitracker) displayResourceErrorF(resourceKind string, spec MultitrackSpec, format string, a ...interface{}) {
-	mt.resetLogProcess()
-	logboek.Context(context.Background()).Warn().LogF(fmt.Sprintf("%s/%s ERROR: %s\n", resourceKind, spec.ResourceName, format), a...)
-}
-
-func (mt *multitracker) displayFailedTrackingResourcesServiceMessages() {
-	for name, state := range mt.TrackingDeployments {
-		if state.Status != resourceFailed {
-			continue
-		}
-
-		spec := mt.DeploymentsSpecs[name]
-		mt.displayResourceServiceMessages("deploy", spec)
-	}
-	for name, state := range mt.TrackingStatefulSets {
-		if state.Status != resourceFailed {
-			continue
-		}
-
-		spec := mt.StatefulSetsSpecs[name]
-		mt.displayResourceServiceMessages("sts", spec)
-	}
-	for name, state := range mt.TrackingDaemonSets {
-		if state.Status != resourceFailed {
-			continue
-		}
-
-		spec := mt.DaemonSetsSpecs[name]
-		mt.displayResourceServiceMessages("ds", spec)
-	}
-	for name, state := range mt.TrackingJobs {
-		if state.Status != resourceFailed {
-			continue
-		}
+	reason := fmt.Sprintf(format, a...)
+	mt.addResourceSpanEvent(resourceKind, spec, "error", attribute.String("kubedog.message", reason))
 
-		spec := mt.JobsSpecs[name]
-		mt.displayResourceServiceMessages("job", spec)
-	}
-}
-
-func (mt *multitracker) displayResourceServiceMessages(resourceKind string, spec MultitrackSpec) {
-	lines := mt.serviceMessagesByResource[fmt.Sprintf("%s/%s", resourceKind, spec.ResourceName)]
-
-	if len(lines) > 0 {
-		mt.resetLogProcess()
-
-		logboek.Context(context.Background()).LogOptionalLn()
-
-		logboek.Context(context.Background()).Default().LogBlock("Failed resource %s/%s service messages", resourceKind, spec.ResourceName).
-			Options(func(options types.LogBlockOptionsInterface) {
-				options.WithoutLogOptionalLn()
-				options.Style(style.Details())
-			}).
-			Do(func() {
-				for _, line := range lines {
-					logboek.Context(context.Background()).Default().LogFDetails("%s\n", line)
-				}
-			})
-
-		logboek.Context(context.Background()).LogOptionalLn()
-	}
+	mt.reporter.ResourceError(resourceKind, spec, reason)
 }
 
 func (mt *multitracker) displayMultitrackServiceMessageF(format string, a ...interface{}) {
-	mt.resetLogProcess()
-	logboek.Context(context.Background()).Default().LogFHighlight(format, a...)
+	mt.reporter.MultitrackMessage(fmt.Sprintf(format, a...))
 }
 
-func (mt *multitracker) displayMultitrackErrorMessageF(format string, a ...interface{}) {
-	mt.resetLogProcess()
-	logboek.Context(context.Background()).Warn().LogF(format, a...)
+func (mt *multitracker) displayTrackingResults() {
+	mt.reporter.TrackingFinished(mt.collectResourcesResults())
 }
 
 func (mt *multitracker) displayStatusProgress() error {
-	displayLn := false
-	//fmt.Println("current mt.displayCalled:", mt.displayCalled)
-	if mt.displayCalled {
-		displayLn = true
-	}
-
-	mt.resetLogProcess()
-
-	if displayLn {
-		logboek.Context(context.Background()).LogOptionalLn()
-	}
-
-	caption := utils.BoldF("Status progress")
-
-	logboek.Context(context.Background()).Default().LogBlock(caption).
-		Options(func(options types.LogBlockOptionsInterface) {
-			options.WithoutLogOptionalLn()
-		}).
-		Do(func() {
-			mt.displayDeploymentsStatusProgress()
-			mt.displayDaemonSetsStatusProgress()
-			mt.displayStatefulSetsStatusProgress()
-			mt.displayJobsProgress()
-			mt.displayCanariesProgress()
-		})
-
-	logboek.Context(context.Background()).LogOptionalLn()
-
+	mt.reporter.StatusProgress(mt.collectStatusProgress())
 	return nil
 }
 
-func (mt *multitracker) displayCanariesProgress() {
-	t := utils.NewTable(statusProgressTableRatio...)
-	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
-	t.Header("CANARY", "STATUS", "WEIGHT", "LASTUPDATE")
+func (mt *multitracker) collectStatusProgress() StatusProgress {
+	progress := StatusProgress{}
 
-	resourcesNames := []string{}
-	for name := range mt.CanariesSpecs {
-		resourcesNames = append(resourcesNames, name)
+	for _, key := range mt.getWaitingResourcesKeys() {
+		waiting := mt.waitingResources[key]
+		progress.Waiting = append(progress.Waiting, WaitingStatusProgress{Kind: waiting.Kind, Spec: waiting.Spec, WaitingFor: mt.getNotReadyDependencies(key)})
 	}
-	sort.Strings(resourcesNames)
 
-	for _, name := range resourcesNames {
-		status := mt.CanariesStatuses[name]
-
-		spec := mt.CanariesSpecs[name]
-		resource := formatResourceCaption(name, spec.FailMode, status.IsSucceeded, status.IsFailed, true)
-
-		if status.IsFailed {
-			t.Row(resource, status.FailedReason, status.CanaryWeight, status.LastTransitionTime)
-		} else {
-			t.Row(resource, status.CanaryStatus.Phase, status.CanaryWeight, status.LastTransitionTime)
+	for _, name := range sortedSpecsNames(mt.DeploymentsSpecs) {
+		if mt.TrackingDeployments[name].Status == resourceWaiting {
+			continue
 		}
+		status := mt.DeploymentsStatuses[name]
+		progress.Deployments = append(progress.Deployments, DeploymentStatusProgress{Spec: mt.DeploymentsSpecs[name], Status: status, PrevStatus: mt.PrevDeploymentsStatuses[name]})
+		mt.PrevDeploymentsStatuses[name] = status
 	}
-
-	if len(resourcesNames) > 0 {
-		logboek.Context(context.Background()).Log(t.Render())
-	}
-}
-
-func (mt *multitracker) displayJobsProgress() {
-	t := utils.NewTable(statusProgressTableRatio...)
-	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
-	t.Header("JOB", "ACTIVE", "DURATION", "SUCCEEDED/FAILED")
-
-	resourcesNames := []string{}
-	for name := range mt.JobsSpecs {
-		resourcesNames = append(resourcesNames, name)
-	}
-	sort.Strings(resourcesNames)
-
-	for _, name := range resourcesNames {
-		prevStatus := mt.PrevJobsStatuses[name]
-		status := mt.JobsStatuses[name]
-
-		spec := mt.JobsSpecs[name]
-
-		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
-		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
-
-		resource := formatResourceCaption(name, spec.FailMode, status.IsSucceeded, status.IsFailed, true)
-
-		succeeded := "-"
-		if status.SucceededIndicator != nil {
-			succeeded = status.SucceededIndicator.FormatTableElem(prevStatus.SucceededIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-			})
-		}
-
-		if status.IsFailed {
-			t.Row(resource, status.Active, status.Duration, strings.Join([]string{succeeded, fmt.Sprintf("%d", status.Failed)}, "/"), formatResourceError(disableWarningColors, status.FailedReason))
-		} else {
-			t.Row(resource, status.Active, status.Duration, strings.Join([]string{succeeded, fmt.Sprintf("%d", status.Failed)}, "/"))
-		}
-
-		if len(status.Pods) > 0 {
-			newPodsNames := []string{}
-			for podName := range status.Pods {
-				newPodsNames = append(newPodsNames, podName)
-			}
-
-			st := mt.displayChildPodsStatusProgress(&t, prevStatus.Pods, status.Pods, newPodsNames, spec.FailMode, showProgress, disableWarningColors)
-
-			extraMsg := ""
-			if len(status.WaitingForMessages) > 0 {
-				extraMsg += "---\n"
-				extraMsg += utils.BlueF("Waiting for: %s", strings.Join(status.WaitingForMessages, ", "))
-			}
-			st.Commit(extraMsg)
+	for _, name := range sortedSpecsNames(mt.StatefulSetsSpecs) {
+		if mt.TrackingStatefulSets[name].Status == resourceWaiting {
+			continue
 		}
-
-		mt.PrevJobsStatuses[name] = status
-	}
-
-	if len(resourcesNames) > 0 {
-		logboek.Context(context.Background()).Log(t.Render())
-	}
-}
-
-func (mt *multitracker) displayStatefulSetsStatusProgress() {
-	t := utils.NewTable(statusProgressTableRatio...)
-	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
-	t.Header("STATEFULSET", "REPLICAS", "READY", "UP-TO-DATE")
-
-	resourcesNames := []string{}
-	for name := range mt.StatefulSetsSpecs {
-		resourcesNames = append(resourcesNames, name)
-	}
-	sort.Strings(resourcesNames)
-
-	for _, name := range resourcesNames {
-		prevStatus := mt.PrevStatefulSetsStatuses[name]
 		status := mt.StatefulSetsStatuses[name]
-
-		spec := mt.StatefulSetsSpecs[name]
-
-		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
-		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
-
-		resource := formatResourceCaption(name, spec.FailMode, status.IsReady, status.IsFailed, true)
-
-		replicas := "-"
-		if status.ReplicasIndicator != nil {
-			replicas = status.ReplicasIndicator.FormatTableElem(prevStatus.ReplicasIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-				WithTargetValue:      true,
-			})
-		}
-
-		ready := "-"
-		if status.ReadyIndicator != nil {
-			ready = status.ReadyIndicator.FormatTableElem(prevStatus.ReadyIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-			})
-		}
-
-		uptodate := "-"
-		if status.UpToDateIndicator != nil {
-			uptodate = status.UpToDateIndicator.FormatTableElem(prevStatus.UpToDateIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-			})
-		}
-
-		if status.IsFailed {
-			t.Row(resource, replicas, ready, uptodate, formatResourceError(disableWarningColors, status.FailedReason))
-		} else {
-			args := []interface{}{}
-			args = append(args, resource, replicas, ready, uptodate)
-			for _, w := range status.WarningMessages {
-				args = append(args, formatResourceWarning(disableWarningColors, w))
-			}
-			t.Row(args...)
-		}
-
-		if len(status.Pods) > 0 {
-			st := mt.displayChildPodsStatusProgress(&t, prevStatus.Pods, status.Pods, status.NewPodsNames, spec.FailMode, showProgress, disableWarningColors)
-			extraMsg := ""
-			if len(status.WaitingForMessages) > 0 {
-				extraMsg += "---\n"
-				extraMsg += utils.BlueF("Waiting for: %s", strings.Join(status.WaitingForMessages, ", "))
-			}
-			st.Commit(extraMsg)
-		}
-
+		progress.StatefulSets = append(progress.StatefulSets, StatefulSetStatusProgress{Spec: mt.StatefulSetsSpecs[name], Status: status, PrevStatus: mt.PrevStatefulSetsStatuses[name]})
 		mt.PrevStatefulSetsStatuses[name] = status
 	}
-
-	if len(resourcesNames) > 0 {
-		logboek.Context(context.Background()).Log(t.Render())
-	}
-}
-
-func (mt *multitracker) displayDaemonSetsStatusProgress() {
-	t := utils.NewTable(statusProgressTableRatio...)
-	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
-	t.Header("DAEMONSET", "REPLICAS", "AVAILABLE", "UP-TO-DATE")
-
-	resourcesNames := []string{}
-	for name := range mt.DaemonSetsSpecs {
-		resourcesNames = append(resourcesNames, name)
-	}
-	sort.Strings(resourcesNames)
-
-	for _, name := range resourcesNames {
-		prevStatus := mt.PrevDaemonSetsStatuses[name]
-		status := mt.DaemonSetsStatuses[name]
-
-		spec := mt.DaemonSetsSpecs[name]
-
-		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
-		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
-
-		resource := formatResourceCaption(name, spec.FailMode, status.IsReady, status.IsFailed, true)
-
-		replicas := "-"
-		if status.ReplicasIndicator != nil {
-			replicas = status.ReplicasIndicator.FormatTableElem(prevStatus.ReplicasIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-				WithTargetValue:      true,
-			})
-		}
-
-		available := "-"
-		if status.AvailableIndicator != nil {
-			available = status.AvailableIndicator.FormatTableElem(prevStatus.AvailableIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-			})
-		}
-
-		uptodate := "-"
-		if status.UpToDateIndicator != nil {
-			uptodate = status.UpToDateIndicator.FormatTableElem(prevStatus.UpToDateIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-			})
-		}
-
-		if status.IsFailed {
-			t.Row(resource, replicas, available, uptodate, formatResourceError(disableWarningColors, status.FailedReason))
-		} else {
-			t.Row(resource, replicas, available, uptodate)
-		}
-
-		if len(status.Pods) > 0 {
-			st := mt.displayChildPodsStatusProgress(&t, prevStatus.Pods, status.Pods, status.NewPodsNames, spec.FailMode, showProgress, disableWarningColors)
-			extraMsg := ""
-			if len(status.WaitingForMessages) > 0 {
-				extraMsg += "---\n"
-				extraMsg += utils.BlueF("Waiting for: %s", strings.Join(status.WaitingForMessages, ", "))
-			}
-			st.Commit(extraMsg)
+	for _, name := range sortedSpecsNames(mt.DaemonSetsSpecs) {
+		if mt.TrackingDaemonSets[name].Status == resourceWaiting {
+			continue
 		}
-
+		status := mt.DaemonSetsStatuses[name]
+		progress.DaemonSets = append(progress.DaemonSets, DaemonSetStatusProgress{Spec: mt.DaemonSetsSpecs[name], Status: status, PrevStatus: mt.PrevDaemonSetsStatuses[name]})
 		mt.PrevDaemonSetsStatuses[name] = status
 	}
-
-	if len(resourcesNames) > 0 {
-		logboek.Context(context.Background()).Log(t.Render())
-	}
-}
-
-func (mt *multitracker) displayDeploymentsStatusProgress() {
-	//fmt.Println("displayDeploymentsStatusProgress:", statusProgressTableRatio)
-	t := utils.NewTable(statusProgressTableRatio...)
-	t.SetWidth(logboek.Context(context.Background()).Streams().ContentWidth() - 1)
-	t.Header("DEPLOYMENT", "REPLICAS", "AVAILABLE", "UP-TO-DATE")
-
-	resourcesNames := []string{}
-	for name := range mt.DeploymentsSpecs {
-		resourcesNames = append(resourcesNames, name)
-	}
-	sort.Strings(resourcesNames)
-	//fmt.Println("resourcesNames:", resourcesNames)
-	for _, name := range resourcesNames {
-		prevStatus := mt.PrevDeploymentsStatuses[name]
-		status := mt.DeploymentsStatuses[name]
-		spec := mt.DeploymentsSpecs[name]
-
-		showProgress := status.StatusGeneration > prevStatus.StatusGeneration
-		disableWarningColors := spec.FailMode == IgnoreAndContinueDeployProcess
-
-		resource := formatResourceCaption(name, spec.FailMode, status.IsReady, status.IsFailed, true)
-
-		replicas := "-"
-		if status.ReplicasIndicator != nil {
-			replicas = status.ReplicasIndicator.FormatTableElem(prevStatus.ReplicasIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-				WithTargetValue:      true,
-			})
-		}
-
-		available := "-"
-		if status.AvailableIndicator != nil {
-			available = status.AvailableIndicator.FormatTableElem(prevStatus.AvailableIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-			})
-		}
-
-		uptodate := "-"
-		if status.UpToDateIndicator != nil {
-			uptodate = status.UpToDateIndicator.FormatTableElem(prevStatus.UpToDateIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-			})
-		}
-
-		if status.IsFailed {
-			t.Row(resource, replicas, available, uptodate, formatResourceError(disableWarningColors, status.FailedReason))
-		} else {
-			t.Row(resource, replicas, available, uptodate)
-		}
-
-		if len(status.Pods) > 0 {
-			//fmt.Println("current status pods:", len(status.Pods))
-			st := mt.displayChildPodsStatusProgress(&t, prevStatus.Pods, status.Pods, status.NewPodsNames, spec.FailMode, showProgress, disableWarningColors)
-			extraMsg := ""
-			if len(status.WaitingForMessages) > 0 {
-				extraMsg += "---\n"
-				extraMsg += utils.BlueF("Waiting for: %s", strings.Join(status.WaitingForMessages, ", "))
-			}
-			st.Commit(extraMsg)
+	for _, name := range sortedSpecsNames(mt.JobsSpecs) {
+		if mt.TrackingJobs[name].Status == resourceWaiting {
+			continue
 		}
-
-		mt.PrevDeploymentsStatuses[name] = status
-	}
-
-	if len(resourcesNames) > 0 {
-		//fmt.Println("resourcesNames length:", len(resourcesNames))
-		logboek.Context(context.Background()).Log(t.Render())
-	}
-}
-
-func (mt *multitracker) displayChildPodsStatusProgress(t *utils.Table, prevPods map[string]pod.PodStatus, pods map[string]pod.PodStatus, newPodsNames []string, failMode FailMode, showProgress, disableWarningColors bool) *utils.Table {
-	st := t.SubTable(statusProgressSubTableRatio...)
-	st.Header("POD", "READY", "RESTARTS", "STATUS", "AGE", "NODEIP")
-
-	podsNames := []string{}
-	for podName := range pods {
-		podsNames = append(podsNames, podName)
+		status := mt.JobsStatuses[name]
+		progress.Jobs = append(progress.Jobs, JobStatusProgress{Spec: mt.JobsSpecs[name], Status: status, PrevStatus: mt.PrevJobsStatuses[name]})
+		mt.PrevJobsStatuses[name] = status
 	}
-	sort.Strings(podsNames)
-
-	var podRows [][]interface{}
-
-	for _, podName := range podsNames {
-		var podRow []interface{}
-
-		isPodNew := false
-		for _, newPodName := range newPodsNames {
-			if newPodName == podName {
-				isPodNew = true
-			}
+	for _, name := range sortedSpecsNames(mt.CanariesSpecs) {
+		if mt.TrackingCanaries[name].Status == resourceWaiting {
+			continue
 		}
-
-		prevPodStatus := prevPods[podName]
-		podStatus := pods[podName]
-
-		isReady := false
-		if podStatus.StatusIndicator != nil {
-			isReady = podStatus.StatusIndicator.IsReady()
+		status := mt.CanariesStatuses[name]
+		progress.Canaries = append(progress.Canaries, CanaryStatusProgress{Spec: mt.CanariesSpecs[name], Status: status, PrevStatus: mt.PrevCanariesStatuses[name]})
+		mt.PrevCanariesStatuses[name] = status
+	}
+	for _, key := range sortedSpecsNames(mt.GenericSpecs) {
+		if mt.TrackingGenerics[key].Status == resourceWaiting {
+			continue
 		}
-
-		resource := formatResourceCaption(strings.Join(strings.Split(podName, "-")[1:], "-"), failMode, isReady, podStatus.IsFailed, isPodNew)
-
-		ready := fmt.Sprintf("%d/%d", podStatus.ReadyContainers, podStatus.TotalContainers)
-
-		status := "-"
-		if podStatus.StatusIndicator != nil {
-			status = podStatus.StatusIndicator.FormatTableElem(prevPodStatus.StatusIndicator, indicators.FormatTableElemOptions{
-				ShowProgress:         showProgress,
-				DisableWarningColors: disableWarningColors,
-				IsResourceNew:        isPodNew,
+		status := mt.GenericStatuses[key]
+		progress.Generics = append(progress.Generics, GenericStatusProgress{Kind: mt.GenericResources[key].Kind, Spec: mt.GenericSpecs[key], Status: status, PrevStatus: mt.PrevGenericStatuses[key]})
+		mt.PrevGenericStatuses[key] = status
+	}
+
+	return progress
+}
+
+func (mt *multitracker) collectResourcesResults() []ResourceResult {
+	var results []ResourceResult
+
+	for _, desc := range []struct {
+		Kind         string
+		Specs        map[string]MultitrackSpec
+		States       map[string]*multitrackerResourceState
+		PodsStatuses func(name string) map[string]pod.PodStatus
+	}{
+		{"deploy", mt.DeploymentsSpecs, mt.TrackingDeployments, func(name string) map[string]pod.PodStatus { return mt.DeploymentsStatuses[name].Pods }},
+		{"sts", mt.StatefulSetsSpecs, mt.TrackingStatefulSets, func(name string) map[string]pod.PodStatus { return mt.StatefulSetsStatuses[name].Pods }},
+		{"ds", mt.DaemonSetsSpecs, mt.TrackingDaemonSets, func(name string) map[string]pod.PodStatus { return mt.DaemonSetsStatuses[name].Pods }},
+		{"job", mt.JobsSpecs, mt.TrackingJobs, func(name string) map[string]pod.PodStatus { return mt.JobsStatuses[name].Pods }},
+		{"canary", mt.CanariesSpecs, mt.TrackingCanaries, func(name string) map[string]pod.PodStatus { return nil }},
+	} {
+		for _, name := range sortedSpecsNames(desc.Specs) {
+			state := desc.States[name]
+			results = append(results, ResourceResult{
+				Kind:              desc.Kind,
+				Spec:              desc.Specs[name],
+				IsFailed:          state.Status == resourceFailed,
+				FailedReason:      state.FailedReason,
+				ServiceMessages:   mt.serviceMessagesByResource[fmt.Sprintf("%s/%s", desc.Kind, name)],
+				UnschedulablePods: getUnschedulablePodsReports(desc.PodsStatuses(name)),
 			})
-			if status == "Running -> Terminating" {
-				status = "正在删除"
-			}
-			podRow = append(podRow, resource, ready, podStatus.Restarts, status, podStatus.Age, podStatus.HostIP)
-			if podStatus.IsFailed {
-				podRow = append(podRow, formatResourceError(disableWarningColors, podStatus.FailedReason))
-			}
-			podRows = append(podRows, podRow)
 		}
 	}
 
-	st.Rows(podRows...)
-
-	return &st
-}
-
-func formatResourceWarning(disableWarningColors bool, reason string) string {
-	msg := fmt.Sprintf("warning: %s", reason)
-	if disableWarningColors {
-		return msg
+	for _, key := range sortedSpecsNames(mt.GenericSpecs) {
+		state := mt.TrackingGenerics[key]
+		results = append(results, ResourceResult{
+			Kind:            mt.GenericResources[key].Kind,
+			Spec:            mt.GenericSpecs[key],
+			IsFailed:        state.Status == resourceFailed,
+			FailedReason:    state.FailedReason,
+			ServiceMessages: mt.serviceMessagesByResource[key],
+		})
 	}
-	return utils.YellowF("%s", msg)
-}
 
-func formatResourceError(disableWarningColors bool, reason string) string {
-	msg := fmt.Sprintf("error: %s", reason)
-	if disableWarningColors {
-		return msg
-	}
-	return utils.RedF("%s", msg)
+	return results
 }
 
-func formatResourceCaption(resourceCaption string, resourceFailMode FailMode, isReady bool, isFailed bool, isNew bool) string {
-	if !isNew {
-		return resourceCaption
-	}
-
-	switch resourceFailMode {
-	case FailWholeDeployProcessImmediately:
-		switch {
-		case isReady:
-			return utils.GreenF("%s", resourceCaption)
-		case isFailed:
-			return utils.RedF("%s", resourceCaption)
-		default:
-			return utils.YellowF("%s", resourceCaption)
-		}
-
-	case IgnoreAndContinueDeployProcess:
-		if isReady {
-			return utils.GreenF("%s", resourceCaption)
-		} else {
-			return resourceCaption
-		}
-
-	case HopeUntilEndOfDeployProcess:
-		if isReady {
-			return utils.GreenF("%s", resourceCaption)
-		} else {
-			return utils.YellowF("%s", resourceCaption)
-		}
-
-	default:
-		panic(fmt.Sprintf("unsupported resource fail mode '%s'", resourceFailMode))
+func sortedSpecsNames(specs map[string]MultitrackSpec) []string {
+	names := []string{}
+	for name := range specs {
+		names = append(names, name)
 	}
-}
-
-func podContainerLogChunkHeader(podName string, chunk *pod.ContainerLogChunk) string {
-	return fmt.Sprintf("po/%s container/%s", podName, chunk.ContainerName)
+	sort.Strings(names)
+	return names
 }
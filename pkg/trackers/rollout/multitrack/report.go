@@ -0,0 +1,253 @@
+package multitrack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/werf/kubedog/pkg/display"
+	"github.com/werf/kubedog/pkg/tracker/pod"
+)
+
+const defaultReportLogLinesCount = 30
+
+// MultitrackReport is the final result of the Multitrack call, which describes every tracked resource.
+type MultitrackReport struct {
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt time.Time        `json:"finishedAt"`
+	Resources  []ResourceReport `json:"resources"`
+
+	// Clusters contains results of the clusters of MultitrackClusters run.
+	Clusters []ClusterReport `json:"clusters,omitempty"`
+}
+
+// ClusterReport is the result of tracking in the cluster of MultitrackClusters run.
+type ClusterReport struct {
+	Name string `json:"name"`
+	// Status is succeeded, failed or cancelled, when tracking has been stopped because of other clusters failures.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ResourceReport struct {
+	// Cluster is set for the resources of MultitrackClusters run.
+	Cluster   string `json:"cluster,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+
+	// Status is the final tracking status of the resource:
+	// active, succeeded, failed, hoping or activeAfterHoping.
+	Status             string     `json:"status"`
+	ReadyAt            *time.Time `json:"readyAt,omitempty"`
+	TimeToReadySeconds float64    `json:"timeToReadySeconds,omitempty"`
+	FailedAt           *time.Time `json:"failedAt,omitempty"`
+	FailedReason       string     `json:"failedReason,omitempty"`
+	FailuresCount      int        `json:"failuresCount"`
+
+	PodsRestarts         map[string]int32         `json:"podsRestarts,omitempty"`
+	FailedContainersLogs []ContainerLogReport     `json:"failedContainersLogs,omitempty"`
+	UnschedulablePods    []UnschedulablePodReport `json:"unschedulablePods,omitempty"`
+	Events               []string                 `json:"events,omitempty"`
+
+	// Rollback is set for the failed resources with MultitrackSpec.RollbackOnFailure.
+	Rollback *RollbackReport `json:"rollback,omitempty"`
+
+	// SpecFromAnnotations contains kubedog/* annotations of the live resource, which were applied to the spec.
+	SpecFromAnnotations map[string]string `json:"specFromAnnotations,omitempty"`
+
+	// DurationSeconds is the time spent on tracking of the resource until it became ready, failed or tracking has finished.
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// ContainerLogReport contains last log lines of the failed container.
+type ContainerLogReport struct {
+	Pod       string   `json:"pod"`
+	Container string   `json:"container"`
+	Lines     []string `json:"lines"`
+}
+
+// UnschedulablePodReport explains why the pod has not been scheduled by the time tracking has finished.
+type UnschedulablePodReport struct {
+	Pod              string `json:"pod"`
+	SchedulerMessage string `json:"schedulerMessage"`
+	Diagnosis        string `json:"diagnosis"`
+}
+
+func (report MultitrackReport) HasFailedResources() bool {
+	for _, res := range report.Resources {
+		if res.Status == formatReportResourceStatus(resourceFailed) {
+			return true
+		}
+	}
+	return false
+}
+
+type containerRef struct {
+	PodName       string
+	ContainerName string
+}
+
+func (mt *multitracker) addResourceEvent(resourceKind string, spec MultitrackSpec, msg string) {
+	resource := fmt.Sprintf("%s/%s", resourceKind, spec.ResourceName)
+	mt.eventsByResource[resource] = append(mt.eventsByResource[resource], msg)
+}
+
+func (mt *multitracker) addResourceLogLines(resourceKind string, spec MultitrackSpec, podName, containerName string, lines []display.LogLine) {
+	resource := fmt.Sprintf("%s/%s", resourceKind, spec.ResourceName)
+	if mt.logLinesByResource[resource] == nil {
+		mt.logLinesByResource[resource] = make(map[containerRef][]string)
+	}
+
+	ref := containerRef{PodName: podName, ContainerName: containerName}
+	containerLines := mt.logLinesByResource[resource][ref]
+	for _, line := range lines {
+		containerLines = append(containerLines, line.Message)
+	}
+	if len(containerLines) > mt.reportLogLinesCount {
+		containerLines = containerLines[len(containerLines)-mt.reportLogLinesCount:]
+	}
+	mt.logLinesByResource[resource][ref] = containerLines
+}
+
+func (mt *multitracker) addResourceFailedContainer(resourceKind string, spec MultitrackSpec, podError pod.PodError) {
+	resource := fmt.Sprintf("%s/%s", resourceKind, spec.ResourceName)
+	ref := containerRef{PodName: podError.PodName, ContainerName: podError.ContainerName}
+
+	for _, failedRef := range mt.failedContainersByResource[resource] {
+		if failedRef == ref {
+			return
+		}
+	}
+	mt.failedContainersByResource[resource] = append(mt.failedContainersByResource[resource], ref)
+}
+
+func (mt *multitracker) getReport() MultitrackReport {
+	mt.mux.Lock()
+	defer mt.mux.Unlock()
+
+	report := MultitrackReport{
+		StartedAt:  mt.startedAt,
+		FinishedAt: time.Now(),
+	}
+
+	for _, desc := range []struct {
+		Kind         string
+		Specs        map[string]MultitrackSpec
+		States       map[string]*multitrackerResourceState
+		PodsStatuses func(name string) map[string]pod.PodStatus
+	}{
+		{"deploy", mt.DeploymentsSpecs, mt.TrackingDeployments, func(name string) map[string]pod.PodStatus { return mt.DeploymentsStatuses[name].Pods }},
+		{"sts", mt.StatefulSetsSpecs, mt.TrackingStatefulSets, func(name string) map[string]pod.PodStatus { return mt.StatefulSetsStatuses[name].Pods }},
+		{"ds", mt.DaemonSetsSpecs, mt.TrackingDaemonSets, func(name string) map[string]pod.PodStatus { return mt.DaemonSetsStatuses[name].Pods }},
+		{"job", mt.JobsSpecs, mt.TrackingJobs, func(name string) map[string]pod.PodStatus { return mt.JobsStatuses[name].Pods }},
+		{"canary", mt.CanariesSpecs, mt.TrackingCanaries, func(name string) map[string]pod.PodStatus { return nil }},
+	} {
+		for _, name := range sortedSpecsNames(desc.Specs) {
+			report.Resources = append(report.Resources, mt.newResourceReport(desc.Kind, desc.Specs[name], desc.States[name], desc.PodsStatuses(name), report.FinishedAt))
+		}
+	}
+
+	for _, key := range sortedSpecsNames(mt.GenericSpecs) {
+		report.Resources = append(report.Resources, mt.newResourceReport(mt.GenericResources[key].Kind, mt.GenericSpecs[key], mt.TrackingGenerics[key], nil, report.FinishedAt))
+	}
+
+	return report
+}
+
+func (mt *multitracker) newResourceReport(kind string, spec MultitrackSpec, state *multitrackerResourceState, podsStatuses map[string]pod.PodStatus, finishedAt time.Time) ResourceReport {
+	resource := fmt.Sprintf("%s/%s", kind, spec.ResourceName)
+
+	res := ResourceReport{
+		Kind:          kind,
+		Name:          spec.ResourceName,
+		Namespace:     spec.Namespace,
+		Status:        formatReportResourceStatus(state.Status),
+		FailedReason:  state.FailedReason,
+		FailuresCount: state.FailuresCount,
+		Events:        mt.eventsByResource[resource],
+
+		SpecFromAnnotations: mt.specsFromAnnotations[resource],
+	}
+
+	if !state.ReadyAt.IsZero() {
+		readyAt := state.ReadyAt
+		res.ReadyAt = &readyAt
+		res.TimeToReadySeconds = state.getTrackingSeconds(readyAt)
+	}
+
+	switch {
+	case res.ReadyAt != nil:
+		res.DurationSeconds = res.TimeToReadySeconds
+	case !state.FailedAt.IsZero():
+		failedAt := state.FailedAt
+		res.FailedAt = &failedAt
+		res.DurationSeconds = state.getTrackingSeconds(failedAt)
+	default:
+		res.DurationSeconds = state.getTrackingSeconds(finishedAt)
+	}
+
+	for podName, podStatus := range podsStatuses {
+		if res.PodsRestarts == nil {
+			res.PodsRestarts = make(map[string]int32)
+		}
+		res.PodsRestarts[podName] = podStatus.Restarts
+	}
+
+	res.UnschedulablePods = getUnschedulablePodsReports(podsStatuses)
+
+	for _, ref := range mt.failedContainersByResource[resource] {
+		res.FailedContainersLogs = append(res.FailedContainersLogs, ContainerLogReport{
+			Pod:       ref.PodName,
+			Container: ref.ContainerName,
+			Lines:     append([]string{}, mt.logLinesByResource[resource][ref]...),
+		})
+	}
+	sort.Slice(res.FailedContainersLogs, func(i, j int) bool {
+		if res.FailedContainersLogs[i].Pod != res.FailedContainersLogs[j].Pod {
+			return res.FailedContainersLogs[i].Pod < res.FailedContainersLogs[j].Pod
+		}
+		return res.FailedContainersLogs[i].Container < res.FailedContainersLogs[j].Container
+	})
+
+	return res
+}
+
+// getTrackingSeconds returns the time since the tracker of the resource has started, the resource,
+// which tracker has not started, e.g. waiting for dependencies, has not been tracked at all.
+func (state *multitrackerResourceState) getTrackingSeconds(until time.Time) float64 {
+	if state.StartedAt.IsZero() || until.Before(state.StartedAt) {
+		return 0
+	}
+	return until.Sub(state.StartedAt).Seconds()
+}
+
+func formatReportResourceStatus(status multitrackerResourceStatus) string {
+	s := strings.TrimPrefix(string(status), "resource")
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func getUnschedulablePodsReports(podsStatuses map[string]pod.PodStatus) []UnschedulablePodReport {
+	var res []UnschedulablePodReport
+	for podName, podStatus := range podsStatuses {
+		if podStatus.SchedulingDiagnosis == nil {
+			continue
+		}
+
+		res = append(res, UnschedulablePodReport{
+			Pod:              podName,
+			SchedulerMessage: podStatus.SchedulingDiagnosis.SchedulerMessage,
+			Diagnosis:        podStatus.SchedulingDiagnosis.Summary(),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Pod < res[j].Pod
+	})
+
+	return res
+}
@@ -0,0 +1,105 @@
+package multitrack
+
+import (
+	"github.com/werf/kubedog/pkg/display"
+	"github.com/werf/kubedog/pkg/tracker/canary"
+	"github.com/werf/kubedog/pkg/tracker/daemonset"
+	"github.com/werf/kubedog/pkg/tracker/deployment"
+	"github.com/werf/kubedog/pkg/tracker/generic"
+	"github.com/werf/kubedog/pkg/tracker/job"
+	"github.com/werf/kubedog/pkg/tracker/statefulset"
+)
+
+// Reporter receives the whole tracking stream of the multitracker.
+// Multitracker calls Reporter methods sequentially, never concurrently.
+type Reporter interface {
+	// ResourceMessage is called on resource state changes (added, ready, succeeded)
+	// and on tracker service messages (e.g. new replicaset or pod added).
+	ResourceMessage(msgType display.EventType, resourceKind string, spec MultitrackSpec, msg string)
+	// ResourceEvent is called on kubernetes event related to the resource.
+	ResourceEvent(resourceKind string, spec MultitrackSpec, msg string)
+	// ResourceError is called on resource or resource pod failure.
+	ResourceError(resourceKind string, spec MultitrackSpec, reason string)
+	// ResourceLogChunk is called on container log lines filtered according to the spec.
+	ResourceLogChunk(resourceKind string, spec MultitrackSpec, podName, containerName string, lines []display.LogLine)
+	// MultitrackMessage is called on messages not related to a single resource.
+	MultitrackMessage(msg string)
+	// StatusProgress is called periodically with the statuses of all tracked resources.
+	StatusProgress(progress StatusProgress)
+	// TrackingFinished is called once when tracking of all resources is done or failed.
+	TrackingFinished(results []ResourceResult)
+}
+
+// ClusterReporter is implemented by reporters, which distinguish the output of the clusters of MultitrackClusters run.
+// Reporters of all clusters are called sequentially, never concurrently.
+type ClusterReporter interface {
+	Reporter
+	// ForCluster returns the reporter of the cluster, which writes into the same output.
+	ForCluster(cluster string) Reporter
+}
+
+type StatusProgress struct {
+	Deployments  []DeploymentStatusProgress
+	StatefulSets []StatefulSetStatusProgress
+	DaemonSets   []DaemonSetStatusProgress
+	Jobs         []JobStatusProgress
+	Canaries     []CanaryStatusProgress
+	Generics     []GenericStatusProgress
+	// Waiting are resources, which tracking has not been started until their dependencies are ready.
+	Waiting []WaitingStatusProgress
+}
+
+type DeploymentStatusProgress struct {
+	Spec       MultitrackSpec
+	Status     deployment.DeploymentStatus
+	PrevStatus deployment.DeploymentStatus
+}
+
+type StatefulSetStatusProgress struct {
+	Spec       MultitrackSpec
+	Status     statefulset.StatefulSetStatus
+	PrevStatus statefulset.StatefulSetStatus
+}
+
+type DaemonSetStatusProgress struct {
+	Spec       MultitrackSpec
+	Status     daemonset.DaemonSetStatus
+	PrevStatus daemonset.DaemonSetStatus
+}
+
+type JobStatusProgress struct {
+	Spec       MultitrackSpec
+	Status     job.JobStatus
+	PrevStatus job.JobStatus
+}
+
+type CanaryStatusProgress struct {
+	Spec       MultitrackSpec
+	Status     canary.CanaryStatus
+	PrevStatus canary.CanaryStatus
+}
+
+type GenericStatusProgress struct {
+	// Kind is the resource kind in the kubectl format, e.g. certificates.cert-manager.io.
+	Kind       string
+	Spec       MultitrackSpec
+	Status     generic.ResourceStatus
+	PrevStatus generic.ResourceStatus
+}
+
+type WaitingStatusProgress struct {
+	Kind string
+	Spec MultitrackSpec
+	// WaitingFor are "kind/name" keys of the dependencies, which are not ready yet.
+	WaitingFor []string
+}
+
+type ResourceResult struct {
+	Kind            string
+	Spec            MultitrackSpec
+	IsFailed        bool
+	FailedReason    string
+	ServiceMessages []string
+	// UnschedulablePods explain why pods of the resource have not been scheduled.
+	UnschedulablePods []UnschedulablePodReport
+}
@@ -0,0 +1,358 @@
+package multitrack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/werf/kubedog/pkg/utils"
+)
+
+const (
+	rollbackSucceeded = "succeeded"
+	rollbackFailed    = "failed"
+)
+
+// RollbackReport is the result of the rollback of the failed resource with MultitrackSpec.RollbackOnFailure.
+type RollbackReport struct {
+	// Revision is the previous revision of the resource, which has been restored.
+	Revision int64 `json:"revision,omitempty"`
+	// Status is succeeded, when the resource is ready after the rollback, or failed.
+	Status       string `json:"status"`
+	FailedReason string `json:"failedReason,omitempty"`
+	// DurationSeconds is the time spent on the rollback and its tracking.
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+type rollbackResource struct {
+	Kind        string
+	Spec        MultitrackSpec
+	ReportIndex int
+	Revision    int64
+}
+
+func isRollbackOnFailureEnabled(spec MultitrackSpec) bool {
+	return spec.RollbackOnFailure != nil && *spec.RollbackOnFailure
+}
+
+// rollbackFailedResources rolls back the failed Deployments, StatefulSets and DaemonSets with RollbackOnFailure
+// to their previous revisions and tracks the rollback until the resources are ready. Results of the rollback are
+// added to the report and to the error of the run.
+func (mt *multitracker) rollbackFailedResources(kube kubernetes.Interface, opts MultitrackOptions, report MultitrackReport, trackErr error) (MultitrackReport, error) {
+	parentContext := opts.ParentContext
+	if parentContext == nil {
+		parentContext = context.Background()
+	}
+
+	var resources []*rollbackResource
+	mt.mux.Lock()
+	for i, res := range report.Resources {
+		if res.Status != formatReportResourceStatus(resourceFailed) {
+			continue
+		}
+
+		var spec MultitrackSpec
+		switch res.Kind {
+		case "deploy":
+			spec = mt.DeploymentsSpecs[res.Name]
+		case "sts":
+			spec = mt.StatefulSetsSpecs[res.Name]
+		case "ds":
+			spec = mt.DaemonSetsSpecs[res.Name]
+		default:
+			continue
+		}
+
+		if isRollbackOnFailureEnabled(spec) {
+			resources = append(resources, &rollbackResource{Kind: res.Kind, Spec: spec, ReportIndex: i})
+		}
+	}
+
+	// Rollback is not started when the run has been stopped by the caller
+	if len(resources) == 0 || parentContext.Err() != nil {
+		mt.mux.Unlock()
+		return report, trackErr
+	}
+
+	mt.stopTrackers()
+	mt.mux.Unlock()
+
+	startedAt := time.Now()
+	var rolledBackSpecs MultitrackSpecs
+	for _, res := range resources {
+		revision, err := rollbackToPreviousRevision(parentContext, kube, res.Kind, res.Spec)
+		if err != nil {
+			mt.displayRollbackMessageF("Unable to roll back %s/%s: %s\n", res.Kind, res.Spec.ResourceName, err)
+			report.Resources[res.ReportIndex].Rollback = &RollbackReport{
+				Status:          rollbackFailed,
+				FailedReason:    err.Error(),
+				DurationSeconds: time.Since(startedAt).Seconds(),
+			}
+			continue
+		}
+		res.Revision = revision
+
+		mt.displayRollbackMessageF("%s/%s has been rolled back to revision %d: tracking rollback\n", res.Kind, res.Spec.ResourceName, revision)
+
+		spec := res.Spec
+		spec.RollbackOnFailure = nil
+		spec.DependsOn = nil
+		switch res.Kind {
+		case "deploy":
+			rolledBackSpecs.Deployments = append(rolledBackSpecs.Deployments, spec)
+		case "sts":
+			rolledBackSpecs.StatefulSets = append(rolledBackSpecs.StatefulSets, spec)
+		case "ds":
+			rolledBackSpecs.DaemonSets = append(rolledBackSpecs.DaemonSets, spec)
+		}
+	}
+
+	var rollbackReport MultitrackReport
+	var rollbackErr error
+	if len(rolledBackSpecs.Deployments)+len(rolledBackSpecs.StatefulSets)+len(rolledBackSpecs.DaemonSets) > 0 {
+		rollbackOpts := opts
+		rollbackOpts.ParentContext = parentContext
+		rollbackOpts.Informers = mt.informers
+		// Stopped trackers of the run may still report while terminating, so calls of the shared reporter are serialized
+		rollbackOpts.Reporter = &clusterReporter{reporter: mt.reporter, mux: &mt.mux}
+		// Specs are already merged with annotations, diagnostics and metrics describe the failed run
+		rollbackOpts.UseResourceAnnotations = false
+		rollbackOpts.DiagnosticsBundlePath = ""
+		rollbackOpts.Metrics = nil
+
+		rollbackReport, rollbackErr = multitrack(kube, rolledBackSpecs, rollbackOpts, false)
+	}
+
+	var msgs []string
+	for _, res := range resources {
+		resourceReport := &report.Resources[res.ReportIndex]
+		if resourceReport.Rollback != nil {
+			msgs = append(msgs, fmt.Sprintf("%s/%s rollback failed: %s", res.Kind, res.Spec.ResourceName, resourceReport.Rollback.FailedReason))
+			continue
+		}
+
+		rollback := &RollbackReport{
+			Revision:        res.Revision,
+			Status:          rollbackFailed,
+			DurationSeconds: rollbackReport.FinishedAt.Sub(startedAt).Seconds(),
+		}
+		status := ""
+		for _, rolledBack := range rollbackReport.Resources {
+			if rolledBack.Kind != res.Kind || rolledBack.Name != res.Spec.ResourceName {
+				continue
+			}
+
+			status = rolledBack.Status
+			if rolledBack.Status == formatReportResourceStatus(resourceSucceeded) {
+				rollback.Status = rollbackSucceeded
+				rollback.DurationSeconds = rolledBack.ReadyAt.Sub(startedAt).Seconds()
+			} else {
+				rollback.FailedReason = rolledBack.FailedReason
+			}
+		}
+		if rollback.Status == rollbackFailed && rollback.FailedReason == "" {
+			if rollbackErr != nil {
+				rollback.FailedReason = rollbackErr.Error()
+			} else {
+				rollback.FailedReason = fmt.Sprintf("tracking finished with resource status %q", status)
+			}
+		}
+		resourceReport.Rollback = rollback
+
+		if rollback.Status == rollbackSucceeded {
+			msgs = append(msgs, fmt.Sprintf("%s/%s has been rolled back to revision %d", res.Kind, res.Spec.ResourceName, res.Revision))
+		} else {
+			msgs = append(msgs, fmt.Sprintf("%s/%s rollback to revision %d failed: %s", res.Kind, res.Spec.ResourceName, res.Revision, rollback.FailedReason))
+		}
+	}
+
+	if trackErr == nil {
+		return report, nil
+	}
+	return report, fmt.Errorf("%s\n%s", trackErr, strings.Join(msgs, "\n"))
+}
+
+// stopTrackers stops trackers of other resources of the failed run before the rollback, should be called with locked mux.
+func (mt *multitracker) stopTrackers() {
+	mt.isTerminating = true
+	mt.dropWaitingResources()
+
+	for _, contexts := range []map[string]*multitrackerContext{mt.DeploymentsContexts, mt.StatefulSetsContexts, mt.DaemonSetsContexts, mt.JobsContexts, mt.CanariesContexts, mt.GenericContexts} {
+		for _, ctx := range contexts {
+			ctx.CancelFunc()
+		}
+	}
+}
+
+// displayRollbackMessageF is called when all trackers of the run are done, so it locks mux itself.
+func (mt *multitracker) displayRollbackMessageF(format string, a ...interface{}) {
+	mt.mux.Lock()
+	defer mt.mux.Unlock()
+	mt.displayMultitrackServiceMessageF(format, a...)
+}
+
+// rollbackToPreviousRevision reverts the resource to its previous revision and returns the number of the revision.
+func rollbackToPreviousRevision(ctx context.Context, kube kubernetes.Interface, kind string, spec MultitrackSpec) (int64, error) {
+	switch kind {
+	case "deploy":
+		return rollbackDeployment(ctx, kube, spec)
+	case "sts":
+		obj, err := kube.AppsV1().StatefulSets(spec.Namespace).Get(ctx, spec.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+
+		revision, err := getPreviousControllerRevision(ctx, kube, obj.Namespace, obj.Spec.Selector, obj.UID, obj.Status.UpdateRevision, obj.Spec.Template)
+		if err != nil {
+			return 0, err
+		}
+
+		// Data of the revision is the strategic merge patch, which restores the pod template
+		if _, err := kube.AppsV1().StatefulSets(obj.Namespace).Patch(ctx, obj.Name, types.StrategicMergePatchType, revision.Data.Raw, metav1.PatchOptions{}); err != nil {
+			return 0, err
+		}
+		return revision.Revision, nil
+	case "ds":
+		obj, err := kube.AppsV1().DaemonSets(spec.Namespace).Get(ctx, spec.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+
+		revision, err := getPreviousControllerRevision(ctx, kube, obj.Namespace, obj.Spec.Selector, obj.UID, "", obj.Spec.Template)
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := kube.AppsV1().DaemonSets(obj.Namespace).Patch(ctx, obj.Name, types.StrategicMergePatchType, revision.Data.Raw, metav1.PatchOptions{}); err != nil {
+			return 0, err
+		}
+		return revision.Revision, nil
+	default:
+		return 0, fmt.Errorf("rollback of %s is not supported", kind)
+	}
+}
+
+// rollbackDeployment sets the pod template of the Deployment to the template of the ReplicaSet of the previous revision.
+func rollbackDeployment(ctx context.Context, kube kubernetes.Interface, spec MultitrackSpec) (int64, error) {
+	var revision int64
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := kube.AppsV1().Deployments(spec.Namespace).Get(ctx, spec.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if obj.Spec.Paused {
+			return fmt.Errorf("deployment is paused")
+		}
+
+		_, allOldRSs, _, err := utils.GetAllReplicaSets(ctx, obj, kube)
+		if err != nil {
+			return err
+		}
+
+		var previousRS *appsv1.ReplicaSet
+		revision = 0
+		for _, rs := range allOldRSs {
+			rsRevision, err := utils.Revision(rs)
+			if err != nil {
+				return fmt.Errorf("cannot get the revision of replicaset %q: %s", rs.Name, err)
+			}
+			if rsRevision > revision {
+				previousRS, revision = rs, rsRevision
+			}
+		}
+		if previousRS == nil {
+			return fmt.Errorf("no previous revision found")
+		}
+
+		template := previousRS.Spec.Template.DeepCopy()
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		obj.Spec.Template = *template
+
+		_, err = kube.AppsV1().Deployments(obj.Namespace).Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+
+	return revision, err
+}
+
+// getPreviousControllerRevision returns the newest revision of the StatefulSet or DaemonSet, which differs from the current failed revision.
+// The current revision is identified by currentRevisionName, or by the pod template when the name is not known (DaemonSet has no status field for it).
+func getPreviousControllerRevision(ctx context.Context, kube kubernetes.Interface, namespace string, selector *metav1.LabelSelector, uid types.UID, currentRevisionName string, template corev1.PodTemplateSpec) (*appsv1.ControllerRevision, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := kube.AppsV1().ControllerRevisions(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []*appsv1.ControllerRevision
+	for i := range list.Items {
+		if controllerRef := utils.GetControllerOf(&list.Items[i]); controllerRef != nil && controllerRef.UID == uid {
+			revisions = append(revisions, &list.Items[i])
+		}
+	}
+
+	if currentRevisionName == "" {
+		current, err := findControllerRevisionByTemplate(revisions, template)
+		if err != nil {
+			return nil, err
+		}
+		currentRevisionName = current.Name
+	}
+
+	return selectPreviousControllerRevision(revisions, currentRevisionName)
+}
+
+// selectPreviousControllerRevision returns the revision with the highest number among revisions other than the current one.
+// The current revision is not necessarily the newest one: it can be an older revision restored by a rollback, or revisions can be stale with OnDelete update strategy.
+func selectPreviousControllerRevision(revisions []*appsv1.ControllerRevision, currentRevisionName string) (*appsv1.ControllerRevision, error) {
+	var previous *appsv1.ControllerRevision
+	for _, revision := range revisions {
+		if revision.Name == currentRevisionName {
+			continue
+		}
+		if previous == nil || revision.Revision > previous.Revision {
+			previous = revision
+		}
+	}
+
+	if previous == nil {
+		return nil, fmt.Errorf("no revision other than the current revision %q found", currentRevisionName)
+	}
+	return previous, nil
+}
+
+// findControllerRevisionByTemplate returns the revision, which restores the given pod template.
+func findControllerRevisionByTemplate(revisions []*appsv1.ControllerRevision, template corev1.PodTemplateSpec) (*appsv1.ControllerRevision, error) {
+	for _, revision := range revisions {
+		// Data of the revision is {"spec":{"template":{...,"$patch":"replace"}}}
+		var data struct {
+			Spec struct {
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(revision.Data.Raw, &data); err != nil {
+			return nil, fmt.Errorf("cannot decode controller revision %q: %s", revision.Name, err)
+		}
+
+		if apiequality.Semantic.DeepEqual(data.Spec.Template, template) {
+			return revision, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot find the controller revision of the current pod template")
+}
@@ -0,0 +1,118 @@
+package multitrack
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newControllerRevision(t *testing.T, name string, revision int64, image string) *appsv1.ControllerRevision {
+	template := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]string{"app": "app"}},
+		"spec": map[string]interface{}{
+			"containers": []map[string]string{{"name": "app", "image": image}},
+		},
+		"$patch": "replace",
+	}
+	raw, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": template}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Revision:   revision,
+		Data:       runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestSelectPreviousControllerRevision(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		revisions   []int64
+		current     string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:      "current is the newest",
+			revisions: []int64{1, 2, 3},
+			current:   "rev-3",
+			expected:  "rev-2",
+		},
+		{
+			name:      "current is restored by an earlier rollback",
+			revisions: []int64{1, 2, 3},
+			current:   "rev-1",
+			expected:  "rev-3",
+		},
+		{
+			name:      "order of revisions does not matter",
+			revisions: []int64{3, 1, 2},
+			current:   "rev-2",
+			expected:  "rev-3",
+		},
+		{
+			name:        "only the current revision",
+			revisions:   []int64{1},
+			current:     "rev-1",
+			expectError: true,
+		},
+		{
+			name:        "no revisions",
+			current:     "rev-1",
+			expectError: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var revisions []*appsv1.ControllerRevision
+			for _, n := range tc.revisions {
+				revisions = append(revisions, newControllerRevision(t, fmt.Sprintf("rev-%d", n), n, "app:1"))
+			}
+
+			revision, err := selectPreviousControllerRevision(revisions, tc.current)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got revision %q", revision.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if revision.Name != tc.expected {
+				t.Errorf("expected revision %q, got %q", tc.expected, revision.Name)
+			}
+		})
+	}
+}
+
+func TestFindControllerRevisionByTemplate(t *testing.T) {
+	revisions := []*appsv1.ControllerRevision{
+		newControllerRevision(t, "rev-1", 1, "app:1"),
+		newControllerRevision(t, "rev-2", 2, "app:2"),
+	}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "app"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:1"}}},
+	}
+
+	revision, err := findControllerRevisionByTemplate(revisions, template)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if revision.Name != "rev-1" {
+		t.Errorf("expected revision rev-1, got %q", revision.Name)
+	}
+
+	template.Spec.Containers[0].Image = "app:3"
+	if _, err := findControllerRevisionByTemplate(revisions, template); err == nil {
+		t.Error("expected error for the template without revision")
+	}
+}
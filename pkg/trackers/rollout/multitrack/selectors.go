@@ -0,0 +1,366 @@
+package multitrack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/werf/kubedog/pkg/tracker"
+	"github.com/werf/kubedog/pkg/tracker/debug"
+	"github.com/werf/kubedog/pkg/tracker/informer"
+)
+
+var selectorResourcesKinds = []string{"deploy", "sts", "ds", "job"}
+
+// MultitrackSelectorSpec describes resources, which are discovered by the label selector.
+// Resources matching the selector are tracked, including ones created while tracking runs.
+type MultitrackSelectorSpec struct {
+	Namespace     string
+	LabelSelector string
+
+	// Kinds limits kinds of the discovered resources: deploy, sts, ds and job. All kinds are discovered by default.
+	Kinds []string
+
+	// Template is the spec for every discovered resource, ResourceName and Namespace are set automatically.
+	Template MultitrackSpec
+}
+
+func (selector MultitrackSelectorSpec) getKinds() []string {
+	if len(selector.Kinds) == 0 {
+		return selectorResourcesKinds
+	}
+	return selector.Kinds
+}
+
+func (selector MultitrackSelectorSpec) newResourceSpec(name string) MultitrackSpec {
+	spec := selector.Template
+	spec.ResourceName = name
+	spec.Namespace = selector.Namespace
+
+	if spec.AllowFailuresCount != nil {
+		allowFailuresCount := *spec.AllowFailuresCount
+		spec.AllowFailuresCount = &allowFailuresCount
+	}
+	if spec.FailureThresholdSeconds != nil {
+		failureThresholdSeconds := *spec.FailureThresholdSeconds
+		spec.FailureThresholdSeconds = &failureThresholdSeconds
+	}
+
+	return spec
+}
+
+func validateSelectorSpec(field string, selector MultitrackSelectorSpec) []string {
+	var errs []string
+
+	if _, err := labels.Parse(selector.LabelSelector); err != nil {
+		errs = append(errs, fmt.Sprintf("%s.LabelSelector: %s", field, err))
+	}
+
+	for i, kind := range selector.Kinds {
+		isKnown := false
+		for _, knownKind := range selectorResourcesKinds {
+			if kind == knownKind {
+				isKnown = true
+			}
+		}
+
+		if !isKnown {
+			errs = append(errs, fmt.Sprintf("%s.Kinds[%d]: invalid value %q, expected one of: %s", field, i, kind, strings.Join(selectorResourcesKinds, ", ")))
+		}
+	}
+
+	if selector.Template.ResourceName != "" || selector.Template.Namespace != "" {
+		errs = append(errs, fmt.Sprintf("%s.Template: ResourceName and Namespace should not be set", field))
+	}
+	errs = append(errs, validateSpecModes(fmt.Sprintf("%s.Template", field), selector.Template)...)
+
+	return errs
+}
+
+// discoverSelectorsResources adds specs for the resources currently matching the selectors, which are not specified explicitly.
+func discoverSelectorsResources(ctx context.Context, kube kubernetes.Interface, specs *MultitrackSpecs) error {
+	for _, selector := range specs.Selectors {
+		for _, kind := range selector.getKinds() {
+			lw, _ := newSelectorListWatch(ctx, kube, kind, selector)
+
+			list, err := lw.List(metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to list %s by selector %q: %s", kind, selector.LabelSelector, err)
+			}
+
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				return err
+			}
+
+			for _, item := range items {
+				accessor, err := meta.Accessor(item)
+				if err != nil {
+					return err
+				}
+
+				specsList := getSpecsListByKind(specs, kind)
+				if hasSpecWithName(*specsList, accessor.GetName()) {
+					continue
+				}
+				*specsList = append(*specsList, selector.newResourceSpec(accessor.GetName()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// runSelectorsWatchers starts tracking of the resources, which start matching the selectors while tracking runs.
+func (mt *multitracker) runSelectorsWatchers(ctx context.Context, kube kubernetes.Interface, selectors []MultitrackSelectorSpec, useResourceAnnotations bool, watchRetryBudget int) {
+	for _, selector := range selectors {
+		for _, kind := range selector.getKinds() {
+			go mt.runSelectorWatcher(ctx, kube, kind, selector, useResourceAnnotations, watchRetryBudget)
+		}
+	}
+}
+
+func (mt *multitracker) runSelectorWatcher(ctx context.Context, kube kubernetes.Interface, kind string, selector MultitrackSelectorSpec, useResourceAnnotations bool, watchRetryBudget int) {
+	selectorLw, objType := newSelectorListWatch(ctx, kube, kind, selector)
+	lw := mt.informers.ListWatch(ctx, getInformerKind(kind), selector.Namespace, func(options metav1.ListOptions) metav1.ListOptions {
+		options.LabelSelector = selector.LabelSelector
+		return options
+	}, selectorLw)
+
+	selectorTracker := &tracker.Tracker{
+		Kube:             kube,
+		Namespace:        selector.Namespace,
+		FullResourceName: fmt.Sprintf("%s selector %q", kind, selector.LabelSelector),
+		WatchRetryBudget: watchRetryBudget,
+	}
+
+	_, err := selectorTracker.UntilWithSync(ctx, lw, objType, func(e watch.Event) (bool, error) {
+		switch e.Type {
+		case watch.Added:
+			accessor, err := meta.Accessor(e.Object)
+			if err != nil {
+				return true, err
+			}
+			mt.addSelectorResource(kind, selector, accessor, useResourceAnnotations)
+		case watch.Deleted:
+			if !mt.watchMode {
+				break
+			}
+
+			accessor, err := meta.Accessor(e.Object)
+			if err != nil {
+				return true, err
+			}
+			mt.removeSelectorResource(kind, accessor)
+		case watch.Error:
+			return true, fmt.Errorf("%s watch error: %v", kind, e.Object)
+		}
+
+		return false, nil
+	})
+
+	if err := tracker.AdaptInformerError(err); err != nil {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.displayMultitrackServiceMessageF("Unable to discover %s by selector %q: %s\n", kind, selector.LabelSelector, err)
+	}
+
+	if debug.Debug() {
+		fmt.Printf("      %s selector %q watcher DONE\n", kind, selector.LabelSelector)
+	}
+}
+
+func (mt *multitracker) addSelectorResource(kind string, selector MultitrackSelectorSpec, obj metav1.Object, useResourceAnnotations bool) {
+	mt.mux.Lock()
+	defer mt.mux.Unlock()
+
+	// New trackers could be started only while there are active trackers (or in watch mode),
+	// otherwise multitracker is already done
+	if mt.startResourceTracker == nil || (!mt.watchMode && mt.activeTrackersCount == 0) || mt.isFailed || mt.isTerminating {
+		return
+	}
+
+	if _, hasKey := mt.getSpecsByKind(kind)[obj.GetName()]; hasKey {
+		return
+	}
+
+	spec := selector.newResourceSpec(obj.GetName())
+
+	if useResourceAnnotations {
+		applied, err := MergeSpecWithAnnotations(&spec, obj.GetAnnotations())
+		if err != nil {
+			mt.displayMultitrackServiceMessageF("Skip %s/%s discovered by selector %q: %s\n", kind, spec.ResourceName, selector.LabelSelector, err)
+			return
+		}
+		if errs := validateSpecModes(fmt.Sprintf("%s/%s", kind, spec.ResourceName), spec); len(errs) > 0 {
+			mt.displayMultitrackServiceMessageF("Skip %s/%s discovered by selector %q: %s\n", kind, spec.ResourceName, selector.LabelSelector, strings.Join(errs, "; "))
+			return
+		}
+		if len(applied) > 0 {
+			mt.specsFromAnnotations[fmt.Sprintf("%s/%s", kind, spec.ResourceName)] = applied
+		}
+		if unknown := GetUnknownAnnotations(obj.GetAnnotations()); len(unknown) > 0 {
+			mt.displayMultitrackServiceMessageF("WARNING: unknown annotations of %s/%s are skipped: %s\n", kind, spec.ResourceName, strings.Join(unknown, ", "))
+		}
+	}
+
+	setDefaultSpecValues(&spec)
+
+	if mt.watchMode {
+		mt.displayMultitrackServiceMessageF("%s/%s added: start tracking\n", kind, spec.ResourceName)
+	} else {
+		mt.displayMultitrackServiceMessageF("%s/%s discovered by selector %q: start tracking\n", kind, spec.ResourceName, selector.LabelSelector)
+	}
+	mt.startResourceTracker(kind, spec)
+}
+
+func (mt *multitracker) removeSelectorResource(kind string, obj metav1.Object) {
+	mt.mux.Lock()
+	defer mt.mux.Unlock()
+
+	specs := mt.getSpecsByKind(kind)
+	if _, hasKey := specs[obj.GetName()]; !hasKey {
+		return
+	}
+
+	if mtCtx, hasKey := mt.getContextsByKind(kind)[obj.GetName()]; hasKey {
+		mtCtx.CancelFunc()
+	}
+	delete(specs, obj.GetName())
+
+	mt.displayMultitrackServiceMessageF("%s/%s deleted: stop tracking\n", kind, obj.GetName())
+}
+
+func (mt *multitracker) getContextsByKind(kind string) map[string]*multitrackerContext {
+	switch kind {
+	case "deploy":
+		return mt.DeploymentsContexts
+	case "sts":
+		return mt.StatefulSetsContexts
+	case "ds":
+		return mt.DaemonSetsContexts
+	case "job":
+		return mt.JobsContexts
+	case "canary":
+		return mt.CanariesContexts
+	default:
+		panic(fmt.Sprintf("unsupported resource kind %q", kind))
+	}
+}
+
+func (mt *multitracker) getSpecsByKind(kind string) map[string]MultitrackSpec {
+	switch kind {
+	case "deploy":
+		return mt.DeploymentsSpecs
+	case "sts":
+		return mt.StatefulSetsSpecs
+	case "ds":
+		return mt.DaemonSetsSpecs
+	case "job":
+		return mt.JobsSpecs
+	case "canary":
+		return mt.CanariesSpecs
+	default:
+		panic(fmt.Sprintf("unsupported resource kind %q", kind))
+	}
+}
+
+func getSpecsListByKind(specs *MultitrackSpecs, kind string) *[]MultitrackSpec {
+	switch kind {
+	case "deploy":
+		return &specs.Deployments
+	case "sts":
+		return &specs.StatefulSets
+	case "ds":
+		return &specs.DaemonSets
+	case "job":
+		return &specs.Jobs
+	case "canary":
+		return &specs.Canaries
+	default:
+		panic(fmt.Sprintf("unsupported resource kind %q", kind))
+	}
+}
+
+func hasSpecWithName(specs []MultitrackSpec, name string) bool {
+	for _, spec := range specs {
+		if spec.ResourceName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func getInformerKind(kind string) informer.Kind {
+	switch kind {
+	case "deploy":
+		return informer.Deployments
+	case "sts":
+		return informer.StatefulSets
+	case "ds":
+		return informer.DaemonSets
+	case "job":
+		return informer.Jobs
+	default:
+		panic(fmt.Sprintf("unsupported selector resource kind %q", kind))
+	}
+}
+
+func newSelectorListWatch(ctx context.Context, kube kubernetes.Interface, kind string, selector MultitrackSelectorSpec) (*cache.ListWatch, runtime.Object) {
+	tweakListOptions := func(options metav1.ListOptions) metav1.ListOptions {
+		options.LabelSelector = selector.LabelSelector
+		return options
+	}
+
+	switch kind {
+	case "deploy":
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.AppsV1().Deployments(selector.Namespace).List(ctx, tweakListOptions(options))
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.AppsV1().Deployments(selector.Namespace).Watch(ctx, tweakListOptions(options))
+			},
+		}, &appsv1.Deployment{}
+	case "sts":
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.AppsV1().StatefulSets(selector.Namespace).List(ctx, tweakListOptions(options))
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.AppsV1().StatefulSets(selector.Namespace).Watch(ctx, tweakListOptions(options))
+			},
+		}, &appsv1.StatefulSet{}
+	case "ds":
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.AppsV1().DaemonSets(selector.Namespace).List(ctx, tweakListOptions(options))
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.AppsV1().DaemonSets(selector.Namespace).Watch(ctx, tweakListOptions(options))
+			},
+		}, &appsv1.DaemonSet{}
+	case "job":
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return kube.BatchV1().Jobs(selector.Namespace).List(ctx, tweakListOptions(options))
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return kube.BatchV1().Jobs(selector.Namespace).Watch(ctx, tweakListOptions(options))
+			},
+		}, &batchv1.Job{}
+	default:
+		panic(fmt.Sprintf("unsupported selector resource kind %q", kind))
+	}
+}
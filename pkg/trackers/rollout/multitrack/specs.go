@@ -0,0 +1,347 @@
+package multitrack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+var specsFilesExtensions = []string{".yaml", ".yml", ".json"}
+
+// LoadSpecsFiles loads MultitrackSpecs from the YAML or JSON files. Every path may be a file,
+// a directory (all *.yaml, *.yml and *.json files in the directory are loaded) or a glob pattern.
+// Specs from all files and all documents of multi-document YAML files are merged and validated.
+func LoadSpecsFiles(paths []string) (MultitrackSpecs, error) {
+	files, err := expandSpecsFilesPaths(paths)
+	if err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	var allSpecs []MultitrackSpecs
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return MultitrackSpecs{}, fmt.Errorf("unable to read %s: %s", file, err)
+		}
+
+		specs, err := parseSpecsDocuments(data, file)
+		if err != nil {
+			return MultitrackSpecs{}, err
+		}
+		allSpecs = append(allSpecs, specs)
+	}
+
+	specs := MergeSpecs(allSpecs...)
+	if err := ValidateSpecs(specs); err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	return specs, nil
+}
+
+// ParseSpecs parses and validates MultitrackSpecs from the YAML or JSON data, which may contain multiple YAML documents.
+func ParseSpecs(data []byte) (MultitrackSpecs, error) {
+	specs, err := parseSpecsDocuments(data, "")
+	if err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	// Documents are validated one by one, resources duplicated across documents are checked after merge
+	if err := ValidateSpecs(specs); err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	return specs, nil
+}
+
+// MergeSpecs joins resources lists of all specified specs.
+func MergeSpecs(specsList ...MultitrackSpecs) MultitrackSpecs {
+	res := MultitrackSpecs{}
+	for _, specs := range specsList {
+		res.Deployments = append(res.Deployments, specs.Deployments...)
+		res.StatefulSets = append(res.StatefulSets, specs.StatefulSets...)
+		res.DaemonSets = append(res.DaemonSets, specs.DaemonSets...)
+		res.Jobs = append(res.Jobs, specs.Jobs...)
+		res.Canaries = append(res.Canaries, specs.Canaries...)
+		res.Generic = append(res.Generic, specs.Generic...)
+		res.Selectors = append(res.Selectors, specs.Selectors...)
+	}
+	return res
+}
+
+// ValidateSpecs checks that every spec has a resource name, known FailMode, TrackTerminationMode, CountedFailureTypes and FailurePolicy values,
+// that CountedFailureTypes, FailurePolicy and FailOnProgressDeadline do not contradict each other,
+// valid DependsOn references, that no resource is specified twice, that generic resources have GroupVersionResource or Kind,
+// that selectors are valid and that RollbackOnFailure is set only for Deployments, StatefulSets and DaemonSets.
+func ValidateSpecs(specs MultitrackSpecs) error {
+	var errs []string
+
+	for _, desc := range []struct {
+		Field string
+		Specs []MultitrackSpec
+	}{
+		{"Deployments", specs.Deployments},
+		{"StatefulSets", specs.StatefulSets},
+		{"DaemonSets", specs.DaemonSets},
+		{"Jobs", specs.Jobs},
+		{"Canaries", specs.Canaries},
+	} {
+		seen := make(map[string]int)
+
+		for i, spec := range desc.Specs {
+			field := fmt.Sprintf("%s[%d]", desc.Field, i)
+
+			if spec.ResourceName == "" {
+				errs = append(errs, fmt.Sprintf("%s: ResourceName is required", field))
+			} else {
+				// Multitracker identifies resources of the same kind by name only
+				if prev, hasKey := seen[spec.ResourceName]; hasKey {
+					errs = append(errs, fmt.Sprintf("%s: resource %q is already specified in %s[%d]", field, spec.ResourceName, desc.Field, prev))
+				} else {
+					seen[spec.ResourceName] = i
+				}
+			}
+
+			errs = append(errs, validateSpecModes(field, spec)...)
+
+			if isRollbackOnFailureEnabled(spec) && (desc.Field == "Jobs" || desc.Field == "Canaries") {
+				errs = append(errs, fmt.Sprintf("%s.RollbackOnFailure: rollback is supported only for Deployments, StatefulSets and DaemonSets", field))
+			}
+		}
+	}
+
+	seenGeneric := make(map[string]int)
+	for i, spec := range specs.Generic {
+		field := fmt.Sprintf("Generic[%d]", i)
+
+		var resource string
+		switch {
+		case spec.GroupVersionResource != nil && spec.Kind != "":
+			errs = append(errs, fmt.Sprintf("%s: only one of GroupVersionResource and Kind should be set", field))
+		case spec.GroupVersionResource != nil:
+			if spec.GroupVersionResource.Version == "" || spec.GroupVersionResource.Resource == "" {
+				errs = append(errs, fmt.Sprintf("%s.GroupVersionResource: Version and Resource are required", field))
+			}
+			resource = spec.GroupVersionResource.String()
+		case spec.Kind != "":
+			resource = spec.Kind
+		default:
+			errs = append(errs, fmt.Sprintf("%s: GroupVersionResource or Kind is required", field))
+		}
+
+		if spec.ResourceName == "" {
+			errs = append(errs, fmt.Sprintf("%s: ResourceName is required", field))
+		} else if resource != "" {
+			key := fmt.Sprintf("%s/%s", resource, spec.ResourceName)
+			if prev, hasKey := seenGeneric[key]; hasKey {
+				errs = append(errs, fmt.Sprintf("%s: resource %q is already specified in Generic[%d]", field, key, prev))
+			} else {
+				seenGeneric[key] = i
+			}
+		}
+
+		errs = append(errs, validateSpecModes(field, spec.MultitrackSpec)...)
+
+		if isRollbackOnFailureEnabled(spec.MultitrackSpec) {
+			errs = append(errs, fmt.Sprintf("%s.RollbackOnFailure: rollback is supported only for Deployments, StatefulSets and DaemonSets", field))
+		}
+	}
+
+	for i, selector := range specs.Selectors {
+		errs = append(errs, validateSelectorSpec(fmt.Sprintf("Selectors[%d]", i), selector)...)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid multitrack specs:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func validateSpecModes(field string, spec MultitrackSpec) []string {
+	var errs []string
+
+	switch spec.FailMode {
+	case "", IgnoreAndContinueDeployProcess, FailWholeDeployProcessImmediately, HopeUntilEndOfDeployProcess:
+	default:
+		errs = append(errs, fmt.Sprintf("%s.FailMode: invalid value %q, expected one of: %s, %s, %s", field, spec.FailMode, IgnoreAndContinueDeployProcess, FailWholeDeployProcessImmediately, HopeUntilEndOfDeployProcess))
+	}
+
+	switch spec.TrackTerminationMode {
+	case "", WaitUntilResourceReady, NonBlocking:
+	default:
+		errs = append(errs, fmt.Sprintf("%s.TrackTerminationMode: invalid value %q, expected one of: %s, %s", field, spec.TrackTerminationMode, WaitUntilResourceReady, NonBlocking))
+	}
+
+	if spec.AllowFailuresCount != nil && *spec.AllowFailuresCount < 0 {
+		errs = append(errs, fmt.Sprintf("%s.AllowFailuresCount: should not be negative", field))
+	}
+	if spec.FailureThresholdSeconds != nil && *spec.FailureThresholdSeconds < 0 {
+		errs = append(errs, fmt.Sprintf("%s.FailureThresholdSeconds: should not be negative", field))
+	}
+	if spec.TimeoutSeconds != nil && *spec.TimeoutSeconds < 0 {
+		errs = append(errs, fmt.Sprintf("%s.TimeoutSeconds: should not be negative", field))
+	}
+	if spec.NoProgressTimeoutSeconds != nil && *spec.NoProgressTimeoutSeconds < 0 {
+		errs = append(errs, fmt.Sprintf("%s.NoProgressTimeoutSeconds: should not be negative", field))
+	}
+	for i, failureType := range spec.CountedFailureTypes {
+		if err := validateFailureType(failureType); err != nil {
+			errs = append(errs, fmt.Sprintf("%s.CountedFailureTypes[%d]: %s", field, i, err))
+		}
+	}
+	for _, err := range validateFailurePolicy(spec.FailurePolicy) {
+		errs = append(errs, fmt.Sprintf("%s.FailurePolicy: %s", field, err))
+	}
+	for _, err := range validateFailureHandling(spec) {
+		errs = append(errs, fmt.Sprintf("%s.%s", field, err))
+	}
+	for i, ref := range spec.DependsOn {
+		if err := validateDependencyRef(ref); err != nil {
+			errs = append(errs, fmt.Sprintf("%s.DependsOn[%d]: %s", field, i, err))
+		}
+	}
+
+	return errs
+}
+
+func validateDependencyRef(ref string) error {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.Contains(parts[1], "/") {
+		return fmt.Errorf("invalid reference %q, expected KIND/NAME, e.g. job/migrate", ref)
+	}
+	return nil
+}
+
+func parseSpecsDocuments(data []byte, source string) (MultitrackSpecs, error) {
+	var docsSpecs []MultitrackSpecs
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for docIndex := 1; ; docIndex++ {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return MultitrackSpecs{}, formatSpecsSourceError(source, 0, err)
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		specs, err := parseSpecsDocument(doc)
+		if err != nil {
+			return MultitrackSpecs{}, formatSpecsSourceError(source, docIndex, err)
+		}
+		if err := ValidateSpecs(specs); err != nil {
+			return MultitrackSpecs{}, formatSpecsSourceError(source, docIndex, err)
+		}
+		docsSpecs = append(docsSpecs, specs)
+	}
+
+	return MergeSpecs(docsSpecs...), nil
+}
+
+func parseSpecsDocument(doc []byte) (MultitrackSpecs, error) {
+	jsonData, err := yaml.YAMLToJSONStrict(doc)
+	if err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	if bytes.Equal(bytes.TrimSpace(jsonData), []byte("null")) {
+		return MultitrackSpecs{}, nil
+	}
+
+	specs := MultitrackSpecs{}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&specs); err != nil {
+		return MultitrackSpecs{}, err
+	}
+
+	return specs, nil
+}
+
+func formatSpecsSourceError(source string, docIndex int, err error) error {
+	var parts []string
+	if source != "" {
+		parts = append(parts, source)
+	}
+	if docIndex > 0 {
+		parts = append(parts, fmt.Sprintf("document %d", docIndex))
+	}
+
+	if len(parts) == 0 {
+		return err
+	}
+	return fmt.Errorf("%s: %s", strings.Join(parts, ": "), err)
+}
+
+func expandSpecsFilesPaths(paths []string) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("bad specs file pattern %q: %s", path, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no specs files found by %q", path)
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+
+			if !info.IsDir() {
+				files = append(files, match)
+				continue
+			}
+
+			dirFiles, err := listSpecsFilesInDir(match)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, dirFiles...)
+		}
+	}
+
+	return files, nil
+}
+
+func listSpecsFilesInDir(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		for _, specsExt := range specsFilesExtensions {
+			if ext == specsExt {
+				files = append(files, filepath.Join(dir, entry.Name()))
+				break
+			}
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
@@ -0,0 +1,128 @@
+package multitrack
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestValidateSpecs(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		specs          MultitrackSpecs
+		expectedErrors []string
+	}{
+		{
+			name: "valid specs",
+			specs: MultitrackSpecs{
+				Deployments: []MultitrackSpec{{ResourceName: "app", FailMode: HopeUntilEndOfDeployProcess, RollbackOnFailure: boolPtr(true), DependsOn: []string{"job/migrate"}}},
+				Jobs:        []MultitrackSpec{{ResourceName: "migrate", TrackTerminationMode: NonBlocking, AllowFailuresCount: intPtr(0)}},
+				Generic:     []MultitrackGenericSpec{{Kind: "Service", MultitrackSpec: MultitrackSpec{ResourceName: "app"}}},
+				Selectors:   []MultitrackSelectorSpec{{LabelSelector: "app=app", Kinds: []string{"deploy"}}},
+			},
+		},
+		{
+			name: "missing and duplicated resource names",
+			specs: MultitrackSpecs{
+				Deployments: []MultitrackSpec{{ResourceName: "app"}, {}, {ResourceName: "app"}},
+			},
+			expectedErrors: []string{
+				"Deployments[1]: ResourceName is required",
+				`Deployments[2]: resource "app" is already specified in Deployments[0]`,
+			},
+		},
+		{
+			name: "invalid modes",
+			specs: MultitrackSpecs{
+				StatefulSets: []MultitrackSpec{{ResourceName: "db", FailMode: "Retry", TrackTerminationMode: "Never", TimeoutSeconds: intPtr(-1)}},
+			},
+			expectedErrors: []string{
+				`StatefulSets[0].FailMode: invalid value "Retry"`,
+				`StatefulSets[0].TrackTerminationMode: invalid value "Never"`,
+				"StatefulSets[0].TimeoutSeconds: should not be negative",
+			},
+		},
+		{
+			name: "invalid dependency reference",
+			specs: MultitrackSpecs{
+				DaemonSets: []MultitrackSpec{{ResourceName: "agent", DependsOn: []string{"migrate"}}},
+			},
+			expectedErrors: []string{`DaemonSets[0].DependsOn[0]: invalid reference "migrate"`},
+		},
+		{
+			name: "rollback of not supported kinds",
+			specs: MultitrackSpecs{
+				Jobs:    []MultitrackSpec{{ResourceName: "migrate", RollbackOnFailure: boolPtr(true)}},
+				Generic: []MultitrackGenericSpec{{Kind: "Service", MultitrackSpec: MultitrackSpec{ResourceName: "app", RollbackOnFailure: boolPtr(true)}}},
+			},
+			expectedErrors: []string{
+				"Jobs[0].RollbackOnFailure: rollback is supported only for Deployments, StatefulSets and DaemonSets",
+				"Generic[0].RollbackOnFailure: rollback is supported only for Deployments, StatefulSets and DaemonSets",
+			},
+		},
+		{
+			name: "explicitly disabled rollback of job",
+			specs: MultitrackSpecs{
+				Jobs: []MultitrackSpec{{ResourceName: "migrate", RollbackOnFailure: boolPtr(false)}},
+			},
+		},
+		{
+			name: "invalid generic resources",
+			specs: MultitrackSpecs{
+				Generic: []MultitrackGenericSpec{
+					{MultitrackSpec: MultitrackSpec{ResourceName: "app"}},
+					{Kind: "Service", GroupVersionResource: &schema.GroupVersionResource{Version: "v1", Resource: "services"}, MultitrackSpec: MultitrackSpec{ResourceName: "app"}},
+					{GroupVersionResource: &schema.GroupVersionResource{Group: "cert-manager.io"}, MultitrackSpec: MultitrackSpec{ResourceName: "tls"}},
+					{Kind: "Service", MultitrackSpec: MultitrackSpec{ResourceName: "app"}},
+					{Kind: "Service", MultitrackSpec: MultitrackSpec{ResourceName: "app"}},
+				},
+			},
+			expectedErrors: []string{
+				"Generic[0]: GroupVersionResource or Kind is required",
+				"Generic[1]: only one of GroupVersionResource and Kind should be set",
+				"Generic[2].GroupVersionResource: Version and Resource are required",
+				`Generic[4]: resource "Service/app" is already specified in Generic[3]`,
+			},
+		},
+		{
+			name: "invalid selectors",
+			specs: MultitrackSpecs{
+				Selectors: []MultitrackSelectorSpec{{
+					LabelSelector: "app in (",
+					Kinds:         []string{"pod"},
+					Template:      MultitrackSpec{ResourceName: "app"},
+				}},
+			},
+			expectedErrors: []string{
+				"Selectors[0].LabelSelector:",
+				`Selectors[0].Kinds[0]: invalid value "pod"`,
+				"Selectors[0].Template: ResourceName and Namespace should not be set",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateSpecs(tc.specs)
+
+			if len(tc.expectedErrors) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			lines := strings.Split(err.Error(), "\n")[1:]
+			if len(lines) != len(tc.expectedErrors) {
+				t.Errorf("expected %d errors, got %q", len(tc.expectedErrors), lines)
+			}
+			for _, expectedError := range tc.expectedErrors {
+				if !strings.Contains(err.Error(), expectedError) {
+					t.Errorf("expected error containing %q, got %q", expectedError, err)
+				}
+			}
+		})
+	}
+}
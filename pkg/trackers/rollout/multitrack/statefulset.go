@@ -1,10 +1,9 @@
 package multitrack
 
 import (
-	"fmt"
-
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/werf/kubedog/pkg/display"
 	"github.com/werf/kubedog/pkg/tracker/replicaset"
 	"github.com/werf/kubedog/pkg/tracker/statefulset"
 )
@@ -44,6 +43,13 @@ func (mt *multitracker) TrackStatefulSet(kube kubernetes.Interface, spec Multitr
 
 		return mt.statefulsetEventMsg(spec, feed, msg)
 	})
+	feed.OnServiceMsg(func(msg string) error {
+		mt.mux.Lock()
+		defer mt.mux.Unlock()
+
+		mt.displayResourceTrackerMessageF("sts", spec, "%s", msg)
+		return nil
+	})
 	feed.OnAddedReplicaSet(func(rs replicaset.ReplicaSet) error {
 		mt.mux.Lock()
 		defer mt.mux.Unlock()
@@ -90,25 +96,25 @@ func (mt *multitracker) TrackStatefulSet(kube kubernetes.Interface, spec Multitr
 
 func (mt *multitracker) statefulsetAdded(spec MultitrackSpec, feed statefulset.Feed, isReady bool) error {
 	if isReady {
-		mt.displayResourceTrackerMessageF("sts", spec, "appears to be READY")
+		mt.displayResourceStateMessageF(display.EventReady, "sts", spec, "appears to be READY")
 
-		return mt.handleResourceReadyCondition(mt.TrackingStatefulSets, spec)
+		return mt.handleResourceReadyCondition(mt.TrackingStatefulSets[spec.ResourceName])
 	}
 
-	mt.displayResourceTrackerMessageF("sts", spec, "added")
+	mt.displayResourceStateMessageF(display.EventAdded, "sts", spec, "added")
 
 	return nil
 }
 
 func (mt *multitracker) statefulsetReady(spec MultitrackSpec, feed statefulset.Feed) error {
-	mt.displayResourceTrackerMessageF("sts", spec, "become READY")
+	mt.displayResourceStateMessageF(display.EventReady, "sts", spec, "become READY")
 
-	return mt.handleResourceReadyCondition(mt.TrackingStatefulSets, spec)
+	return mt.handleResourceReadyCondition(mt.TrackingStatefulSets[spec.ResourceName])
 }
 
 func (mt *multitracker) statefulsetFailed(spec MultitrackSpec, feed statefulset.Feed, reason string) error {
 	mt.displayResourceErrorF("sts", spec, "%s", reason)
-	return mt.handleResourceFailure(mt.TrackingStatefulSets, "sts", spec, reason)
+	return mt.handleResourceFailure(mt.TrackingStatefulSets[spec.ResourceName], "sts", spec, getFailureTypeByReason(reason), reason)
 }
 
 func (mt *multitracker) statefulsetEventMsg(spec MultitrackSpec, feed statefulset.Feed, msg string) error {
@@ -127,11 +133,7 @@ func (mt *multitracker) statefulsetAddedPod(spec MultitrackSpec, feed statefulse
 }
 
 func (mt *multitracker) statefulsetPodError(spec MultitrackSpec, feed statefulset.Feed, podError replicaset.ReplicaSetPodError) error {
-	reason := fmt.Sprintf("po/%s container/%s: %s", podError.PodName, podError.ContainerName, podError.Message)
-
-	mt.displayResourceErrorF("sts", spec, "%s", reason)
-
-	return mt.handleResourceFailure(mt.TrackingStatefulSets, "sts", spec, reason)
+	return mt.handlePodError(mt.TrackingStatefulSets[spec.ResourceName], "sts", spec, podError.PodError)
 }
 
 func (mt *multitracker) statefulsetPodLogChunk(spec MultitrackSpec, feed statefulset.Feed, chunk *replicaset.ReplicaSetPodLogChunk) error {
@@ -142,6 +144,6 @@ func (mt *multitracker) statefulsetPodLogChunk(spec MultitrackSpec, feed statefu
 		}
 	}
 
-	mt.displayResourceLogChunk("sts", spec, podContainerLogChunkHeader(chunk.PodName, chunk.ContainerLogChunk), chunk.ContainerLogChunk)
+	mt.displayResourceLogChunk("sts", spec, chunk.PodName, chunk.ContainerLogChunk)
 	return nil
 }
@@ -0,0 +1,135 @@
+package multitrack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// TimeoutReason is the failed reason prefix of the resource, which is not ready within MultitrackSpec.TimeoutSeconds.
+	TimeoutReason = "Timeout"
+	// NoProgressReason is the failed reason prefix of the resource, which status has not changed within MultitrackSpec.NoProgressTimeoutSeconds.
+	NoProgressReason = "NoProgress"
+)
+
+// resourceProgressCheckPeriod is the period of the resource status checks of the no progress detector
+const resourceProgressCheckPeriod = time.Second
+
+func hasResourceTimeouts(spec MultitrackSpec) bool {
+	return (spec.TimeoutSeconds != nil && *spec.TimeoutSeconds > 0) || (spec.NoProgressTimeoutSeconds != nil && *spec.NoProgressTimeoutSeconds > 0)
+}
+
+// watchResourceTimeouts fails the resource, which is not ready within the spec timeout or which status has not
+// progressed within the spec no progress timeout. Runs until the resource tracker context is done.
+func (mt *multitracker) watchResourceTimeouts(kind, key string, spec MultitrackSpec, mtCtx *multitrackerContext) {
+	var timeoutChan <-chan time.Time
+	var timeout time.Duration
+	if spec.TimeoutSeconds != nil && *spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(*spec.TimeoutSeconds) * time.Second
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+
+	var progressCheckChan <-chan time.Time
+	var noProgressTimeout time.Duration
+	if spec.NoProgressTimeoutSeconds != nil && *spec.NoProgressTimeoutSeconds > 0 {
+		noProgressTimeout = time.Duration(*spec.NoProgressTimeoutSeconds) * time.Second
+
+		ticker := time.NewTicker(resourceProgressCheckPeriod)
+		defer ticker.Stop()
+		progressCheckChan = ticker.C
+	}
+
+	var lastProgress string
+	lastProgressAt := time.Now()
+
+	for {
+		select {
+		case <-mtCtx.Context.Done():
+			return
+
+		case <-timeoutChan:
+			mt.mux.Lock()
+			_, waitingFor := mt.getResourceProgress(kind, key)
+			mt.failResourceByTimeout(kind, spec, mtCtx, formatTimeoutReason(TimeoutReason, fmt.Sprintf("not ready after %s", timeout), waitingFor))
+			mt.mux.Unlock()
+			return
+
+		case now := <-progressCheckChan:
+			mt.mux.Lock()
+			progress, waitingFor := mt.getResourceProgress(kind, key)
+			if progress != lastProgress {
+				lastProgress = progress
+				lastProgressAt = now
+			} else if now.Sub(lastProgressAt) >= noProgressTimeout {
+				mt.failResourceByTimeout(kind, spec, mtCtx, formatTimeoutReason(NoProgressReason, fmt.Sprintf("no progress for %s", noProgressTimeout), waitingFor))
+				mt.mux.Unlock()
+				return
+			}
+			mt.mux.Unlock()
+		}
+	}
+}
+
+// failResourceByTimeout fails the resource and stops its tracker, should be called with locked mux.
+func (mt *multitracker) failResourceByTimeout(kind string, spec MultitrackSpec, mtCtx *multitrackerContext, reason string) {
+	state := mt.getResourceState(kind, spec)
+	if state == nil || state.Status == resourceSucceeded || state.Status == resourceFailed || mtCtx.Context.Err() != nil {
+		return
+	}
+
+	mt.displayResourceErrorF(kind, spec, "%s", reason)
+	mt.displayMultitrackServiceMessageF("%s/%s has timed out: stop tracking immediately!\n", kind, spec.ResourceName)
+
+	state.Status = resourceFailed
+	state.FailedReason = reason
+	state.FailedAt = time.Now()
+	state.endSpan()
+	mt.updateWaitingResources()
+
+	mtCtx.IsTimedOut = true
+	mtCtx.CancelFunc()
+}
+
+// getResourceProgress returns the values of the status indicators of the resource, which change while the resource
+// progresses, and what the resource is still waiting for. Should be called with locked mux.
+func (mt *multitracker) getResourceProgress(kind, key string) (string, []string) {
+	switch kind {
+	case "deploy":
+		status := mt.DeploymentsStatuses[key]
+		return fmt.Sprintf("%+v %+v %+v", status.ReplicasIndicator, status.UpToDateIndicator, status.AvailableIndicator), status.WaitingForMessages
+	case "sts":
+		status := mt.StatefulSetsStatuses[key]
+		return fmt.Sprintf("%+v %+v %+v", status.ReplicasIndicator, status.ReadyIndicator, status.UpToDateIndicator), status.WaitingForMessages
+	case "ds":
+		status := mt.DaemonSetsStatuses[key]
+		return fmt.Sprintf("%+v %+v %+v", status.ReplicasIndicator, status.UpToDateIndicator, status.AvailableIndicator), status.WaitingForMessages
+	case "job":
+		status := mt.JobsStatuses[key]
+		return fmt.Sprintf("%+v %d %d", status.SucceededIndicator, status.Active, status.Failed), status.WaitingForMessages
+	case "canary":
+		status := mt.CanariesStatuses[key]
+		var waitingFor []string
+		if status.Phase != "" {
+			waitingFor = append(waitingFor, fmt.Sprintf("phase %s", status.Phase))
+		}
+		return fmt.Sprintf("%+v %d", status.StatusIndicator, status.CanaryWeight), waitingFor
+	default:
+		status := mt.GenericStatuses[key]
+		var waitingFor []string
+		if status.Message != "" {
+			waitingFor = append(waitingFor, status.Message)
+		}
+		return fmt.Sprintf("%+v %s", status.StatusIndicator, status.Message), waitingFor
+	}
+}
+
+func formatTimeoutReason(reason, msg string, waitingFor []string) string {
+	if len(waitingFor) == 0 {
+		return fmt.Sprintf("%s: %s", reason, msg)
+	}
+	return fmt.Sprintf("%s: %s, waiting for: %s", reason, msg, strings.Join(waitingFor, ", "))
+}
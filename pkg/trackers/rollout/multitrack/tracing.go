@@ -0,0 +1,91 @@
+package multitrack
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/werf/kubedog/pkg/trackers/rollout/multitrack"
+
+// startRunSpan starts the root span of the run, which is the child of the span of the parent context if any.
+func (mt *multitracker) startRunSpan(parentContext context.Context, tracerProvider trace.TracerProvider) trace.Span {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	mt.tracer = tracerProvider.Tracer(tracerName)
+
+	var span trace.Span
+	mt.traceContext, span = mt.tracer.Start(parentContext, "multitrack", trace.WithAttributes(attribute.Bool("kubedog.watch_mode", mt.watchMode)))
+	return span
+}
+
+// endRunSpan ends spans of the resources, which are still tracked, and the root span of the run.
+func (mt *multitracker) endRunSpan(span trace.Span, report MultitrackReport, err error) {
+	mt.mux.Lock()
+	for _, states := range []map[string]*multitrackerResourceState{
+		mt.TrackingDeployments,
+		mt.TrackingStatefulSets,
+		mt.TrackingDaemonSets,
+		mt.TrackingJobs,
+		mt.TrackingCanaries,
+		mt.TrackingGenerics,
+	} {
+		for _, state := range states {
+			state.endSpan()
+		}
+	}
+	mt.mux.Unlock()
+
+	span.SetAttributes(attribute.Int("kubedog.resources_count", len(report.Resources)))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startResourceSpan starts the span of the resource, which lasts until the resource is ready, failed or tracking is done.
+// Should be called with locked mux.
+func (mt *multitracker) startResourceSpan(kind string, spec MultitrackSpec) {
+	state := mt.getResourceState(kind, spec)
+	if state == nil {
+		return
+	}
+
+	_, state.span = mt.tracer.Start(mt.traceContext, fmt.Sprintf("%s/%s", kind, spec.ResourceName), trace.WithAttributes(
+		attribute.String("kubedog.kind", kind),
+		attribute.String("kubedog.name", spec.ResourceName),
+		attribute.String("kubedog.namespace", spec.Namespace),
+	))
+}
+
+// addResourceSpanEvent adds the event to the span of the resource, should be called with locked mux.
+func (mt *multitracker) addResourceSpanEvent(kind string, spec MultitrackSpec, name string, attrs ...attribute.KeyValue) {
+	state := mt.getResourceState(kind, spec)
+	if state == nil || state.span == nil {
+		return
+	}
+
+	state.span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span of the resource with the current status, the span is ended once.
+func (state *multitrackerResourceState) endSpan() {
+	if state.span == nil {
+		return
+	}
+
+	state.span.SetAttributes(
+		attribute.String("kubedog.status", formatReportResourceStatus(state.Status)),
+		attribute.Int("kubedog.failures_count", state.FailuresCount),
+	)
+	if state.Status == resourceFailed {
+		state.span.SetStatus(codes.Error, state.FailedReason)
+	}
+	state.span.End()
+	state.span = nil
+}
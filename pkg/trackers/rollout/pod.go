@@ -14,33 +14,44 @@ func TrackPodTillReady(name, namespace string, kube kubernetes.Interface, opts t
 	feed := pod.NewFeed()
 
 	feed.OnAdded(func() error {
-		fmt.Fprintf(display.Out, "# po/%s added\n", name)
-		return nil
+		return display.PrintEvent(display.EventAdded, "po", namespace, name, feed.GetStatus(), "added")
 	})
 	feed.OnSucceeded(func() error {
-		fmt.Fprintf(display.Out, "# po/%s succeeded\n", name)
+		if err := display.PrintEvent(display.EventSucceeded, "po", namespace, name, feed.GetStatus(), "succeeded"); err != nil {
+			return err
+		}
 		return tracker.StopTrack
 	})
 	feed.OnFailed(func(reason string) error {
-		fmt.Fprintf(display.Out, "# po/%s failed: %s\n", name, reason)
+		if err := display.PrintEvent(display.EventFailed, "po", namespace, name, nil, "failed: %s", reason); err != nil {
+			return err
+		}
 		return tracker.ResourceErrorf("po/%s failed: %s", name, reason)
 	})
 	feed.OnReady(func() error {
-		fmt.Fprintf(display.Out, "# po/%s become READY\n", name)
+		if err := display.PrintEvent(display.EventReady, "po", namespace, name, feed.GetStatus(), "become READY"); err != nil {
+			return err
+		}
 		return tracker.StopTrack
 	})
 	feed.OnEventMsg(func(msg string) error {
-		fmt.Fprintf(display.Out, "# po/%s event: %s\n", name, msg)
-		return nil
+		return display.PrintEvent(display.EventMessage, "po", namespace, name, nil, "event: %s", msg)
+	})
+	feed.OnServiceMsg(func(msg string) error {
+		return display.PrintEvent(display.EventServiceMessage, "po", namespace, name, nil, "%s", msg)
 	})
 	feed.OnContainerError(func(containerError pod.ContainerError) error {
-		fmt.Fprintf(display.Out, "# po/%s %s error: %s\n", name, containerError.ContainerName, containerError.Message)
+		if err := display.PrintEvent(display.EventFailed, "po", namespace, name, nil, "%s error: %s", containerError.ContainerName, containerError.Message); err != nil {
+			return err
+		}
 		return tracker.ResourceErrorf("po/%s %s failed: %s", name, containerError.ContainerName, containerError.Message)
 	})
 	feed.OnContainerLogChunk(func(chunk *pod.ContainerLogChunk) error {
 		header := fmt.Sprintf("po/%s %s", name, chunk.ContainerName)
-		display.OutputLogLines(header, chunk.LogLines)
-		return nil
+		return display.PrintLogLines("po", namespace, name, name, chunk.ContainerName, header, chunk.LogLines)
+	})
+	feed.OnStatus(func(status pod.PodStatus) error {
+		return display.PrintStatus("po", namespace, name, status)
 	})
 
 	err := feed.Track(name, namespace, kube, opts)